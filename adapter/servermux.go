@@ -4,7 +4,6 @@ package ngadapter
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -12,39 +11,52 @@ import (
 	nghttp "github.com/foxie-io/ng/http"
 )
 
-// ServeMuxResponseHandler write HTTPResponse to http.ResponseWriter
+// ServeMuxResponseHandler write HTTPResponse to http.ResponseWriter,
+// body is encoded with codec negotiated from Accept header
 func ServeMuxResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	var (
-		w     = ng.MustLoad[http.ResponseWriter](ctx)
-		value []byte
+		w      = ng.MustLoad[http.ResponseWriter](ctx)
+		header = http.Header{}
 	)
 
-	switch v := info.(type) {
-	case *nghttp.Response:
-		w.Header().Set("content-type", "application/json")
-		value, _ = json.Marshal(v.Response())
+	if r, err := ng.Load[*http.Request](ctx); err == nil {
+		header = r.Header
+	}
 
-	case *nghttp.PanicError:
-		fmt.Println("recieve (*nghttp.PanicError)", v.Value())
-		value, _ = json.Marshal(info.Response())
+	if stream, ok := info.(nghttp.Streamer); ok {
+		return nghttp.WriteStream(ctx, w, header, stream)
+	}
 
-	case *nghttp.RawResponse:
-		value = v.Value()
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return ServeMuxResponseHandler(ctx, errResp)
+		}
+		defer reader.Close()
+		return nghttp.CopyBody(w, body, reader)
+	}
+
+	if v, ok := info.(*nghttp.PanicError); ok {
+		fmt.Println("recieve (*nghttp.PanicError)", v.Value())
+	}
 
-	default:
-		fmt.Println("unknown in response", info.Response())
-		value = []byte("unknown in response value")
+	status, contentType, value := ng.EncodeResponse(ctx, header.Get("Accept"), info)
+	if contentType != "" {
+		w.Header().Set("content-type", contentType)
 	}
 
-	w.WriteHeader(info.StatusCode())
-	_, _ = w.Write(value)
-	return nil
+	nghttp.WriteHeaders(w, info)
+	w.WriteHeader(status)
+	_, err := w.Write(value)
+	nghttp.WriteTrailers(w, info)
+	return err
 }
 
 // ServeMuxHandler create http.HandlerFunc from ng.Handler
 func ServeMuxHandler(scopeHandler func() ng.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx, _ := ng.NewContext(r.Context())
+		ctx, rc := ng.NewContext(r.Context())
+		defer rc.Clear()
 
 		// store in context
 		ng.Store(ctx, w)
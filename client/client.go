@@ -0,0 +1,401 @@
+// Package ngclient calls ng apps over HTTP. Generate emits typed clients of an app, their methods call Do.
+package ngclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type (
+	// RequestEditor changes requests before they are sent, e.g. to add credentials
+	RequestEditor func(ctx context.Context, req *http.Request) error
+
+	// Option configures Client
+	Option func(*Client)
+
+	// Client sends requests to an ng app
+	Client struct {
+		baseURL    string
+		httpClient *http.Client
+		editors    []RequestEditor
+	}
+
+	// Call describes the route called by Do
+	Call struct {
+		// Method is HTTP method, e.g. http.MethodGet
+		Method string
+
+		// Path is OpenAPI path template, e.g. "/users/{id}"
+		Path string
+
+		// Bare is set for routes writing bare data, see nghttp.DataEnvelope
+		Bare bool
+	}
+)
+
+// WithHTTPClient replaces http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithRequestEditor adds editor run before every request
+func WithRequestEditor(editor RequestEditor) Option {
+	return func(client *Client) {
+		client.editors = append(client.editors, editor)
+	}
+}
+
+// WithHeader sets header of every request
+func WithHeader(key, value string) Option {
+	return WithRequestEditor(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set(key, value)
+		return nil
+	})
+}
+
+// New create client of app served at baseURL, e.g. "http://users.internal:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+/*
+Do sends req to route and decodes data of the response into Resp.
+
+Fields of req tagged `path` (or `param`), `query` and `header` are sent like ng.DefaultBinder reads them,
+req is sent as JSON body except for GET, HEAD and DELETE. Error responses are returned as *nghttp.Response,
+so codes can be checked across services:
+
+	user, err := ngclient.Do[*dtos.User](ctx, c, ngclient.Call{Method: http.MethodGet, Path: "/users/{id}"}, req)
+	if resp, ok := err.(*nghttp.Response); ok && resp.Code == nghttp.CodeNotFound { ... }
+*/
+func Do[Resp any](ctx context.Context, c *Client, call Call, req any) (Resp, error) {
+	var resp Resp
+
+	httpReq, err := c.newRequest(ctx, call, req)
+	if err != nil {
+		return resp, err
+	}
+
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return resp, ParseError(res.StatusCode, body)
+	}
+	return resp, decodeData(res.StatusCode, body, call.Bare, &resp)
+}
+
+func (c *Client) newRequest(ctx context.Context, call Call, req any) (*http.Request, error) {
+	path, query, header := call.Path, url.Values{}, http.Header{}
+	hasBody := false
+
+	v := reflect.ValueOf(req)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		var err error
+		if path, hasBody, err = encodeFields(v, path, query, header); err != nil {
+			return nil, err
+		}
+	} else if v.IsValid() && v.Kind() != reflect.Pointer {
+		hasBody = true
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	switch call.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		hasBody = false
+	}
+	if hasBody {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, call.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range header {
+		httpReq.Header[k] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if hasBody {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	for _, edit := range c.editors {
+		if err := edit(ctx, httpReq); err != nil {
+			return nil, err
+		}
+	}
+	return httpReq, nil
+}
+
+// encodeFields fills path, query and header from tagged fields and reports whether other fields are left for the body
+func encodeFields(v reflect.Value, path string, query url.Values, header http.Header) (string, bool, error) {
+	hasBody := false
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf, field := t.Field(i), v.Field(i)
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			var body bool
+			var err error
+			if path, body, err = encodeFields(field, path, query, header); err != nil {
+				return "", false, err
+			}
+			hasBody = hasBody || body
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, in := fieldSource(sf)
+		if name == "" {
+			hasBody = hasBody || sf.Tag.Get("json") != "-"
+			continue
+		}
+
+		values := fieldValues(field)
+		switch in {
+		case "path":
+			if len(values) == 0 || values[0] == "" {
+				return "", false, fmt.Errorf("ngclient: path parameter %q is empty", name)
+			}
+			path = replaceParam(path, name, url.PathEscape(values[0]))
+		case "query":
+			for _, value := range values {
+				query.Add(name, value)
+			}
+		case "header":
+			for _, value := range values {
+				header.Add(name, value)
+			}
+		}
+	}
+	return path, hasBody, nil
+}
+
+// replaceParam replaces "{name}" in path, ":name" and "{name...}" forms are accepted too
+func replaceParam(path, name, value string) string {
+	for _, param := range []string{"{" + name + "}", "{" + name + "...}", ":" + name} {
+		if strings.Contains(path, param) {
+			return strings.Replace(path, param, value, 1)
+		}
+	}
+	return path
+}
+
+// fieldValues formats field like ng.DefaultBinder parses it, zero values of optional fields are skipped
+func fieldValues(field reflect.Value) []string {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice {
+		values := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			values = append(values, fmt.Sprint(field.Index(i).Interface()))
+		}
+		return values
+	}
+
+	if field.IsZero() && field.Kind() == reflect.String {
+		return nil
+	}
+	return []string{fmt.Sprint(field.Interface())}
+}
+
+// fieldSource return name and location of `path` (or `param`), `query` or `header` tagged field
+func fieldSource(sf reflect.StructField) (name, in string) {
+	for _, tag := range []string{"path", "param", "query", "header"} {
+		if name, _, _ = strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			if tag == "param" {
+				tag = "path"
+			}
+			return name, tag
+		}
+	}
+	return "", ""
+}
+
+var responseType = reflect.TypeFor[*nghttp.Response]()
+
+// decodeData decodes "data" member of body, or whole body of bare routes
+func decodeData(statusCode int, body []byte, bare bool, dst any) error {
+	target := reflect.ValueOf(dst).Elem()
+
+	// full responses are read back with their code and meta
+	if target.Type() == responseType {
+		resp, err := nghttp.ParseResponse(statusCode, body)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(resp))
+		return nil
+	}
+	if _, ok := target.Interface().(nghttp.HTTPResponse); ok || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	if bare {
+		return json.Unmarshal(body, dst)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return err
+	}
+	if len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, dst)
+}
+
+/*
+ParseError decodes error body into *nghttp.Response. Bodies written by nghttp.GoogleEnvelope,
+nghttp.JSendEnvelope and application/problem+json bodies of ngproblem.Format are understood,
+other bodies become the message of a code matching the status.
+*/
+func ParseError(statusCode int, body []byte) *nghttp.Response {
+	if resp, ok := parseProblem(statusCode, body); ok {
+		return resp
+	}
+
+	if resp, err := nghttp.ParseResponse(statusCode, body); err == nil {
+		return resp
+	}
+
+	var google nghttp.GoogleBody
+	if json.Unmarshal(body, &google) == nil && google.Error != nil && google.Error.Status != "" {
+		return nghttp.NewError(google.Error.Status, statusCode, google.Error.Message).Update(
+			nghttp.WithDetails(google.Error.Details...),
+			withMeta(google.Error.Meta),
+		)
+	}
+
+	var jsend struct {
+		nghttp.JSendBody
+		Data map[string]any `json:"data"`
+	}
+	if json.Unmarshal(body, &jsend) == nil && jsend.Code != "" {
+		return nghttp.NewError(jsend.Code, statusCode, jsend.Message).Update(withMeta(jsend.Data))
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return nghttp.NewError(codeOf(statusCode), statusCode, message)
+}
+
+// problem members that are not copied to meta
+var problemMembers = []string{"type", "title", "status", "detail", "instance", "code", "details"}
+
+// parseProblem reads RFC 9457 problem details, the code extension falls back to a code matching the status
+func parseProblem(statusCode int, body []byte) (*nghttp.Response, bool) {
+	var problem struct {
+		Type    string         `json:"type"`
+		Title   string         `json:"title"`
+		Status  int            `json:"status"`
+		Detail  string         `json:"detail"`
+		Code    nghttp.Code    `json:"code"`
+		Details nghttp.Details `json:"details"`
+	}
+	if json.Unmarshal(body, &problem) != nil || problem.Type == "" || problem.Title == "" || problem.Status == 0 {
+		return nil, false
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, false
+	}
+	for _, k := range problemMembers {
+		delete(members, k)
+	}
+
+	code, message := problem.Code, problem.Detail
+	if code == "" {
+		code = codeOf(statusCode)
+	}
+	if message == "" {
+		message = problem.Title
+	}
+
+	return nghttp.NewError(code, statusCode, message).Update(
+		nghttp.WithDetails(problem.Details...),
+		withMeta(members),
+	), true
+}
+
+func withMeta(meta map[string]any) nghttp.Option {
+	return func(r *nghttp.Response) {
+		r.Meta = meta
+	}
+}
+
+// codeOf return code of status for bodies without code
+func codeOf(statusCode int) nghttp.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return nghttp.CodeBadRequest
+	case http.StatusUnauthorized:
+		return nghttp.CodeUnauthenticated
+	case http.StatusForbidden:
+		return nghttp.CodePermissionDenied
+	case http.StatusNotFound:
+		return nghttp.CodeNotFound
+	case http.StatusConflict:
+		return nghttp.CodeAlreadyExists
+	case http.StatusTooManyRequests:
+		return nghttp.CodeTooManyRequests
+	case http.StatusNotImplemented:
+		return nghttp.CodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return nghttp.CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return nghttp.CodeDeadlineExceeded
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return nghttp.CodeInternal
+	}
+	return nghttp.CodeUnknown
+}
@@ -0,0 +1,312 @@
+package ngclient
+
+// Client generator: typed routes of a built app become methods of a Go client
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+// Config configures generated client
+type Config struct {
+	// Package is package name of generated file, default "client"
+	Package string
+
+	// ImportPath is import path of generated package, types declared in it are not qualified
+	ImportPath string
+
+	// TypeName is name of client type, default "Client"
+	TypeName string
+}
+
+/*
+Generate return Go source of a client calling typed routes of built app, see ng.WithTypedHandler.
+Routes without handler types and hidden routes are skipped.
+
+Method names come from ngopenapi.OperationID: controller routes like UserController.Get become
+UserGet, other ids are exported as is. Request and response types are referenced from their packages,
+so they must be exported and importable. Use it from a go:generate program:
+
+	//go:generate go run ./cmd/genclient
+
+	func main() {
+		app := server.NewApp()
+		app.Build()
+		if err := ngclient.GenerateFile(app, "client/client_gen.go", ngclient.Config{Package: "client"}); err != nil {
+			log.Fatal(err)
+		}
+	}
+*/
+func Generate(app ng.App, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "client"
+	}
+	if cfg.TypeName == "" {
+		cfg.TypeName = "Client"
+	}
+
+	g := &clientGen{cfg: cfg, imports: map[string]string{}}
+	g.imports["context"] = "context"
+	g.imports["github.com/foxie-io/ng/client"] = "ngclient"
+
+	var methods bytes.Buffer
+	names := map[string]string{}
+	for _, r := range app.Routes() {
+		types, ok := ng.RouteHandlerTypes(r)
+		if !ok || ngopenapi.Hidden(r) {
+			continue
+		}
+
+		name := methodName(ngopenapi.OperationID(r))
+		if other, taken := names[name]; taken {
+			return nil, fmt.Errorf("ngclient: method %s of %s %s is taken by %s, set ngopenapi.WithOperationID", name, r.Method(), r.Path(), other)
+		}
+		names[name] = r.Method() + " " + r.Path()
+
+		if err := g.method(&methods, r, name, types); err != nil {
+			return nil, fmt.Errorf("ngclient: %s %s: %w", r.Method(), r.Path(), err)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by ngclient. DO NOT EDIT.\n\npackage %s\n\nimport (\n", cfg.Package)
+	for _, p := range slices.Sorted(maps.Keys(g.imports)) {
+		if alias := g.imports[p]; alias != path.Base(p) {
+			fmt.Fprintf(&src, "\t%s %q\n", alias, p)
+		} else {
+			fmt.Fprintf(&src, "\t%q\n", p)
+		}
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	fmt.Fprintf(&src, "// %[1]s calls routes of the app\ntype %[1]s struct {\n\t*ngclient.Client\n}\n\n", cfg.TypeName)
+	fmt.Fprintf(&src, "// New%[1]s create client of app served at baseURL\nfunc New%[1]s(baseURL string, opts ...ngclient.Option) *%[1]s {\n\treturn &%[1]s{Client: ngclient.New(baseURL, opts...)}\n}\n", cfg.TypeName)
+	src.Write(methods.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("ngclient: format generated source: %w", err)
+	}
+	return out, nil
+}
+
+// GenerateFile writes Generate output to filename, the file is left untouched when unchanged
+func GenerateFile(app ng.App, filename string, cfg Config) error {
+	src, err := Generate(app, cfg)
+	if err != nil {
+		return err
+	}
+	if old, err := os.ReadFile(filename); err == nil && bytes.Equal(old, src) {
+		return nil
+	}
+	return os.WriteFile(filename, src, 0o644)
+}
+
+type clientGen struct {
+	cfg Config
+
+	// imports maps import paths to package names
+	imports map[string]string
+}
+
+func (g *clientGen) method(w *bytes.Buffer, r ng.Route, name string, types ng.HandlerTypes) error {
+	req, err := g.typeExpr(types.Request)
+	if err != nil {
+		return err
+	}
+	resp, err := g.typeExpr(types.Response)
+	if err != nil {
+		return err
+	}
+
+	routePath, _ := ngopenapi.Path(r)
+	method := methodConst(r.Method())
+	if strings.HasPrefix(method, "http.") {
+		g.imports["net/http"] = "http"
+	}
+	call := fmt.Sprintf("ngclient.Call{Method: %s, Path: %q", method, routePath)
+	if ng.RouteEnvelope(r) == nghttp.DataEnvelope {
+		call += ", Bare: true"
+	}
+	call += "}"
+
+	fmt.Fprintf(w, "\n// %s calls %s %s\n", name, r.Method(), routePath)
+	fmt.Fprintf(w, "func (c *%s) %s(ctx context.Context, req %s) (%s, error) {\n", g.cfg.TypeName, name, req, resp)
+	fmt.Fprintf(w, "\treturn ngclient.Do[%s](ctx, c.Client, %s, req)\n}\n", resp, call)
+	return nil
+}
+
+// typeExpr return Go expression of t, packages are added to imports
+func (g *clientGen) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		return g.namedType(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	case reflect.Struct:
+		var b strings.Builder
+		b.WriteString("struct {\n")
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			ft, err := g.typeExpr(sf.Type)
+			if err != nil {
+				return "", err
+			}
+			if !sf.Anonymous {
+				b.WriteString(sf.Name + " ")
+			}
+			b.WriteString(ft)
+			if sf.Tag != "" {
+				b.WriteString(" " + quoteTag(string(sf.Tag)))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// qualifiedName matches package qualified names in type arguments, e.g. github.com/acme/dtos.User
+var (
+	qualifiedName = regexp.MustCompile(`([A-Za-z0-9_./-]+)\.([A-Za-z_][A-Za-z0-9_]*)`)
+	majorVersion  = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+func (g *clientGen) namedType(t reflect.Type) (string, error) {
+	if t.PkgPath() == "" {
+		// predeclared, e.g. string or error
+		return t.Name(), nil
+	}
+	if !unicode.IsUpper([]rune(t.Name())[0]) {
+		return "", fmt.Errorf("type %s is not exported", t)
+	}
+	if t.PkgPath() == "main" {
+		return "", fmt.Errorf("type %s is declared in package main", t)
+	}
+
+	pkg, _, _ := strings.Cut(t.String(), ".")
+	name, args, generic := strings.Cut(t.Name(), "[")
+	expr := g.qualify(t.PkgPath(), pkg, name)
+	if !generic {
+		return expr, nil
+	}
+
+	var err error
+	args = qualifiedName.ReplaceAllStringFunc(args, func(s string) string {
+		m := qualifiedName.FindStringSubmatch(s)
+		if m[1] == "main" {
+			err = fmt.Errorf("type %s is declared in package main", t)
+		}
+		return g.qualify(m[1], packageName(m[1]), m[2])
+	})
+	return expr + "[" + args, err
+}
+
+// qualify return name qualified with package pkg of importPath, a unique alias is chosen on conflicts
+func (g *clientGen) qualify(importPath, pkg, name string) string {
+	if importPath == g.cfg.ImportPath {
+		return name
+	}
+
+	alias, ok := g.imports[importPath]
+	if !ok {
+		alias = pkg
+		for i := 2; slices.Contains(slices.Collect(maps.Values(g.imports)), alias) || alias == g.cfg.Package; i++ {
+			alias = pkg + strconv.Itoa(i)
+		}
+		g.imports[importPath] = alias
+	}
+	return alias + "." + name
+}
+
+// packageName guesses package name from import path for type arguments, "v2" suffixes are skipped
+func packageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && majorVersion.MatchString(name) {
+		name = parts[len(parts)-2]
+	}
+	return strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' {
+			return c
+		}
+		return -1
+	}, name)
+}
+
+// methodName return client method of operation id, "pkg.UserController.Get" is UserGet
+func methodName(id string) string {
+	parts := strings.Split(id, ".")
+	if len(parts) == 3 {
+		id = strings.TrimSuffix(parts[1], "Controller") + exportName(parts[2])
+	}
+	return exportName(id)
+}
+
+func exportName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range s {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func methodConst(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodConnect:
+		return "http.Method" + string(method[0]) + strings.ToLower(method[1:])
+	}
+	return strconv.Quote(method)
+}
+
+func quoteTag(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
@@ -0,0 +1,86 @@
+/*
+Command ng generates code of ng apps.
+
+	ng gen server [-o dir] [-package name] spec.yaml
+
+gen server writes DTOs, controllers and service interfaces of an OpenAPI document to dir,
+see ngscaffold.WriteFiles. Files ending with _gen.go are replaced, service implementations are
+only created when missing. Use it with go:generate next to the spec:
+
+	//go:generate go run github.com/foxie-io/ng/cmd/ng gen server -o api -package api openapi.yaml
+
+Typed Go clients are generated from the app itself, see ngclient.GenerateFile.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ngopenapi "github.com/foxie-io/ng/openapi"
+	ngscaffold "github.com/foxie-io/ng/openapi/scaffold"
+)
+
+const usage = `usage: ng gen server [-o dir] [-package name] spec.yaml`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "ng:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 || args[0] != "gen" || args[1] != "server" {
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("ng gen server", flag.ContinueOnError)
+	dir := flags.String("o", ".", "output directory")
+	pkg := flags.String("package", "", "package name, default is name of output directory")
+	if err := flags.Parse(args[2:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(usage)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	doc, err := ngopenapi.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	if *pkg == "" {
+		abs, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		*pkg = packageName(filepath.Base(abs))
+	}
+	return ngscaffold.WriteFiles(*dir, doc, ngscaffold.Config{Package: *pkg})
+}
+
+// packageName return package name of directory, e.g. "user-api" is userapi
+func packageName(dir string) string {
+	name := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			return c
+		case c >= 'A' && c <= 'Z':
+			return c + 'a' - 'A'
+		}
+		return -1
+	}, dir)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return "api"
+	}
+	return name
+}
@@ -0,0 +1,324 @@
+package ng
+
+// Codecs encode response bodies and decode request bodies by media type
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// Codec encodes and decodes values for a media type
+/*
+type YAMLCodec struct{}
+
+func (YAMLCodec) ContentType() string { return "application/yaml" }
+
+func (YAMLCodec) Encode(w io.Writer, v any) error { return yaml.NewEncoder(w).Encode(v) }
+
+func (YAMLCodec) Decode(r io.Reader, v any) error { return yaml.NewDecoder(r).Decode(v) }
+
+app := ng.NewApp(ng.WithCodecs(YAMLCodec{}))
+*/
+type Codec interface {
+	// ContentType return media type, e.g. "application/json"
+	ContentType() string
+
+	// Encode writes v to w
+	Encode(w io.Writer, v any) error
+
+	// Decode reads r into v
+	Decode(r io.Reader, v any) error
+}
+
+const (
+	// MIMEApplicationJSON is JSON media type
+	MIMEApplicationJSON = "application/json"
+
+	// MIMEApplicationXML is XML media type
+	MIMEApplicationXML = "application/xml"
+
+	// MIMETextCSV is CSV media type
+	MIMETextCSV = "text/csv"
+
+	// MIMEApplicationNDJSON is newline delimited JSON media type
+	MIMEApplicationNDJSON = "application/x-ndjson"
+)
+
+var (
+	_ Codec = (*jsonCodec)(nil)
+	_ Codec = XMLCodec{}
+	_ Codec = CSVCodec{}
+	_ Codec = NDJSONCodec{}
+)
+
+// DefaultCodecs are codecs available to every route, first one is used when client accepts anything
+var DefaultCodecs = []Codec{
+	JSONCodec,
+	XMLCodec{},
+	CSVCodec{},
+	NDJSONCodec{},
+}
+
+// JSONCodec is the default JSON codec based on encoding/json
+var JSONCodec = NewJSONCodec(json.Marshal, json.Unmarshal)
+
+type jsonCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// NewJSONCodec create JSON codec from marshal and unmarshal functions,
+// e.g. to use a faster encoder app-wide with WithJSONCodec
+/*
+	ng.WithJSONCodec(ng.NewJSONCodec(sonic.Marshal, sonic.Unmarshal))
+*/
+func NewJSONCodec(marshal func(v any) ([]byte, error), unmarshal func(data []byte, v any) error) Codec {
+	return &jsonCodec{marshal: marshal, unmarshal: unmarshal}
+}
+
+func (c *jsonCodec) ContentType() string { return MIMEApplicationJSON }
+
+func (c *jsonCodec) Encode(w io.Writer, v any) error {
+	data, err := c.marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *jsonCodec) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(data, v)
+}
+
+// XMLCodec encodes and decodes XML using encoding/xml
+type XMLCodec struct{}
+
+// ContentType return "application/xml"
+func (XMLCodec) ContentType() string { return MIMEApplicationXML }
+
+// Encode writes v as XML
+func (XMLCodec) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode reads XML into v
+func (XMLCodec) Decode(r io.Reader, v any) error { return xml.NewDecoder(r).Decode(v) }
+
+// NDJSONCodec encodes slices as newline delimited JSON, one item per line.
+// For *nghttp.Response the data field is encoded, error responses are written as a single line.
+type NDJSONCodec struct{}
+
+// ContentType return "application/x-ndjson"
+func (NDJSONCodec) ContentType() string { return MIMEApplicationNDJSON }
+
+// Encode writes v as newline delimited JSON
+func (NDJSONCodec) Encode(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	items, ok := sliceOf(responseData(v))
+	if !ok {
+		return enc.Encode(v)
+	}
+
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads newline delimited JSON into pointer to slice v
+func (NDJSONCodec) Decode(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ndjson: decode requires pointer to slice, got %T", v)
+	}
+
+	slice := rv.Elem()
+	dec := json.NewDecoder(r)
+	for {
+		item := reflect.New(slice.Type().Elem())
+		if err := dec.Decode(item.Interface()); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+}
+
+// CSVCodec encodes slices of structs, maps or string slices as CSV.
+// For *nghttp.Response the data field is encoded, error responses are written as code and message.
+//
+// Struct columns are named by `csv` tag, then `json` tag, then field name; `csv:"-"` skips a field.
+type CSVCodec struct{}
+
+// ContentType return "text/csv"
+func (CSVCodec) ContentType() string { return MIMETextCSV }
+
+// Encode writes v as CSV
+func (CSVCodec) Encode(w io.Writer, v any) error {
+	records, err := csvRecords(v)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// Decode reads CSV into *[][]string
+func (CSVCodec) Decode(r io.Reader, v any) error {
+	dst, ok := v.(*[][]string)
+	if !ok {
+		return fmt.Errorf("csv: decode requires *[][]string, got %T", v)
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	*dst = records
+	return nil
+}
+
+// responseData return data field of success *nghttp.Response, v otherwise
+func responseData(v any) any {
+	if resp, ok := v.(*nghttp.Response); ok && resp.Code == nghttp.CodeOk {
+		return resp.Data
+	}
+	return v
+}
+
+func sliceOf(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+func csvRecords(v any) ([][]string, error) {
+	if resp, ok := v.(*nghttp.Response); ok && resp.Code != nghttp.CodeOk {
+		return [][]string{{"code", "message"}, {string(resp.Code), resp.Error()}}, nil
+	}
+
+	data := responseData(v)
+	if data == nil {
+		return [][]string{}, nil
+	}
+
+	if records, ok := data.([][]string); ok {
+		return records, nil
+	}
+
+	items, ok := sliceOf(data)
+	if !ok {
+		items = []any{data}
+	}
+
+	var header []string
+	records := [][]string{}
+	for _, item := range items {
+		row, columns, err := csvRow(item, header)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil && columns != nil {
+			header = columns
+			records = append(records, header)
+		}
+		records = append(records, row)
+	}
+
+	return records, nil
+}
+
+// csvRow return row values ordered by header, header is derived from first item
+func csvRow(item any, header []string) ([]string, []string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(item))
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		values := map[string]string{}
+		columns := []string{}
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name := csvColumn(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			columns = append(columns, name)
+			values[name] = fmt.Sprint(rv.Field(i).Interface())
+		}
+		return orderRow(values, header, columns), columns, nil
+
+	case reflect.Map:
+		values := map[string]string{}
+		columns := []string{}
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			columns = append(columns, key)
+			values[key] = fmt.Sprint(iter.Value().Interface())
+		}
+		slices.Sort(columns)
+		return orderRow(values, header, columns), columns, nil
+
+	case reflect.Slice, reflect.Array:
+		row := make([]string, rv.Len())
+		for i := range row {
+			row[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return row, header, nil
+	}
+
+	return nil, nil, fmt.Errorf("csv: unsupported value %T", item)
+}
+
+func orderRow(values map[string]string, header []string, columns []string) []string {
+	if header == nil {
+		header = columns
+	}
+
+	row := make([]string, len(header))
+	for i, name := range header {
+		row[i] = values[name]
+	}
+	return row
+}
+
+func csvColumn(field reflect.StructField) string {
+	for _, key := range []string{"csv", "json"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
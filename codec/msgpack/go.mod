@@ -0,0 +1,12 @@
+module github.com/foxie-io/ng/codec/msgpack
+
+go 1.25.2
+
+replace github.com/foxie-io/ng => ./../..
+
+require (
+	github.com/foxie-io/ng v0.4.4
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
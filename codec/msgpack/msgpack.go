@@ -0,0 +1,42 @@
+package ngmsgpack
+
+// MessagePack codec plugin, kept in its own module so ng core has no dependency on it
+
+import (
+	"io"
+
+	"github.com/foxie-io/ng"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MIMEApplicationMsgPack is MessagePack media type
+const MIMEApplicationMsgPack = "application/msgpack"
+
+var _ ng.Codec = Codec{}
+
+// Codec encodes and decodes MessagePack using github.com/vmihailenco/msgpack/v5.
+//
+// Struct fields are named by `msgpack` tag, falling back to `json` tag.
+/*
+	app := ng.NewApp(
+		ng.WithCodecs(ngmsgpack.Codec{}),
+	)
+*/
+type Codec struct{}
+
+// ContentType return "application/msgpack"
+func (Codec) ContentType() string { return MIMEApplicationMsgPack }
+
+// Encode writes v as MessagePack
+func (Codec) Encode(w io.Writer, v any) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+// Decode reads MessagePack into v
+func (Codec) Decode(r io.Reader, v any) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
@@ -6,6 +6,7 @@ type config struct {
 	core       *core
 	route      *route
 	controller *controller
+	gateway    *Gateway
 }
 
 // mutate config.route affect route
@@ -23,6 +24,11 @@ func (c *config) bindController(controller *controller) {
 	c.controller = controller
 }
 
+// mutate config.gateway will affect gateway
+func (c *config) bindGateway(gateway *Gateway) {
+	c.gateway = gateway
+}
+
 // mutate config.app will affect app
 func (c *config) bindApp(app *app) {
 	c.app = app
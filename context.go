@@ -2,6 +2,7 @@ package ng
 
 import (
 	"context"
+	"sync"
 
 	nghttp "github.com/foxie-io/ng/http"
 )
@@ -27,6 +28,9 @@ type Context interface {
 	// release resources
 	Clear()
 
+	// OnClear registers fn running on Clear, last registered first
+	OnClear(fn func())
+
 	// store route data
 	setRoute(route Route) Context
 }
@@ -46,6 +50,10 @@ type requestContext struct {
 	storage  Storage
 	response nghttp.HTTPResponse
 	route    Route
+
+	// release hooks registered by OnClear
+	mu      sync.Mutex
+	onClear []func()
 }
 
 // newContext create new request context
@@ -62,10 +70,41 @@ func (r *requestContext) Storage() Storage {
 }
 
 func (r *requestContext) Clear() {
+	r.mu.Lock()
+	hooks := r.onClear
+	r.onClear = nil
+	r.mu.Unlock()
+
+	// last registered first, like defer
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+
 	r.storage.Clear()
 	r.storage = nil
 }
 
+// OnClear registers fn running on Clear, last registered first
+func (r *requestContext) OnClear(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onClear = append(r.onClear, fn)
+}
+
+// OnClear registers fn to release request resources, e.g. temp files, when the context is cleared.
+//
+// It reports false when ctx holds no request context, fn would never run,
+// callers should not acquire the resource then.
+func OnClear(ctx context.Context, fn func()) bool {
+	rc := GetContext(ctx)
+	if rc == nil {
+		return false
+	}
+
+	rc.OnClear(fn)
+	return true
+}
+
 // SetResponse set request response
 func (r *requestContext) SetResponse(resp nghttp.HTTPResponse) Context {
 	r.response = resp
@@ -101,12 +140,17 @@ func (r *requestContext) Clone() Context {
 
 	// clone storage
 	r.storage.Range(func(key, value any) bool {
-		clone.storage.Store(key.(PayloadKey), value)
+		clone.storage.Store(storedKey(key.(string)), value)
 		return true
 	})
 	return clone
 }
 
+// storedKey is a key already resolved by PayloadKeyer, as ranged over by Storage
+type storedKey string
+
+func (k storedKey) PayloadKey() string { return string(k) }
+
 func dynamicKey[T any](keys ...PayloadKeyer) PayloadKeyer {
 	if len(keys) == 0 {
 		return TypeKey[T]{}
@@ -129,6 +173,16 @@ func NewContext(ctx context.Context) (context.Context, Context) {
 	return withContext(ctx, rc), rc
 }
 
+// NewRouteContext create new request context bound to given route.
+//
+// Route data is normally attached by the request flow, this allows guards,
+// middlewares and interceptors to run outside of it, e.g. in unit tests.
+func NewRouteContext(ctx context.Context, route Route) (context.Context, Context) {
+	rc := newContext()
+	rc.setRoute(route)
+	return withContext(ctx, rc), rc
+}
+
 // acquireContext get or create request context
 func acquireContext(ctx context.Context) (c context.Context, rc Context, new bool) {
 	rc = GetContext(ctx)
@@ -20,6 +20,8 @@ type (
 	Core interface {
 		Prefix() string
 		Metadata(key any) (value any, found bool)
+		// RangeMetadata calls fn for each metadata entry until fn returns false
+		RangeMetadata(fn func(key, value any) bool)
 	}
 
 	/*core
@@ -55,6 +57,25 @@ type (
 		responseHandler ResponseHandler
 
 		valueHandler ValueHandler
+
+		// renders error responses, e.g. problem details
+		errorFormat ErrorFormat
+
+		// serializes *nghttp.Response values
+		envelope nghttp.Envelope
+
+		// convert errors to responses, most specific first
+		errorMappings []ErrorMapping
+
+		// localizes response messages
+		i18n *i18nConfig
+
+		// bind and validate typed handler requests
+		binder    Binder
+		validator Validator
+
+		// response and request body codecs
+		codecs []Codec
 	}
 )
 
@@ -62,10 +83,42 @@ func newCore() *core {
 	return &core{}
 }
 
+// clone copies core, slices are shared and must be replaced rather than appended
+func (c *core) clone() *core {
+	cp := &core{
+		prefix:          c.prefix,
+		preExecutes:     c.preExecutes,
+		middlewares:     c.middlewares,
+		guards:          c.guards,
+		interceptors:    c.interceptors,
+		handlers:        c.handlers,
+		responseHandler: c.responseHandler,
+		valueHandler:    c.valueHandler,
+		errorFormat:     c.errorFormat,
+		envelope:        c.envelope,
+		errorMappings:   c.errorMappings,
+		i18n:            c.i18n,
+		binder:          c.binder,
+		validator:       c.validator,
+		codecs:          c.codecs,
+	}
+
+	c.metadata.Range(func(key, value any) bool {
+		cp.metadata.Store(key, value)
+		return true
+	})
+	cp.built.Store(c.built.Load())
+	return cp
+}
+
 func (c *core) Metadata(key any) (value any, found bool) {
 	return c.metadata.Load(key)
 }
 
+func (c *core) RangeMetadata(fn func(key, value any) bool) {
+	c.metadata.Range(fn)
+}
+
 func (c *core) Prefix() string {
 	return c.prefix
 }
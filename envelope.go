@@ -0,0 +1,51 @@
+package ng
+
+// Envelopes select how *nghttp.Response values are serialized
+
+import (
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+/*
+WithEnvelope sets envelope of app, controller or route responses, the closest one wins.
+Responses created with nghttp.WithEnvelope keep their own envelope.
+
+	app := ng.NewApp(ng.WithEnvelope(nghttp.JSendEnvelope))
+
+	ng.NewRoute(http.MethodGet, "/users/{id}",
+		ng.WithHandler(getUser),
+		ng.WithEnvelope(nghttp.DataEnvelope),
+	)
+*/
+func WithEnvelope(envelope nghttp.Envelope) Option {
+	return func(c *config) {
+		c.core.envelope = envelope
+	}
+}
+
+// RouteEnvelope return envelope used by built route, nghttp.DefaultEnvelope when none is set
+func RouteEnvelope(r Route) nghttp.Envelope {
+	if c, ok := r.Core().(*core); ok && c.envelope != nil {
+		return c.envelope
+	}
+	return nghttp.DefaultEnvelope
+}
+
+// applyEnvelope sets envelope on responses without one, resp is copied since it may be shared
+func applyEnvelope(envelope nghttp.Envelope, resp nghttp.HTTPResponse) nghttp.HTTPResponse {
+	switch t := resp.(type) {
+	case *nghttp.Response:
+		if t.Envelope() == nil {
+			return t.With(nghttp.WithEnvelope(envelope))
+		}
+	case *nghttp.StreamResponse:
+		if t.Envelope() == nil {
+			return t.WithEnvelope(envelope)
+		}
+	case *nghttp.PanicError:
+		if inner, ok := t.Response().(*nghttp.Response); ok && inner.Envelope() == nil {
+			return t.With(nghttp.WithEnvelope(envelope))
+		}
+	}
+	return resp
+}
@@ -0,0 +1,48 @@
+package ng
+
+// Error formats render error responses in a different shape, e.g. RFC 9457 problem details
+
+import (
+	"context"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// ErrorFormat renders error response, resp is a copy and can be mutated.
+// Returned response is written by the ResponseHandler instead of resp.
+type ErrorFormat func(ctx context.Context, resp *nghttp.Response) nghttp.HTTPResponse
+
+/*
+WithErrorFormat renders every error *nghttp.Response of app, controller or route with format,
+success responses are written unchanged
+
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithErrorFormat(ngproblem.Format),
+	)
+*/
+func WithErrorFormat(format ErrorFormat) Option {
+	return func(c *config) {
+		c.core.errorFormat = format
+	}
+}
+
+// formatError applies format to error responses and panic errors
+func formatError(ctx context.Context, format ErrorFormat, resp nghttp.HTTPResponse) nghttp.HTTPResponse {
+	var errResp *nghttp.Response
+	switch t := resp.(type) {
+	case *nghttp.Response:
+		errResp = t
+	case *nghttp.PanicError:
+		errResp, _ = t.Response().(*nghttp.Response)
+	}
+
+	if errResp == nil || errResp.StatusCode() < 400 {
+		return resp
+	}
+
+	if formatted := format(ctx, errResp.With()); formatted != nil {
+		return formatted
+	}
+	return resp
+}
@@ -0,0 +1,84 @@
+package ng
+
+// Error mappings convert errors returned by handlers into responses
+
+import (
+	"context"
+	"errors"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// ErrorMapping converts err to response, ok is false when err is not handled
+type ErrorMapping func(err error) (resp nghttp.HTTPResponse, ok bool)
+
+/*
+MapError maps errors matching target with errors.Is
+
+	ng.WithErrorMapping(
+		ng.MapError(gorm.ErrRecordNotFound, func(err error) nghttp.HTTPResponse {
+			return nghttp.NewErrNotFound()
+		}),
+	)
+*/
+func MapError(target error, fn func(err error) nghttp.HTTPResponse) ErrorMapping {
+	return func(err error) (nghttp.HTTPResponse, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+		return fn(err), true
+	}
+}
+
+/*
+MapErrorAs maps errors of type T with errors.As
+
+	ng.MapErrorAs(func(err *pgconn.PgError) nghttp.HTTPResponse {
+		if err.Code == "23505" {
+			return nghttp.NewErrAlreadyExists()
+		}
+		return nghttp.NewErrInternal()
+	})
+*/
+func MapErrorAs[T error](fn func(err T) nghttp.HTTPResponse) ErrorMapping {
+	return func(err error) (nghttp.HTTPResponse, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return nil, false
+		}
+		return fn(target), true
+	}
+}
+
+// WithErrorMapping adds error mappings to app, controller or route,
+// route mappings are consulted first, then controller and app ones
+func WithErrorMapping(mappings ...ErrorMapping) Option {
+	return func(c *config) {
+		c.core.errorMappings = append(c.core.errorMappings, mappings...)
+	}
+}
+
+// mapError converts err with mappings of current route, mapped *nghttp.Response wraps err as cause.
+// ok is false for unmapped errors, the ValueHandler decides what they become.
+func mapError(ctx context.Context, err error) (nghttp.HTTPResponse, bool) {
+	var mappings []ErrorMapping
+	if rc := GetContext(ctx); rc != nil && rc.Route() != nil {
+		if c, ok := rc.Route().Core().(*core); ok {
+			mappings = c.errorMappings
+		}
+	}
+
+	for _, mapping := range mappings {
+		resp, ok := mapping(err)
+		if !ok || resp == nil {
+			continue
+		}
+
+		if r, ok := resp.(*nghttp.Response); ok && r.Unwrap() == nil {
+			resp = r.WithCause(err)
+		}
+		return resp, true
+	}
+
+	return nil, false
+}
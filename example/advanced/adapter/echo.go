@@ -14,21 +14,43 @@ import (
 func ResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	ectx := ng.MustLoad[echo.Context](ctx)
 
+	w := ectx.Response()
+	if stream, ok := info.(nghttp.Streamer); ok {
+		return nghttp.WriteStream(ctx, w, ectx.Request().Header, stream)
+	}
+
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return ResponseHandler(ctx, errResp)
+		}
+		defer reader.Close()
+		return nghttp.CopyBody(w, body, reader)
+	}
+
+	nghttp.WriteHeaders(w, info)
+	defer nghttp.WriteTrailers(w, info)
+
 	switch val := info.(type) {
 	case *nghttp.RawResponse:
-		return ectx.String(val.StatusCode(), string(val.Value()))
+		contentType := w.Header().Get(echo.HeaderContentType)
+		if contentType == "" {
+			contentType = echo.MIMETextPlainCharsetUTF8
+		}
+		return ectx.Blob(val.StatusCode(), contentType, val.Value())
 
 	case *nghttp.Response:
-		return ectx.JSON(val.StatusCode(), val.Response())
 
 	case *nghttp.PanicError:
 		fmt.Println("Unknown response type:", fmt.Sprintf("%T, value: %v", info, val.Value()), string(debug.Stack()))
-		return ectx.JSON(val.StatusCode(), val.Response())
+
+	default:
+		fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
+		info = nghttp.NewErrUnknown()
 	}
 
-	fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
-	resp := nghttp.NewErrUnknown()
-	return ectx.JSON(resp.StatusCode(), resp.Response())
+	status, contentType, body := ng.EncodeResponse(ctx, ectx.Request().Header.Get(echo.HeaderAccept), info)
+	return ectx.Blob(status, contentType, body)
 }
 
 func ToEchoHandler(scopeHandler func() ng.Handler) echo.HandlerFunc {
@@ -36,6 +58,7 @@ func ToEchoHandler(scopeHandler func() ng.Handler) echo.HandlerFunc {
 		ctx, rc := ng.NewContext(ectx.Request().Context())
 		defer rc.Clear()
 		ng.Store(ctx, ectx)
+		ng.Store(ctx, ng.PathParams(ectx.Param))
 		return scopeHandler()(ctx)
 	}
 }
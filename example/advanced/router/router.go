@@ -2,9 +2,10 @@ package router
 
 import (
 	"example/advanced/adapter"
-	"example/advanced/docs"
 
 	"github.com/foxie-io/ng"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+	ngdocs "github.com/foxie-io/ng/openapi/docs"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
 )
@@ -27,7 +28,11 @@ func NewRouter(p Param) *Router {
 func (r *Router) Register(app ng.App, echoApp *echo.Echo) {
 
 	app.AddController(r.globalControllers...)
-	app.AddController(NewSwaggerUI(docs.SwaggerInfo))
+	app.AddController(ngdocs.Controller(app, ngdocs.Options{
+		DocOptions: []ngopenapi.DocOption{
+			ngopenapi.WithInfo(ngopenapi.Info{Title: "Advanced Example", Version: "1.0.0"}),
+		},
+	}, ng.SkipAllGuards()))
 	app.Build()
 
 	adapter.RegisterRoutes(app, echoApp)
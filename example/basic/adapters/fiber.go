@@ -1,9 +1,12 @@
 package adapters
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net/http"
 	"runtime/debug"
+	"strconv"
 
 	"github.com/foxie-io/ng"
 	nghttp "github.com/foxie-io/ng/http"
@@ -13,25 +16,81 @@ import (
 func FiberResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	fctx := ng.MustLoad[*fiber.Ctx](ctx)
 
+	if stream, ok := info.(nghttp.Streamer); ok {
+		stream = stream.Prepare(http.Header(fctx.GetReqHeaders()))
+		FiberWriteHeaders(fctx, stream)
+		fctx.Status(stream.StatusCode())
+
+		// fasthttp runs stream writer after handler returns, FiberHandler keeps context until it ends
+		fctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer ng.GetContext(ctx).Clear()
+			_ = stream.Stream(ctx, w, w.Flush)
+		})
+		return nil
+	}
+
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return FiberResponseHandler(ctx, errResp)
+		}
+
+		size := -1
+		if length := body.Headers().Get(fiber.HeaderContentLength); length != "" {
+			size, _ = strconv.Atoi(length)
+			body.Headers().Del(fiber.HeaderContentLength)
+		}
+
+		// fasthttp closes reader after streaming
+		FiberWriteHeaders(fctx, info)
+		return fctx.Status(body.StatusCode()).SendStream(reader, size)
+	}
+
+	FiberWriteHeaders(fctx, info)
+
 	switch val := info.(type) {
 	case *nghttp.RawResponse:
 		return fctx.Status(val.StatusCode()).SendString(string(val.Value()))
 	case *nghttp.Response:
-		return fctx.Status(val.StatusCode()).JSON(val.Response())
 	case *nghttp.PanicError:
 		fmt.Println("Unknown response type:", fmt.Sprintf("%T, value: %v", info, val.Value()), string(debug.Stack()))
-		fctx.Status(info.StatusCode()).JSON(info.Response())
-		return nil
+	default:
+		fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
+	}
+
+	status, contentType, body := ng.EncodeResponse(ctx, fctx.Get(fiber.HeaderAccept), info)
+	fctx.Set(fiber.HeaderContentType, contentType)
+	return fctx.Status(status).Send(body)
+}
+
+// FiberWriteHeaders sets headers and cookies of nghttp response on fiber context,
+// trailers are skipped because fasthttp only writes them for streamed bodies
+func FiberWriteHeaders(fctx *fiber.Ctx, info nghttp.HTTPResponse) {
+	carrier, ok := info.(nghttp.HeaderCarrier)
+	if !ok {
+		return
+	}
+
+	for key, values := range carrier.Headers() {
+		for _, value := range values {
+			fctx.Append(key, value)
+		}
 	}
 
-	fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
-	return fctx.Status(info.StatusCode()).JSON(info.Response())
+	for _, cookie := range carrier.Cookies() {
+		fctx.Response().Header.Add(fiber.HeaderSetCookie, cookie.String())
+	}
 }
 
 func FiberHandler(scopeHandler func() ng.Handler) fiber.Handler {
 	return func(fctx *fiber.Ctx) error {
 		ctx, rc := ng.NewContext(fctx.Context())
-		defer rc.Clear()
+		defer func() {
+			// streamed responses clear context once stream ends
+			if _, streaming := rc.GetResponse().(nghttp.Streamer); !streaming {
+				rc.Clear()
+			}
+		}()
 
 		// store fiber context
 		ng.Store(ctx, fctx)
@@ -2,7 +2,6 @@ package adapters
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -13,29 +12,39 @@ import (
 
 func ServeMuxResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	w := ng.MustLoad[http.ResponseWriter](ctx)
-	w.WriteHeader(info.StatusCode())
 
-	switch val := info.(type) {
-	case *nghttp.RawResponse:
-		_, err := w.Write(val.Value())
-		return err
+	header := http.Header{}
+	if r, err := ng.Load[*http.Request](ctx); err == nil {
+		header = r.Header
+	}
+
+	if stream, ok := info.(nghttp.Streamer); ok {
+		return nghttp.WriteStream(ctx, w, header, stream)
+	}
 
-	case *nghttp.Response:
-		jsonbytes, _ := json.Marshal(info.Response())
-		_, err := w.Write(jsonbytes)
-		return err
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return ServeMuxResponseHandler(ctx, errResp)
+		}
+		defer reader.Close()
+		return nghttp.CopyBody(w, body, reader)
+	}
 
-	case *nghttp.PanicError:
+	if val, ok := info.(*nghttp.PanicError); ok {
 		fmt.Println("Unknown response type:", fmt.Sprintf("%T, value: %v", info, val.Value()), string(debug.Stack()))
-		jsonbytes, _ := json.Marshal(info.Response())
-		_, err := w.Write(jsonbytes)
-		return err
 	}
 
-	fmt.Println("Unknown response type:", fmt.Sprintf("%T, info", info), string(debug.Stack()))
-	jsonbytes, _ := json.Marshal(info.Response())
-	_, _ = w.Write(jsonbytes)
-	return nil
+	status, contentType, body := ng.EncodeResponse(ctx, header.Get("Accept"), info)
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	nghttp.WriteHeaders(w, info)
+	w.WriteHeader(status)
+	_, err := w.Write(body)
+	nghttp.WriteTrailers(w, info)
+	return err
 }
 
 func ServeMuxHandler(scopeHandler func() ng.Handler) http.HandlerFunc {
@@ -43,8 +52,9 @@ func ServeMuxHandler(scopeHandler func() ng.Handler) http.HandlerFunc {
 		ctx, rc := ng.NewContext(r.Context())
 		defer rc.Clear()
 
-		// store http.ResponseWriter in context
+		// store http.ResponseWriter and *http.Request in context
 		ng.Store(ctx, w)
+		ng.Store(ctx, r)
 
 		ip := r.RemoteAddr
 		ng.Store(ctx, ClientIp(ip))
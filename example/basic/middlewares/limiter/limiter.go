@@ -3,6 +3,7 @@ package limiter
 import (
 	"context"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 
@@ -45,7 +46,15 @@ var defaultConfig = &Config{
 		return "default-client-id"
 	},
 	ErrorHandler: func(ctx context.Context) error {
-		return ng.Respond(ctx, nghttp.NewErrTooManyRequests())
+		client := ng.MustLoad[*ClientData](ctx)
+		reset := strconv.Itoa(int(time.Until(client.ResetAt).Seconds()) + 1)
+
+		return nghttp.NewErrTooManyRequests().Update(
+			nghttp.WithHeader("RateLimit-Limit", strconv.Itoa(client.Limit)),
+			nghttp.WithHeader("RateLimit-Remaining", "0"),
+			nghttp.WithHeader("RateLimit-Reset", reset),
+			nghttp.WithHeader("Retry-After", reset),
+		)
 	},
 }
 
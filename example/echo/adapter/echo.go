@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 
 	"github.com/foxie-io/ng"
@@ -13,21 +14,39 @@ import (
 func EchoResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	ectx := ng.MustLoad[echo.Context](ctx)
 
+	w := ectx.Response()
+	if stream, ok := info.(nghttp.Streamer); ok {
+		return nghttp.WriteStream(ctx, w, ectx.Request().Header, stream)
+	}
+
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return EchoResponseHandler(ctx, errResp)
+		}
+		defer reader.Close()
+		return nghttp.CopyBody(w, body, reader)
+	}
+
+	nghttp.WriteHeaders(w, info)
+	defer nghttp.WriteTrailers(w, info)
+
 	switch val := info.(type) {
 	case *nghttp.RawResponse:
 		return ectx.String(val.StatusCode(), string(val.Value()))
 
 	case *nghttp.Response:
-		return ectx.JSON(val.StatusCode(), val.Response())
 
 	case *nghttp.PanicError:
 		fmt.Println("Unknown response type:", fmt.Sprintf("%T, value: %v", info, val.Value()), string(debug.Stack()))
-		return ectx.JSON(val.StatusCode(), val.Response())
+
+	default:
+		fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
+		info = nghttp.NewErrUnknown()
 	}
 
-	fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
-	resp := nghttp.NewErrUnknown()
-	return ectx.JSON(resp.StatusCode(), resp.Response())
+	status, contentType, body := ng.EncodeResponse(ctx, ectx.Request().Header.Get(echo.HeaderAccept), info)
+	return ectx.Blob(status, contentType, body)
 }
 
 func EchoHandler(scopeHandler func() ng.Handler) echo.HandlerFunc {
@@ -37,6 +56,11 @@ func EchoHandler(scopeHandler func() ng.Handler) echo.HandlerFunc {
 
 		// store echo context
 		ng.Store(ctx, echoCtx)
+		ng.Store(ctx, ng.PathParams(echoCtx.Param))
+
+		// store net/http writer and request for mounted http.Handler routes
+		ng.Store(ctx, http.ResponseWriter(echoCtx.Response()))
+		ng.Store(ctx, echoCtx.Request())
 
 		// get echo context from ng ctx
 		// echoCtx := ng.MustLoad[echo.Context](ctx)
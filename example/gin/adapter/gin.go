@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 
 	"github.com/foxie-io/ng"
@@ -13,31 +14,51 @@ import (
 func GinResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
 	ginctx := ng.MustLoad[*gin.Context](ctx)
 
+	if stream, ok := info.(nghttp.Streamer); ok {
+		return nghttp.WriteStream(ctx, ginctx.Writer, ginctx.Request.Header, stream)
+	}
+
+	if body, ok := info.(*nghttp.BodyResponse); ok {
+		reader, errResp := body.Open()
+		if errResp != nil {
+			return GinResponseHandler(ctx, errResp)
+		}
+		defer reader.Close()
+		return nghttp.CopyBody(ginctx.Writer, body, reader)
+	}
+
+	nghttp.WriteHeaders(ginctx.Writer, info)
+	defer nghttp.WriteTrailers(ginctx.Writer, info)
+
 	switch val := info.(type) {
 	case *nghttp.RawResponse:
 		ginctx.Writer.WriteHeader(val.StatusCode())
 		ginctx.Writer.Write(val.Value())
 		return nil
 	case *nghttp.Response:
-		ginctx.JSON(info.StatusCode(), info.Response())
-		return nil
 	case *nghttp.PanicError:
 		fmt.Println("Unknown response type:", fmt.Sprintf("%T, value: %v", info, val.Value()), string(debug.Stack()))
-		ginctx.JSON(info.StatusCode(), info.Response())
-		return nil
+	default:
+		fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
 	}
 
-	fmt.Println("Unknown response type:", fmt.Sprintf("%T", info), string(debug.Stack()))
-	ginctx.JSON(info.StatusCode(), info.Response())
+	status, contentType, body := ng.EncodeResponse(ctx, ginctx.GetHeader("Accept"), info)
+	ginctx.Data(status, contentType, body)
 	return nil
 }
 
 func GinHandler(scopeHandler func() ng.Handler) gin.HandlerFunc {
 	return func(gctx *gin.Context) {
-		ctx, _ := ng.NewContext(gctx.Request.Context())
+		ctx, rc := ng.NewContext(gctx.Request.Context())
+		defer rc.Clear()
 
 		// Store Gin context in NG context
 		ng.Store(ctx, gctx)
+		ng.Store(ctx, ng.PathParams(gctx.Param))
+
+		// store net/http writer and request for mounted http.Handler routes
+		ng.Store(ctx, http.ResponseWriter(gctx.Writer))
+		ng.Store(ctx, gctx.Request)
 
 		// Invoke the handler
 		scopeHandler()(ctx)
@@ -0,0 +1,529 @@
+package ng
+
+// WebSocket gateways: event based message handlers on upgraded connections
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+
+	nghttp "github.com/foxie-io/ng/http"
+	ngws "github.com/foxie-io/ng/ws"
+)
+
+var _ ControllerInitializer = (*Gateway)(nil)
+
+type (
+	// GatewayMessage is a JSON frame sent by clients: {"event": "chat.send", "id": "1", "data": {...}}
+	GatewayMessage struct {
+		Event string `json:"event"`
+
+		// ID is echoed in the reply, messages without ID only get replies on Respond or error
+		ID string `json:"id,omitempty"`
+
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	// GatewayHook runs on connection lifecycle events
+	GatewayHook func(ctx context.Context, client *GatewayClient) error
+
+	// GatewayCloseHook runs after a connection is closed, err is nil on normal closure
+	GatewayCloseHook func(ctx context.Context, client *GatewayClient, err error)
+
+	// Gateway serves WebSocket connections on a route and dispatches messages to handlers by event name
+	Gateway struct {
+		route        *route
+		handlers     map[string]Handler
+		onConnect    []GatewayHook
+		onDisconnect []GatewayCloseHook
+		readLimit    int64
+		checkOrigin  func(r *http.Request) bool
+
+		// built by the request flow, interceptors wrap every message
+		dispatch  Handler
+		transform ValueHandler
+
+		mu      sync.RWMutex
+		clients map[*GatewayClient]struct{}
+		rooms   map[string]map[*GatewayClient]struct{}
+	}
+
+	// GatewayClient is a connected client
+	GatewayClient struct {
+		id      string
+		ctx     context.Context
+		conn    *ngws.Conn
+		gateway *Gateway
+		rooms   map[string]struct{}
+	}
+
+	// GatewayBroadcast emits events to a set of clients, see Gateway.To
+	GatewayBroadcast struct {
+		gateway *Gateway
+		rooms   []string
+		except  []*GatewayClient
+	}
+)
+
+/*
+NewGateway create WebSocket gateway served on GET path.
+
+The gateway is a controller: app guards and gateway guards run on the upgrade request,
+interceptors run around every message. Handlers reply with ng.Respond, errors are sent
+back with the event and id of the message.
+
+	chat := ng.NewGateway("/ws/chat",
+		ng.WithGuards(authGuard),
+		ng.OnConnect(func(ctx context.Context, client *ng.GatewayClient) error {
+			client.Join("lobby")
+			return nil
+		}),
+		ng.OnMessage("chat.send", ng.ScopeHandler(func() ng.Handler {
+			var body ChatMessage
+			return ng.Handle(
+				ng.BindMessage(&body),
+				func(ctx context.Context) error {
+					client := ng.MustLoad[*ng.GatewayClient](ctx)
+					return client.Gateway().To(body.Room).Emit("chat.message", body)
+				},
+			)
+		})),
+	)
+
+	app.AddController(chat)
+
+Connections are hijacked from net/http, the adapter must store http.ResponseWriter and *http.Request.
+*/
+func NewGateway(path string, opts ...Option) *Gateway {
+	g := &Gateway{
+		handlers:  map[string]Handler{},
+		readLimit: ngws.DefaultReadLimit,
+		clients:   map[*GatewayClient]struct{}{},
+		rooms:     map[string]map[*GatewayClient]struct{}{},
+	}
+
+	g.route = &route{
+		method:  http.MethodGet,
+		path:    normolizePath(path),
+		core:    newCore(),
+		gateway: g,
+	}
+
+	config := newConfig()
+	config.bindRoute(g.route)
+	config.bindCore(g.route.core)
+	config.bindGateway(g)
+	config.update(WithHandler(g.upgrade), Opitons(opts...))
+	return g
+}
+
+// InitializeController allows the gateway to be added with App.AddController
+func (g *Gateway) InitializeController() Controller {
+	return NewController()
+}
+
+// Route return upgrade route, it can also be returned from a controller method
+func (g *Gateway) Route() Route {
+	return g.route
+}
+
+// OnMessage registers handler for messages with given event name
+func OnMessage(event string, handler Handler) Option {
+	return func(c *config) {
+		mustGateway(c, "OnMessage").handlers[event] = handler
+	}
+}
+
+// OnConnect registers hook running after upgrade, an error closes the connection
+func OnConnect(hook GatewayHook) Option {
+	return func(c *config) {
+		g := mustGateway(c, "OnConnect")
+		g.onConnect = append(g.onConnect, hook)
+	}
+}
+
+// OnDisconnect registers hook running after the connection is closed
+func OnDisconnect(hook GatewayCloseHook) Option {
+	return func(c *config) {
+		g := mustGateway(c, "OnDisconnect")
+		g.onDisconnect = append(g.onDisconnect, hook)
+	}
+}
+
+// WithMessageLimit sets maximum size of a message, larger messages close the connection
+func WithMessageLimit(limit int64) Option {
+	return func(c *config) {
+		mustGateway(c, "WithMessageLimit").readLimit = limit
+	}
+}
+
+// WithCheckOrigin sets the handshake origin check, cross-origin handshakes are rejected by default
+/*
+	ng.WithCheckOrigin(func(r *http.Request) bool {
+		return r.Header.Get("Origin") == "https://app.example.com"
+	})
+*/
+func WithCheckOrigin(check func(r *http.Request) bool) Option {
+	return func(c *config) {
+		mustGateway(c, "WithCheckOrigin").checkOrigin = check
+	}
+}
+
+func mustGateway(c *config, option string) *Gateway {
+	if c.gateway == nil {
+		panic(option + " can only be used with NewGateway")
+	}
+	return c.gateway
+}
+
+// BindMessage decodes data of current gateway message into dst
+func BindMessage(dst any) Handler {
+	return func(ctx context.Context) error {
+		msg, err := Load[*GatewayMessage](ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(msg.Data) == 0 {
+			return nil
+		}
+
+		if err := json.Unmarshal(msg.Data, dst); err != nil {
+			return nghttp.NewErrInvalidArgument().Update(nghttp.WithMessage(err.Error()))
+		}
+		return nil
+	}
+}
+
+// To selects clients in any of given rooms, all clients when no room is given
+func (g *Gateway) To(rooms ...string) *GatewayBroadcast {
+	return &GatewayBroadcast{gateway: g, rooms: rooms}
+}
+
+// Broadcast emits event to all connected clients
+func (g *Gateway) Broadcast(event string, data any) error {
+	return g.To().Emit(event, data)
+}
+
+// Clients return connected clients
+func (g *Gateway) Clients() []*GatewayClient {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	clients := make([]*GatewayClient, 0, len(g.clients))
+	for c := range g.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// Except excludes clients, e.g. the sender
+func (b *GatewayBroadcast) Except(clients ...*GatewayClient) *GatewayBroadcast {
+	b.except = append(b.except, clients...)
+	return b
+}
+
+// Emit sends event to selected clients, failed writes are joined into the returned error
+func (b *GatewayBroadcast) Emit(event string, data any) error {
+	frame, err := json.Marshal(gatewayFrame{Event: event, Data: data})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, c := range b.clients() {
+		if err := c.conn.WriteMessage(ngws.TextMessage, frame); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *GatewayBroadcast) clients() []*GatewayClient {
+	g := b.gateway
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	selected := map[*GatewayClient]struct{}{}
+	if len(b.rooms) == 0 {
+		selected = maps.Clone(g.clients)
+	}
+	for _, room := range b.rooms {
+		for c := range g.rooms[room] {
+			selected[c] = struct{}{}
+		}
+	}
+	for _, c := range b.except {
+		delete(selected, c)
+	}
+
+	clients := make([]*GatewayClient, 0, len(selected))
+	for c := range selected {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// ID return unique client id
+func (c *GatewayClient) ID() string { return c.id }
+
+// Context return connection context, values stored by middlewares and guards on upgrade are available
+func (c *GatewayClient) Context() context.Context { return c.ctx }
+
+// Gateway return gateway serving the client
+func (c *GatewayClient) Gateway() *Gateway { return c.gateway }
+
+// Conn return underlying WebSocket connection
+func (c *GatewayClient) Conn() *ngws.Conn { return c.conn }
+
+// Emit sends event to the client
+func (c *GatewayClient) Emit(event string, data any) error {
+	return c.send(gatewayFrame{Event: event, Data: data})
+}
+
+// Join adds client to rooms
+func (c *GatewayClient) Join(rooms ...string) {
+	g := c.gateway
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, room := range rooms {
+		if g.rooms[room] == nil {
+			g.rooms[room] = map[*GatewayClient]struct{}{}
+		}
+		g.rooms[room][c] = struct{}{}
+		c.rooms[room] = struct{}{}
+	}
+}
+
+// Leave removes client from rooms
+func (c *GatewayClient) Leave(rooms ...string) {
+	g := c.gateway
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.leave(c, rooms...)
+}
+
+// Rooms return rooms the client joined
+func (c *GatewayClient) Rooms() []string {
+	c.gateway.mu.RLock()
+	defer c.gateway.mu.RUnlock()
+	return c.roomNames()
+}
+
+// Close starts closing handshake with given close code, e.g. ngws.ClosePolicyViolation
+func (c *GatewayClient) Close(code int, reason string) error {
+	return c.conn.WriteClose(code, reason)
+}
+
+func (c *GatewayClient) send(frame any) error {
+	b, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(ngws.TextMessage, b)
+}
+
+// gatewayFrame is a server event frame
+type gatewayFrame struct {
+	Event string `json:"event"`
+	Data  any    `json:"data,omitempty"`
+}
+
+// bind receives message flow built by the route
+func (g *Gateway) bind(dispatch Handler, transform ValueHandler) {
+	g.dispatch = dispatch
+	g.transform = transform
+}
+
+// upgrade is the route handler, it blocks until the connection is closed
+func (g *Gateway) upgrade(ctx context.Context) error {
+	w, err := Load[http.ResponseWriter](ctx)
+	if err != nil {
+		return errors.New("http.ResponseWriter not found in context, adapter must store it")
+	}
+
+	r, err := Load[*http.Request](ctx)
+	if err != nil {
+		return errors.New("*http.Request not found in context, adapter must store it")
+	}
+
+	var opts []ngws.UpgradeOption
+	if g.checkOrigin != nil {
+		opts = append(opts, ngws.WithCheckOrigin(g.checkOrigin))
+	}
+
+	conn, err := ngws.Upgrade(w, r, nil, opts...)
+	if err != nil {
+		var he *ngws.HandshakeError
+		if errors.As(err, &he) && he.Status < http.StatusInternalServerError {
+			return handshakeError(he)
+		}
+		return err
+	}
+
+	conn.SetReadLimit(g.readLimit)
+	g.serve(ctx, conn)
+
+	return Respond(ctx, nghttp.NewWrittenResponse(http.StatusSwitchingProtocols, 0))
+}
+
+// handshakeError return response of rejected handshake with code matching its status
+func handshakeError(he *ngws.HandshakeError) *nghttp.Response {
+	var resp *nghttp.Response
+	switch he.Status {
+	case http.StatusUnauthorized:
+		resp = nghttp.NewErrUnauthenticated()
+	case http.StatusForbidden:
+		resp = nghttp.NewErrPermissionDenied()
+	default:
+		resp = nghttp.NewErrBadRequest()
+	}
+	return resp.Update(nghttp.WithMessage(he.Reason), nghttp.WithStatusCode(he.Status))
+}
+
+func (g *Gateway) serve(ctx context.Context, conn *ngws.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := &GatewayClient{id: newClientID(), ctx: ctx, conn: conn, gateway: g, rooms: map[string]struct{}{}}
+
+	g.mu.Lock()
+	g.clients[client] = struct{}{}
+	g.mu.Unlock()
+
+	var closeErr error
+	defer func() {
+		g.mu.Lock()
+		delete(g.clients, client)
+		g.leave(client, client.roomNames()...)
+		g.mu.Unlock()
+
+		_ = conn.Close()
+		for _, hook := range g.onDisconnect {
+			hook(ctx, client, closeErr)
+		}
+	}()
+
+	for _, hook := range g.onConnect {
+		if closeErr = hook(ctx, client); closeErr != nil {
+			_ = conn.WriteClose(ngws.ClosePolicyViolation, closeErr.Error())
+			return
+		}
+	}
+
+	for {
+		opcode, data, err := conn.ReadMessage()
+		if err != nil {
+			closeErr = normalCloseError(err)
+			return
+		}
+		g.handle(ctx, client, opcode, data)
+	}
+}
+
+// handle runs one message through interceptors and handler, then replies
+func (g *Gateway) handle(ctx context.Context, client *GatewayClient, opcode int, data []byte) {
+	msg := &GatewayMessage{}
+	if opcode != ngws.TextMessage || json.Unmarshal(data, msg) != nil || msg.Event == "" {
+		client.reply(&GatewayMessage{Event: "error"}, nghttp.NewErrInvalidArgument().Update(
+			nghttp.WithMessage("invalid message frame"),
+		))
+		return
+	}
+
+	// every message gets own context, values stored on upgrade are copied
+	mrc := GetContext(ctx).Clone()
+	mctx := withContext(ctx, mrc)
+	defer mrc.Clear()
+
+	mrc.SetResponse(nil)
+	Store(mctx, msg)
+	Store(mctx, client)
+
+	_ = g.dispatch(mctx)
+
+	val := mrc.GetResponse()
+	if val == nil {
+		if msg.ID == "" {
+			return
+		}
+		val = nghttp.NewResponse(nil)
+	}
+	client.reply(msg, g.transform(mctx, val))
+}
+
+// handleMessage runs handler registered for the message event
+func (g *Gateway) handleMessage(ctx context.Context) error {
+	msg := MustLoad[*GatewayMessage](ctx)
+
+	handler, ok := g.handlers[msg.Event]
+	if !ok {
+		return nghttp.NewErrNotFound().Update(nghttp.WithMessage("unknown event: " + msg.Event))
+	}
+	return handler(ctx)
+}
+
+// reply sends response with event and id of msg, e.g. {"event":"chat.send","id":"1","code":"OK","data":...}
+func (c *GatewayClient) reply(msg *GatewayMessage, resp nghttp.HTTPResponse) {
+	body, err := json.Marshal(resp.Response())
+	if err != nil {
+		body, _ = json.Marshal(nghttp.NewErrInternal().Response())
+	}
+
+	fields := map[string]json.RawMessage{}
+	if json.Unmarshal(body, &fields) != nil {
+		fields = map[string]json.RawMessage{"data": body}
+	}
+
+	fields["event"], _ = json.Marshal(msg.Event)
+	if msg.ID != "" {
+		fields["id"], _ = json.Marshal(msg.ID)
+	}
+
+	_ = c.send(fields)
+}
+
+func (c *GatewayClient) roomNames() []string {
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// leave must be called with g.mu held
+func (g *Gateway) leave(c *GatewayClient, rooms ...string) {
+	for _, room := range rooms {
+		delete(g.rooms[room], c)
+		if len(g.rooms[room]) == 0 {
+			delete(g.rooms, room)
+		}
+		delete(c.rooms, room)
+	}
+}
+
+// normalCloseError return nil for normal closure and dropped connections after close
+func normalCloseError(err error) error {
+	var ce *ngws.CloseError
+	if errors.As(err, &ce) && (ce.Code == ngws.CloseNormal || ce.Code == ngws.CloseGoingAway || ce.Code == ngws.CloseNoStatus) {
+		return nil
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func newClientID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,6 +1,9 @@
 package ng
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Guard is used to determine if a request is allowed to proceed.
 // Guards are typically used for authorization and access control.
@@ -31,3 +34,38 @@ type GuardFunc func(ctx context.Context) error
 func (gf GuardFunc) Allow(ctx context.Context) error {
 	return gf(ctx)
 }
+
+// ReplaceGuards returns a copy of built route with every guard passed through replace,
+// the route itself is not changed.
+//
+// It is intended for tests, e.g. bypassing authorization without editing
+// production options, see ngtest.OverrideGuard.
+func ReplaceGuards(r Route, replace func(guard Guard) Guard) Route {
+	orig, ok := r.(*route)
+	if !ok {
+		panic(fmt.Sprintf("ng: cannot replace guards of %T", r))
+	}
+
+	if !orig.core.built.Load() {
+		panic("ng: cannot replace guards of route that has not built yet")
+	}
+
+	cp := &route{
+		core:   orig.core.clone(),
+		name:   orig.name,
+		method: orig.method,
+		path:   orig.path,
+
+		// gateway stays bound to the original route
+		gateway: orig.gateway,
+	}
+
+	guards := make([]Guard, 0, len(cp.core.guards))
+	for _, guard := range cp.core.guards {
+		guards = append(guards, replace(guard))
+	}
+	cp.core.guards = guards
+
+	cp.handler = cp.buildRequestFlow()
+	return cp
+}
@@ -0,0 +1,167 @@
+package nghttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var _ interface {
+	HTTPResponse
+	HeaderCarrier
+} = (*BodyResponse)(nil)
+
+// BodyResponse represents a response whose body is streamed from a reader instead of being encoded,
+// e.g. no content, redirects, files and downloads.
+//
+// Response handlers open the body with Open, apply headers with WriteHeaders and copy it to the client
+// without buffering, see CopyBody.
+type BodyResponse struct {
+	responseHeader
+	s    int
+	open func() (io.ReadCloser, int64, error)
+}
+
+// StatusCode return http status code
+func (b *BodyResponse) StatusCode() int { return b.s }
+
+// Response return nil, body is streamed with Open
+func (b *BodyResponse) Response() any { return nil }
+
+// Open opens body and sets Content-Length header when size is known.
+//
+// When body cannot be opened, e.g. file does not exist, an error response is returned instead
+// and should be written by the response handler.
+func (b *BodyResponse) Open() (io.ReadCloser, *Response) {
+	if b.open == nil {
+		return http.NoBody, nil
+	}
+
+	body, size, err := b.open()
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return nil, NewErrNotFound()
+		case errors.Is(err, os.ErrPermission):
+			return nil, NewErrPermissionDenied()
+		default:
+			return nil, NewErrInternal().Update(WithCause(err))
+		}
+	}
+
+	if size >= 0 {
+		b.Headers().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	return body, nil
+}
+
+// Update applies response options to status and headers, e.g. WithStatusCode, WithHeader, WithCookie
+func (b *BodyResponse) Update(opts ...Option) *BodyResponse {
+	r := &Response{statusCode: b.s, responseHeader: b.responseHeader}
+	r.Update(opts...)
+	b.s, b.responseHeader = r.statusCode, r.responseHeader
+	return b
+}
+
+// NoContent create 204 No Content response
+func NoContent() *BodyResponse {
+	return &BodyResponse{s: http.StatusNoContent}
+}
+
+// Redirect create redirect response to url with given 3xx status code, e.g. http.StatusFound
+func Redirect(url string, statusCode int) *BodyResponse {
+	b := &BodyResponse{s: statusCode}
+	b.Headers().Set("Location", url)
+	b.open = func() (io.ReadCloser, int64, error) { return http.NoBody, 0, nil }
+	return b
+}
+
+// Reader create 200 OK response streaming r, size -1 means unknown length
+func Reader(r io.Reader, contentType string, size int64) *BodyResponse {
+	b := &BodyResponse{s: http.StatusOK}
+	if contentType != "" {
+		b.Headers().Set("Content-Type", contentType)
+	}
+	b.open = func() (io.ReadCloser, int64, error) { return readCloser(r), size, nil }
+	return b
+}
+
+// File create 200 OK response streaming file at path, content type is detected from extension
+func File(path string) *BodyResponse {
+	b := &BodyResponse{s: http.StatusOK}
+	b.Headers().Set("Content-Type", contentTypeOf(path))
+	b.open = func() (io.ReadCloser, int64, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		stat, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+
+		if stat.IsDir() {
+			f.Close()
+			return nil, 0, os.ErrNotExist
+		}
+		return f, stat.Size(), nil
+	}
+	return b
+}
+
+// Attachment create 200 OK download response streaming r as file name,
+// content type is detected from name extension
+func Attachment(name string, r io.Reader) *BodyResponse {
+	b := Reader(r, contentTypeOf(name), -1)
+	b.Headers().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	return b
+}
+
+// CopyBody writes headers and status of resp to w, then streams body and trailers.
+// body must be opened with resp.Open.
+func CopyBody(w http.ResponseWriter, resp *BodyResponse, body io.Reader) error {
+	WriteHeaders(w, resp)
+	w.WriteHeader(resp.StatusCode())
+
+	_, err := io.Copy(w, body)
+	WriteTrailers(w, resp)
+	return err
+}
+
+func readCloser(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(r)
+}
+
+func contentTypeOf(name string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// WriteStream prepares s with request header, writes headers and status to w, then streams body and trailers,
+// each flush is pushed to the client through http.ResponseController
+func WriteStream(ctx context.Context, w http.ResponseWriter, req http.Header, s Streamer) error {
+	s = s.Prepare(req)
+	WriteHeaders(w, s)
+	w.WriteHeader(s.StatusCode())
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	err := s.Stream(ctx, w, rc.Flush)
+	WriteTrailers(w, s)
+	return err
+}
@@ -0,0 +1,140 @@
+package nghttp
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// internal metadata keys, never serialized
+const (
+	// MetadataCause is metadata key of error wrapped by response, see WithCause
+	MetadataCause = "cause"
+
+	// MetadataStack is metadata key of stack captured with the cause, see EnableStackCapture
+	MetadataStack = "stack"
+
+	// MetadataMessageID is metadata key of message id, see WithMessageID
+	MetadataMessageID = "messageID"
+
+	// MetadataMessageArgs is metadata key of message template args, see WithMessageID
+	MetadataMessageArgs = "messageArgs"
+)
+
+var captureStack atomic.Bool
+
+// EnableStackCapture captures call stack when a cause is attached, it is meant for development
+func EnableStackCapture(enabled bool) {
+	captureStack.Store(enabled)
+}
+
+/*
+Wrap return copy of resp wrapping cause, errors.Is and errors.As see through the response
+while clients only see resp
+
+	user, err := repo.Find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nghttp.Wrap(err, nghttp.NewErrNotFound())
+	}
+*/
+func Wrap(cause error, resp *Response) *Response {
+	return resp.With(causeOption(cause, callers(cause, 3)))
+}
+
+// WithCause return copy of response wrapping err, see Wrap
+func (r *Response) WithCause(err error) *Response {
+	return r.With(causeOption(err, callers(err, 3)))
+}
+
+// WithCause returns an Option that sets error wrapped by the Response,
+// the call stack is captured when stack capture is enabled
+func WithCause(err error) Option {
+	return causeOption(err, callers(err, 3))
+}
+
+// causeOption sets cause and its captured stack
+func causeOption(err error, stack []uintptr) Option {
+	return func(r *Response) {
+		Metadata(MetadataCause, err)(r)
+		if stack != nil {
+			Metadata(MetadataStack, stack)(r)
+		}
+	}
+}
+
+// callers captures stack when enabled, skip counts frames like runtime.Callers from the caller of callers,
+// public entry points pass 3 so the stack starts at their caller
+func callers(err error, skip int) []uintptr {
+	if !captureStack.Load() || err == nil {
+		return nil
+	}
+
+	stack := make([]uintptr, 32)
+	return stack[:runtime.Callers(skip, stack)]
+}
+
+// Unwrap return wrapped cause
+func (r *Response) Unwrap() error {
+	cause, _ := r.GetMetadata(MetadataCause)
+	err, _ := cause.(error)
+	return err
+}
+
+// Is reports whether target is the same response, copies made by With match their original,
+// so sentinel responses still match after Wrap. Use HasCode to match any response with a code.
+func (r *Response) Is(target error) bool {
+	t, ok := target.(*Response)
+	return ok && t.origin() == r.origin()
+}
+
+// origin return response r was copied from, r itself when it is not a copy
+func (r *Response) origin() *Response {
+	if r.root != nil {
+		return r.root
+	}
+	return r
+}
+
+// HasCode reports whether any response in err's chain has code
+/*
+	if nghttp.HasCode(err, nghttp.CodeNotFound) {
+		...
+	}
+*/
+func HasCode(err error, code Code) bool {
+	var resp *Response
+	for err != nil && errors.As(err, &resp) {
+		if resp.Code == code {
+			return true
+		}
+		err = resp.Unwrap()
+	}
+	return false
+}
+
+// Stack return call stack captured with the cause, empty when stack capture is disabled
+func (r *Response) Stack() string {
+	val, _ := r.GetMetadata(MetadataStack)
+	pcs, _ := val.([]uintptr)
+	if len(pcs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// Unwrap return panic value when it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.v.(error)
+	return err
+}
@@ -0,0 +1,173 @@
+package nghttp
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CodeClass groups codes by who is at fault
+type CodeClass string
+
+const (
+	// ClassSuccess is class of successful operations
+	ClassSuccess CodeClass = "success"
+
+	// ClassClient is class of invalid, unauthorized or unfulfillable requests
+	ClassClient CodeClass = "client"
+
+	// ClassCanceled is class of requests terminated by the client
+	ClassCanceled CodeClass = "canceled"
+
+	// ClassRateLimit is class of valid requests rejected until the client slows down
+	ClassRateLimit CodeClass = "rate_limit"
+
+	// ClassServer is class of valid requests the server failed to process
+	ClassServer CodeClass = "server"
+)
+
+// CodeSpec describes a code, see RegisterCode
+type CodeSpec struct {
+	// Code is set by RegisterCode
+	Code Code `json:"code"`
+
+	// Status is default http status code
+	Status int `json:"status"`
+
+	// Class groups the code, e.g. ClassClient
+	Class CodeClass `json:"class"`
+
+	// Retryable reports whether the request may succeed if retried
+	Retryable bool `json:"retryable"`
+
+	// DefaultMessage is message of responses created with New
+	DefaultMessage string `json:"defaultMessage"`
+
+	// Description documents when the code is returned
+	Description string `json:"description,omitempty"`
+}
+
+var codes = struct {
+	sync.RWMutex
+	specs map[Code]CodeSpec
+}{specs: map[Code]CodeSpec{}}
+
+/*
+RegisterCode adds code to the registry or replaces its spec, e.g. to change a built-in default message.
+
+Status defaults to 500, Class is derived from Status when empty and DefaultMessage defaults to the code in lower case.
+
+	const CodePaymentRequired nghttp.Code = "PAYMENT_REQUIRED"
+
+	func init() {
+		nghttp.RegisterCode(CodePaymentRequired, nghttp.CodeSpec{
+			Status:         http.StatusPaymentRequired,
+			DefaultMessage: "payment required",
+			Description:    "The subscription of the account has expired.",
+		})
+	}
+
+	return nghttp.New(CodePaymentRequired)
+*/
+func RegisterCode(code Code, spec CodeSpec) {
+	if code == "" {
+		panic("nghttp: RegisterCode requires a code")
+	}
+
+	spec.Code = code
+	if spec.Status == 0 {
+		spec.Status = http.StatusInternalServerError
+	}
+	if spec.Class == "" {
+		spec.Class = classOf(spec.Status)
+	}
+	if spec.DefaultMessage == "" {
+		spec.DefaultMessage = strings.ToLower(strings.ReplaceAll(string(code), "_", " "))
+	}
+
+	codes.Lock()
+	defer codes.Unlock()
+	codes.specs[code] = spec
+}
+
+// LookupCode return spec of registered code
+func LookupCode(code Code) (CodeSpec, bool) {
+	codes.RLock()
+	defer codes.RUnlock()
+	spec, ok := codes.specs[code]
+	return spec, ok
+}
+
+// Codes return catalog of registered codes sorted by code, e.g. to document every error of an API
+func Codes() []CodeSpec {
+	codes.RLock()
+	defer codes.RUnlock()
+
+	specs := make([]CodeSpec, 0, len(codes.specs))
+	for _, spec := range codes.specs {
+		specs = append(specs, spec)
+	}
+	slices.SortFunc(specs, func(a, b CodeSpec) int { return strings.Compare(string(a.Code), string(b.Code)) })
+	return specs
+}
+
+// New create response with status and default message of registered code,
+// unknown codes are 500 Internal Server Error
+/*
+	return nghttp.New(CodeQuotaExceeded, nghttp.Meta("limit", 100))
+*/
+func New(code Code, opts ...Option) *Response {
+	spec, ok := LookupCode(code)
+	if !ok {
+		return NewError(code, http.StatusInternalServerError, string(code)).Update(opts...)
+	}
+	return NewError(code, spec.Status, spec.DefaultMessage).Update(opts...)
+}
+
+// Spec return registered spec of code, see LookupCode
+func (c Code) Spec() (CodeSpec, bool) { return LookupCode(c) }
+
+func classOf(status int) CodeClass {
+	switch {
+	case status < http.StatusBadRequest:
+		return ClassSuccess
+	case status == http.StatusTooManyRequests:
+		return ClassRateLimit
+	case status == 499:
+		return ClassCanceled
+	case status < http.StatusInternalServerError:
+		return ClassClient
+	default:
+		return ClassServer
+	}
+}
+
+func init() {
+	for _, spec := range []CodeSpec{
+		{CodeOk, http.StatusOK, ClassSuccess, false, "ok", "The operation succeeded."},
+		{CodeInvalidArgument, http.StatusBadRequest, ClassClient, false, "invalid argument", "A request argument is invalid, e.g. failed validation."},
+		{CodeBadRequest, http.StatusBadRequest, ClassClient, false, "bad request", "The request is malformed or cannot be processed."},
+		{CodeNotFound, http.StatusNotFound, ClassClient, false, "not found", "The requested resource does not exist."},
+		{CodeAlreadyExists, http.StatusConflict, ClassClient, false, "already exists", "The resource to create already exists."},
+		{CodePermissionDenied, http.StatusForbidden, ClassClient, false, "permission denied", "The caller is not allowed to perform the operation."},
+		{CodeUnauthenticated, http.StatusUnauthorized, ClassClient, false, "unauthenticated", "Authentication is required or has failed."},
+		{CodeFailedPrecondition, http.StatusPreconditionFailed, ClassClient, false, "failed precondition", "A condition required for the operation is not met."},
+		{CodeOutOfRange, http.StatusBadRequest, ClassClient, false, "out of range", "The operation was attempted past the valid range."},
+		{CodeAborted, http.StatusConflict, ClassClient, false, "aborted", "The operation was aborted, typically due to a concurrency conflict."},
+		{CodeNotAcceptable, http.StatusNotAcceptable, ClassClient, false, "not acceptable", "No response media type matches the Accept header."},
+		{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, ClassClient, false, "unsupported media type", "The request Content-Type cannot be decoded."},
+		{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, ClassClient, false, "payload too large", "The request body exceeds a size limit."},
+		{CodeCanceled, 499, ClassCanceled, false, "canceled", "The client canceled the request."},
+		{CodeResourceExhausted, http.StatusTooManyRequests, ClassRateLimit, true, "resource exhausted", "A quota or resource limit was exhausted."},
+		{CodeTooManyRequests, http.StatusTooManyRequests, ClassRateLimit, true, "too many requests", "The rate limit was exceeded."},
+		{CodeUnknown, http.StatusInternalServerError, ClassServer, false, "unknown", "An unexpected error occurred."},
+		{CodeDeadlineExceeded, http.StatusGatewayTimeout, ClassServer, true, "deadline exceeded", "The operation did not complete before its deadline."},
+		{CodeUnimplemented, http.StatusNotImplemented, ClassServer, false, "unimplemented", "The operation is not implemented."},
+		{CodeInternal, http.StatusInternalServerError, ClassServer, true, "internal error", "The server failed to process a valid request."},
+		{CodeUnavailable, http.StatusServiceUnavailable, ClassServer, true, "unavailable", "The service is temporarily unavailable."},
+		{CodeDataLoss, http.StatusInternalServerError, ClassServer, false, "data loss", "Unrecoverable data loss or corruption."},
+	} {
+		RegisterCode(spec.Code, spec)
+	}
+}
@@ -0,0 +1,222 @@
+package nghttp
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Detail is a typed error detail modeled on google.rpc error details,
+// it is serialized with its type URL in "@type"
+type Detail interface {
+	// TypeURL return type of detail, e.g. "type.googleapis.com/google.rpc.BadRequest"
+	TypeURL() string
+}
+
+var (
+	_ Detail = (*BadRequest)(nil)
+	_ Detail = (*RetryInfo)(nil)
+	_ Detail = (*ErrorInfo)(nil)
+	_ Detail = (*QuotaFailure)(nil)
+	_ Detail = (*LocalizedMessage)(nil)
+	_ Detail = (*RawDetail)(nil)
+)
+
+type (
+	// BadRequest describes violations in a client request
+	BadRequest struct {
+		FieldViolations []FieldViolation `json:"fieldViolations"`
+	}
+
+	// FieldViolation describes a single bad request field
+	FieldViolation struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+		Reason      string `json:"reason,omitempty"`
+	}
+
+	// RetryInfo describes when the client can retry a failed request
+	RetryInfo struct {
+		RetryDelay time.Duration `json:"-"`
+	}
+
+	// ErrorInfo describes the cause of the error with structured details
+	ErrorInfo struct {
+		Reason   string            `json:"reason"`
+		Domain   string            `json:"domain"`
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}
+
+	// QuotaFailure describes how a quota check failed
+	QuotaFailure struct {
+		Violations []QuotaViolation `json:"violations"`
+	}
+
+	// QuotaViolation describes a single quota violation
+	QuotaViolation struct {
+		Subject     string `json:"subject"`
+		Description string `json:"description"`
+	}
+
+	// LocalizedMessage provides a localized error message
+	LocalizedMessage struct {
+		Locale  string `json:"locale"`
+		Message string `json:"message"`
+	}
+
+	// RawDetail is a detail of unregistered type read from a response
+	RawDetail struct {
+		Type  string
+		Value json.RawMessage
+	}
+)
+
+const typeURLPrefix = "type.googleapis.com/google.rpc."
+
+func (*BadRequest) TypeURL() string       { return typeURLPrefix + "BadRequest" }
+func (*RetryInfo) TypeURL() string        { return typeURLPrefix + "RetryInfo" }
+func (*ErrorInfo) TypeURL() string        { return typeURLPrefix + "ErrorInfo" }
+func (*QuotaFailure) TypeURL() string     { return typeURLPrefix + "QuotaFailure" }
+func (*LocalizedMessage) TypeURL() string { return typeURLPrefix + "LocalizedMessage" }
+func (d *RawDetail) TypeURL() string      { return d.Type }
+
+// MarshalJSON writes retry delay as protobuf duration, e.g. {"retryDelay":"1.5s"}
+func (d *RetryInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"retryDelay": formatDuration(d.RetryDelay)})
+}
+
+// UnmarshalJSON reads protobuf duration
+func (d *RetryInfo) UnmarshalJSON(data []byte) error {
+	var v struct {
+		RetryDelay string `json:"retryDelay"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	delay, err := time.ParseDuration(v.RetryDelay)
+	d.RetryDelay = delay
+	return err
+}
+
+// MarshalJSON writes raw value, it already contains "@type"
+func (d *RawDetail) MarshalJSON() ([]byte, error) { return d.Value, nil }
+
+// formatDuration formats fractional seconds as protobuf JSON does, e.g. "1.5s"
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+var detailTypes sync.Map
+
+/*
+RegisterDetail registers custom detail type so it can be read back from responses,
+built-in google.rpc details are registered
+
+	nghttp.RegisterDetail(func() nghttp.Detail { return &PaymentInfo{} })
+*/
+func RegisterDetail(newDetail func() Detail) {
+	detailTypes.Store(newDetail().TypeURL(), newDetail)
+}
+
+func init() {
+	RegisterDetail(func() Detail { return &BadRequest{} })
+	RegisterDetail(func() Detail { return &RetryInfo{} })
+	RegisterDetail(func() Detail { return &ErrorInfo{} })
+	RegisterDetail(func() Detail { return &QuotaFailure{} })
+	RegisterDetail(func() Detail { return &LocalizedMessage{} })
+}
+
+// Details is list of typed error details, serialized as objects with "@type" member
+type Details []Detail
+
+// MarshalJSON writes each detail with its "@type"
+func (ds Details) MarshalJSON() ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(ds))
+	for _, d := range ds {
+		if raw, ok := d.(*RawDetail); ok {
+			items = append(items, raw.Value)
+			continue
+		}
+
+		b, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return nil, err
+		}
+		fields["@type"], _ = json.Marshal(d.TypeURL())
+
+		if b, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+		items = append(items, b)
+	}
+	return json.Marshal(items)
+}
+
+// UnmarshalJSON reads details by "@type", unregistered types are read as *RawDetail
+func (ds *Details) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	*ds = make(Details, 0, len(items))
+	for _, item := range items {
+		var typed struct {
+			Type string `json:"@type"`
+		}
+		if err := json.Unmarshal(item, &typed); err != nil {
+			return err
+		}
+
+		newDetail, ok := detailTypes.Load(typed.Type)
+		if !ok {
+			*ds = append(*ds, &RawDetail{Type: typed.Type, Value: item})
+			continue
+		}
+
+		d := newDetail.(func() Detail)()
+		if err := json.Unmarshal(item, d); err != nil {
+			return err
+		}
+		*ds = append(*ds, d)
+	}
+	return nil
+}
+
+/*
+WithDetails returns an Option that appends typed error details
+
+	nghttp.NewErrInvalidArgument().Update(nghttp.WithDetails(&nghttp.BadRequest{
+		FieldViolations: []nghttp.FieldViolation{{Field: "email", Description: "invalid email"}},
+	}))
+*/
+func WithDetails(details ...Detail) Option {
+	return func(r *Response) {
+		r.Details = append(r.Details, details...)
+	}
+}
+
+/*
+DetailOf return first detail of type T, e.g. when reading a response back
+
+	if info, ok := nghttp.DetailOf[*nghttp.RetryInfo](resp); ok {
+		time.Sleep(info.RetryDelay)
+	}
+*/
+func DetailOf[T Detail](r *Response) (T, bool) {
+	for _, d := range r.Details {
+		if t, ok := d.(T); ok {
+			return t, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,189 @@
+package nghttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+var (
+	_ json.Marshaler = (*Response)(nil)
+
+	_ Envelope = DefaultEnvelope
+	_ Envelope = JSendEnvelope
+	_ Envelope = GoogleEnvelope
+	_ Envelope = DataEnvelope
+)
+
+// Envelope controls how success and error Response values are serialized
+/*
+	// custom envelope
+	nghttp.EnvelopeFunc(func(resp *nghttp.Response) any {
+		if resp.IsError() {
+			return map[string]any{"ok": false, "error": resp.Error()}
+		}
+		return map[string]any{"ok": true, "result": resp.Data}
+	})
+
+Wrap should return struct values, their fields are used to document response schemas.
+*/
+type Envelope interface {
+	// Wrap return value encoded instead of resp
+	Wrap(resp *Response) any
+}
+
+// EnvelopeFunc adapts function to Envelope
+type EnvelopeFunc func(resp *Response) any
+
+// Wrap calls f(resp)
+func (f EnvelopeFunc) Wrap(resp *Response) any { return f(resp) }
+
+var (
+	// DefaultEnvelope writes {"code", "message", "meta", "data"}
+	DefaultEnvelope Envelope = defaultEnvelope{}
+
+	// JSendEnvelope writes JSend {"status": "success"|"fail"|"error", "data", "message", "code"},
+	// client errors are "fail" and server errors are "error"
+	JSendEnvelope Envelope = jsendEnvelope{}
+
+	// GoogleEnvelope writes Google JSON style {"data"} or {"error": {"code", "status", "message", "details", "meta"}}
+	GoogleEnvelope Envelope = googleEnvelope{}
+
+	// DataEnvelope writes bare data of success responses, errors use DefaultEnvelope
+	DataEnvelope Envelope = dataEnvelope{}
+)
+
+type (
+	// StandardBody is body written by DefaultEnvelope
+	StandardBody struct {
+		Code    Code           `json:"code"`
+		Message *string        `json:"message,omitempty"`
+		Meta    map[string]any `json:"meta,omitempty"`
+		Details Details        `json:"details,omitempty"`
+		Data    any            `json:"data,omitempty"`
+	}
+
+	// JSendBody is body written by JSendEnvelope
+	JSendBody struct {
+		Status  string `json:"status" xml:"status"`
+		Data    any    `json:"data" xml:"data,omitempty"`
+		Message string `json:"message,omitempty" xml:"message,omitempty"`
+		Code    Code   `json:"code,omitempty" xml:"code,omitempty"`
+	}
+
+	// GoogleBody is body written by GoogleEnvelope
+	GoogleBody struct {
+		Data  any          `json:"data,omitempty" xml:"data,omitempty"`
+		Error *GoogleError `json:"error,omitempty" xml:"error,omitempty"`
+	}
+
+	// GoogleError is error member of GoogleBody
+	GoogleError struct {
+		Code    int            `json:"code" xml:"code"`
+		Status  Code           `json:"status" xml:"status"`
+		Message string         `json:"message" xml:"message"`
+		Details Details        `json:"details,omitempty" xml:"-"`
+		Meta    map[string]any `json:"meta,omitempty" xml:"-"`
+	}
+)
+
+// JSend statuses
+const (
+	JSendSuccess = "success"
+	JSendFail    = "fail"
+	JSendError   = "error"
+)
+
+type defaultEnvelope struct{}
+
+func (defaultEnvelope) Wrap(r *Response) any {
+	return StandardBody{Code: r.Code, Message: r.Message, Meta: r.Meta, Details: r.Details, Data: r.Data}
+}
+
+type jsendEnvelope struct{}
+
+func (jsendEnvelope) Wrap(r *Response) any {
+	switch {
+	case !r.IsError():
+		return JSendBody{Status: JSendSuccess, Data: r.Data}
+	case r.statusCode < http.StatusInternalServerError:
+		return JSendBody{Status: JSendFail, Data: r.Meta, Message: r.Error(), Code: r.Code}
+	default:
+		return JSendBody{Status: JSendError, Data: r.Meta, Message: r.Error(), Code: r.Code}
+	}
+}
+
+type googleEnvelope struct{}
+
+func (googleEnvelope) Wrap(r *Response) any {
+	if !r.IsError() {
+		return GoogleBody{Data: r.Data}
+	}
+	return GoogleBody{Error: &GoogleError{Code: r.statusCode, Status: r.Code, Message: r.Error(), Details: r.Details, Meta: r.Meta}}
+}
+
+type dataEnvelope struct{}
+
+func (dataEnvelope) Wrap(r *Response) any {
+	if r.IsError() {
+		return DefaultEnvelope.Wrap(r)
+	}
+	return r.Data
+}
+
+// WithEnvelope returns an Option that sets envelope used to serialize the Response
+func WithEnvelope(envelope Envelope) Option {
+	return func(r *Response) {
+		r.envelope = envelope
+	}
+}
+
+// Envelope return envelope of response, nil means DefaultEnvelope
+func (r *Response) Envelope() Envelope { return r.envelope }
+
+// IsError reports whether response has an error status code
+func (r *Response) IsError() bool { return r.statusCode >= http.StatusBadRequest }
+
+// Body return value serialized for the response by its envelope
+func (r *Response) Body() any {
+	if r.envelope == nil {
+		return DefaultEnvelope.Wrap(r)
+	}
+	return r.envelope.Wrap(r)
+}
+
+// MarshalJSON encodes response with its envelope
+func (r *Response) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Body())
+}
+
+// UnmarshalJSON decodes response written with DefaultEnvelope, details are read back by "@type"
+func (r *Response) UnmarshalJSON(data []byte) error {
+	var body StandardBody
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+
+	r.Code, r.Message, r.Meta, r.Details, r.Data = body.Code, body.Message, body.Meta, body.Details, body.Data
+	return nil
+}
+
+/*
+ParseResponse decodes body written with DefaultEnvelope, e.g. by an API client.
+An error is returned when body is not a response.
+
+	resp, err := nghttp.ParseResponse(res.StatusCode, body)
+	if err == nil && resp.IsError() {
+		return resp
+	}
+*/
+func ParseResponse(statusCode int, body []byte) (*Response, error) {
+	resp := &Response{statusCode: statusCode}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+	if resp.Code == "" {
+		return nil, errors.New("nghttp: body is not a response")
+	}
+	return resp, nil
+}
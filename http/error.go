@@ -1,7 +1,5 @@
 package nghttp
 
-import "net/http"
-
 // Code represents a standardized set of error codes for HTTP responses.
 type Code string
 
@@ -39,6 +37,15 @@ const (
 	// CodeAborted represents an aborted operation
 	CodeAborted Code = "ABORTED"
 
+	// CodeNotAcceptable represents a response media type the client does not accept
+	CodeNotAcceptable Code = "NOT_ACCEPTABLE"
+
+	// CodeUnsupportedMediaType represents a request media type the server does not support
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+
+	// CodePayloadTooLarge represents a request body exceeding the server limit
+	CodePayloadTooLarge Code = "PAYLOAD_TOO_LARGE"
+
 	// Client-Initiated Termination
 	// (Usually mapped to 499 Client Closed Request)
 
@@ -78,138 +85,124 @@ const (
 
 // IsClientError reports whether the code represents a client-side error.
 func (c Code) IsClientError() bool {
-	switch c {
-	case CodeInvalidArgument,
-		CodeBadRequest,
-		CodeNotFound,
-		CodeAlreadyExists,
-		CodePermissionDenied,
-		CodeUnauthenticated,
-		CodeFailedPrecondition,
-		CodeOutOfRange,
-		CodeAborted:
-		return true
-	default:
-		return false
-	}
+	spec, ok := LookupCode(c)
+	return ok && spec.Class == ClassClient
 }
 
 // IsServerError reports whether the code represents a server-side error.
 func (c Code) IsServerError() bool {
-	switch c {
-	case CodeUnknown,
-		CodeDeadlineExceeded,
-		CodeUnimplemented,
-		CodeInternal,
-		CodeUnavailable,
-		CodeDataLoss:
-		return true
-	default:
-		return false
-	}
+	spec, ok := LookupCode(c)
+	return ok && spec.Class == ClassServer
 }
 
 // IsRetryable reports whether the request may succeed if retried.
 func (c Code) IsRetryable() bool {
-	switch c {
-	case CodeUnavailable,
-		CodeDeadlineExceeded,
-		CodeResourceExhausted,
-		CodeTooManyRequests,
-		CodeInternal:
-		return true
-	default:
-		return false
-	}
+	spec, ok := LookupCode(c)
+	return ok && spec.Retryable
 }
 
 // NewErrInvalidArgument exists when an argument is invalid
 func NewErrInvalidArgument() *Response {
-	return NewError(CodeInvalidArgument, http.StatusBadRequest, "invalid argument")
+	return New(CodeInvalidArgument)
 }
 
 // NewErrBadRequest exists when the request is malformed or cannot be processed
 func NewErrBadRequest() *Response {
-	return NewError(CodeBadRequest, http.StatusBadRequest, "bad request")
+	return New(CodeBadRequest)
 }
 
 // NewErrNotFound exists when a requested resource is not found
 func NewErrNotFound() *Response {
-	return NewError(CodeNotFound, http.StatusNotFound, "not found")
+	return New(CodeNotFound)
 }
 
 // NewErrAlreadyExists exists when attempting to create a resource that already exists
 func NewErrAlreadyExists() *Response {
-	return NewError(CodeAlreadyExists, http.StatusConflict, "already exists")
+	return New(CodeAlreadyExists)
 }
 
 // NewErrPermissionDenied denied when the caller does not have permission to execute the specified operation
 func NewErrPermissionDenied() *Response {
-	return NewError(CodePermissionDenied, http.StatusForbidden, "permission denied")
+	return New(CodePermissionDenied)
 }
 
 // NewErrUnauthenticated when authentication is required and has failed or has not yet been provided
 func NewErrUnauthenticated() *Response {
-	return NewError(CodeUnauthenticated, http.StatusUnauthorized, "unauthenticated")
+	return New(CodeUnauthenticated)
 }
 
 // NewErrFailedPrecondition when a condition for the operation is not met
 func NewErrFailedPrecondition() *Response {
-	return NewError(CodeFailedPrecondition, http.StatusPreconditionFailed, "failed precondition")
+	return New(CodeFailedPrecondition)
 }
 
 // NewErrOutOfRange when an operation is attempted past the valid range
 func NewErrOutOfRange() *Response {
-	return NewError(CodeOutOfRange, http.StatusBadRequest, "out of range")
+	return New(CodeOutOfRange)
 }
 
 // NewErrAborted operation was aborted, typically due to a concurrency issue
 func NewErrAborted() *Response {
-	return NewError(CodeAborted, http.StatusConflict, "aborted")
+	return New(CodeAborted)
+}
+
+// NewErrNotAcceptable when no response media type matches the Accept header
+func NewErrNotAcceptable() *Response {
+	return New(CodeNotAcceptable)
+}
+
+// NewErrUnsupportedMediaType when the request Content-Type cannot be decoded
+func NewErrUnsupportedMediaType() *Response {
+	return New(CodeUnsupportedMediaType)
+}
+
+// NewErrPayloadTooLarge when the request body exceeds a size limit
+func NewErrPayloadTooLarge() *Response {
+	return New(CodePayloadTooLarge)
 }
 
 // NewErrResourceExhausted represents a resource exhaustion error, such as rate limit exceeded
 func NewErrResourceExhausted() *Response {
-	return NewError(CodeResourceExhausted, http.StatusTooManyRequests, "resource exhausted")
+	return New(CodeResourceExhausted)
 }
 
 // NewErrTooManyRequests when rate limit is exceeded
 func NewErrTooManyRequests() *Response {
-	return NewError(CodeTooManyRequests, http.StatusTooManyRequests, "too many requests")
+	return New(CodeTooManyRequests)
 }
 
 // NewErrDeadlineExceeded when a deadline has been exceeded
 func NewErrDeadlineExceeded() *Response {
-	return NewError(CodeDeadlineExceeded, http.StatusGatewayTimeout, "deadline exceeded")
+	return New(CodeDeadlineExceeded)
 }
 
 // NewErrUnavailable when the service is currently unavailable
 func NewErrUnavailable() *Response {
-	return NewError(CodeUnavailable, http.StatusServiceUnavailable, "unavailable")
+	return New(CodeUnavailable)
 }
 
 // NewErrInternal represents a server error
 func NewErrInternal() *Response {
-	return NewError(CodeInternal, http.StatusInternalServerError, "internal error")
+	return New(CodeInternal)
 }
 
 // NewErrUnimplemented represents an unimplemented method
 func NewErrUnimplemented() *Response {
-	return NewError(CodeUnimplemented, http.StatusNotImplemented, "unimplemented")
+	return New(CodeUnimplemented)
 }
 
 // NewErrDataLoss represents an unrecoverable data loss or corruption error
 func NewErrDataLoss() *Response {
-	return NewError(CodeDataLoss, http.StatusInternalServerError, "data loss")
+	return New(CodeDataLoss)
 }
 
 // NewErrCancel represents a canceled operation
 func NewErrCancel() *Response {
 	// Client Closed Request (non-standard but widely used)
-	return NewError(CodeCanceled, 499, "canceled")
+	return New(CodeCanceled)
 }
 
 // NewErrUnknown represents an unknown error
 func NewErrUnknown() *Response {
-	return NewError(CodeUnknown, http.StatusInternalServerError, "unknown")
+	return New(CodeUnknown)
 }
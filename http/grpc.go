@@ -0,0 +1,156 @@
+package nghttp
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// GRPCCode is a gRPC status code, see google.golang.org/grpc/codes
+type GRPCCode uint32
+
+// gRPC status codes
+const (
+	GRPCOk                 GRPCCode = 0
+	GRPCCanceled           GRPCCode = 1
+	GRPCUnknown            GRPCCode = 2
+	GRPCInvalidArgument    GRPCCode = 3
+	GRPCDeadlineExceeded   GRPCCode = 4
+	GRPCNotFound           GRPCCode = 5
+	GRPCAlreadyExists      GRPCCode = 6
+	GRPCPermissionDenied   GRPCCode = 7
+	GRPCResourceExhausted  GRPCCode = 8
+	GRPCFailedPrecondition GRPCCode = 9
+	GRPCAborted            GRPCCode = 10
+	GRPCOutOfRange         GRPCCode = 11
+	GRPCUnimplemented      GRPCCode = 12
+	GRPCInternal           GRPCCode = 13
+	GRPCUnavailable        GRPCCode = 14
+	GRPCDataLoss           GRPCCode = 15
+	GRPCUnauthenticated    GRPCCode = 16
+)
+
+// canonical codes in gRPC code order
+var grpcCodes = [...]Code{
+	CodeOk,
+	CodeCanceled,
+	CodeUnknown,
+	CodeInvalidArgument,
+	CodeDeadlineExceeded,
+	CodeNotFound,
+	CodeAlreadyExists,
+	CodePermissionDenied,
+	CodeResourceExhausted,
+	CodeFailedPrecondition,
+	CodeAborted,
+	CodeOutOfRange,
+	CodeUnimplemented,
+	CodeInternal,
+	CodeUnavailable,
+	CodeDataLoss,
+	CodeUnauthenticated,
+}
+
+// codes without gRPC equivalent
+var grpcAliases = map[Code]GRPCCode{
+	CodeBadRequest:           GRPCInvalidArgument,
+	CodeNotAcceptable:        GRPCInvalidArgument,
+	CodeUnsupportedMediaType: GRPCInvalidArgument,
+	CodePayloadTooLarge:      GRPCResourceExhausted,
+	CodeTooManyRequests:      GRPCResourceExhausted,
+}
+
+// String return gRPC code name, e.g. "NOT_FOUND"
+func (c GRPCCode) String() string {
+	if c == GRPCCanceled {
+		return "CANCELLED"
+	}
+	if int(c) < len(grpcCodes) {
+		return string(grpcCodes[c])
+	}
+	return "Code(" + strconv.FormatUint(uint64(c), 10) + ")"
+}
+
+/*
+GRPCCode converts code to gRPC status code.
+
+Codes without gRPC equivalent, e.g. registered ones, are mapped from their default status
+the way grpc-gateway maps statuses, unknown codes are GRPCUnknown.
+
+	status.Error(codes.Code(resp.Code.GRPCCode()), resp.Error())
+*/
+func (c Code) GRPCCode() GRPCCode {
+	for i, code := range grpcCodes {
+		if code == c {
+			return GRPCCode(i)
+		}
+	}
+
+	if g, ok := grpcAliases[c]; ok {
+		return g
+	}
+
+	if spec, ok := LookupCode(c); ok {
+		return grpcCodeOfStatus(spec.Status)
+	}
+	return GRPCUnknown
+}
+
+// CodeFromGRPC converts gRPC status code to code, unknown values are CodeUnknown
+func CodeFromGRPC(code GRPCCode) Code {
+	if int(code) < len(grpcCodes) {
+		return grpcCodes[code]
+	}
+	return CodeUnknown
+}
+
+/*
+FromGRPC create response from gRPC status code and message, e.g. to forward errors of a gRPC backend
+
+	st := status.Convert(err)
+	return nghttp.FromGRPC(nghttp.GRPCCode(st.Code()), st.Message())
+*/
+func FromGRPC(code GRPCCode, message string, opts ...Option) *Response {
+	resp := New(CodeFromGRPC(code))
+	if message != "" {
+		resp.Update(WithMessage(message))
+	}
+	return resp.Update(opts...)
+}
+
+func grpcCodeOfStatus(status int) GRPCCode {
+	switch status {
+	case http.StatusBadRequest:
+		return GRPCInvalidArgument
+	case http.StatusUnauthorized:
+		return GRPCUnauthenticated
+	case http.StatusForbidden:
+		return GRPCPermissionDenied
+	case http.StatusNotFound:
+		return GRPCNotFound
+	case http.StatusConflict:
+		return GRPCAborted
+	case http.StatusPreconditionFailed:
+		return GRPCFailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return GRPCOutOfRange
+	case http.StatusTooManyRequests:
+		return GRPCResourceExhausted
+	case 499:
+		return GRPCCanceled
+	case http.StatusNotImplemented:
+		return GRPCUnimplemented
+	case http.StatusServiceUnavailable:
+		return GRPCUnavailable
+	case http.StatusGatewayTimeout:
+		return GRPCDeadlineExceeded
+	}
+
+	switch {
+	case status < http.StatusBadRequest:
+		return GRPCOk
+	case status < http.StatusInternalServerError:
+		return GRPCFailedPrecondition
+	default:
+		return GRPCInternal
+	}
+}
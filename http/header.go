@@ -0,0 +1,106 @@
+package nghttp
+
+import "net/http"
+
+var (
+	_ HeaderCarrier = (*Response)(nil)
+	_ HeaderCarrier = (*RawResponse)(nil)
+	_ HeaderCarrier = (*PanicError)(nil)
+)
+
+// responseHeader holds headers, cookies and trailers written along with a response
+type responseHeader struct {
+	header  http.Header
+	cookies []*http.Cookie
+	trailer http.Header
+}
+
+// Headers return response headers, the returned header can be mutated
+func (h *responseHeader) Headers() http.Header {
+	if h.header == nil {
+		h.header = http.Header{}
+	}
+	return h.header
+}
+
+// Cookies return cookies to be set with response
+func (h *responseHeader) Cookies() []*http.Cookie { return h.cookies }
+
+// Trailers return response trailers, the returned header can be mutated
+func (h *responseHeader) Trailers() http.Header {
+	if h.trailer == nil {
+		h.trailer = http.Header{}
+	}
+	return h.trailer
+}
+
+// AddCookie adds cookie to be set with response
+func (h *responseHeader) AddCookie(cookie *http.Cookie) {
+	h.cookies = append(h.cookies, cookie)
+}
+
+// clone return deep copy so copies of a response do not share headers
+func (h responseHeader) clone() responseHeader {
+	return responseHeader{
+		header:  h.header.Clone(),
+		cookies: append([]*http.Cookie(nil), h.cookies...),
+		trailer: h.trailer.Clone(),
+	}
+}
+
+// WithHeader returns an Option that adds a response header, e.g. Location, Cache-Control, Retry-After
+func WithHeader(key, value string) Option {
+	return func(r *Response) {
+		r.Headers().Add(key, value)
+	}
+}
+
+// WithCookie returns an Option that sets a cookie with the response
+func WithCookie(cookie *http.Cookie) Option {
+	return func(r *Response) {
+		r.AddCookie(cookie)
+	}
+}
+
+// WithTrailer returns an Option that adds a response trailer, written after the body
+func WithTrailer(key, value string) Option {
+	return func(r *Response) {
+		r.Trailers().Add(key, value)
+	}
+}
+
+// WriteHeaders sets headers and cookies of resp on w and declares its trailers.
+// It must be called before w.WriteHeader.
+func WriteHeaders(w http.ResponseWriter, resp HTTPResponse) {
+	carrier, ok := resp.(HeaderCarrier)
+	if !ok {
+		return
+	}
+
+	header := w.Header()
+	for key, values := range carrier.Headers() {
+		header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	for _, cookie := range carrier.Cookies() {
+		http.SetCookie(w, cookie)
+	}
+
+	for key := range carrier.Trailers() {
+		header.Add("Trailer", key)
+	}
+}
+
+// WriteTrailers sets trailer values of resp on w.
+// It must be called after the body is written.
+func WriteTrailers(w http.ResponseWriter, resp HTTPResponse) {
+	carrier, ok := resp.(HeaderCarrier)
+	if !ok {
+		return
+	}
+
+	header := w.Header()
+	for key, values := range carrier.Trailers() {
+		header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+}
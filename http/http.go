@@ -1,5 +1,11 @@
 package nghttp
 
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
 type (
 
 	// HTTPResponse represents an HTTP response with status code and response body
@@ -10,4 +16,33 @@ type (
 		// Response return response body
 		Response() any
 	}
+
+	// HeaderCarrier is implemented by responses carrying headers, cookies and trailers,
+	// response handlers apply them when writing the response
+	HeaderCarrier interface {
+		// Headers return response headers
+		Headers() http.Header
+
+		// Cookies return cookies to be set
+		Cookies() []*http.Cookie
+
+		// Trailers return response trailers
+		Trailers() http.Header
+	}
+
+	// Streamer is implemented by responses written incrementally by the response handler,
+	// e.g. server-sent events, see WriteStream
+	Streamer interface {
+		HTTPResponse
+		HeaderCarrier
+
+		// Prepare is called with request header before response headers are written,
+		// it return the streamer of this request, e.g. a copy holding the negotiated format,
+		// so the response value is not changed
+		Prepare(req http.Header) Streamer
+
+		// Stream writes body to w, flush pushes written data to the client.
+		// ctx is canceled when the client disconnects.
+		Stream(ctx context.Context, w io.Writer, flush func() error) error
+	}
 )
@@ -1,5 +1,7 @@
 package nghttp
 
+import "net/http"
+
 var (
 	_ interface {
 		HTTPResponse
@@ -19,6 +21,20 @@ func (e *PanicError) Response() any { return e.resp }
 // StatusCode return http status code
 func (e *PanicError) StatusCode() int { return e.resp.StatusCode() }
 
+// Headers return headers of underlying response
+func (e *PanicError) Headers() http.Header { return e.resp.Headers() }
+
+// Cookies return cookies of underlying response
+func (e *PanicError) Cookies() []*http.Cookie { return e.resp.Cookies() }
+
+// Trailers return trailers of underlying response
+func (e *PanicError) Trailers() http.Header { return e.resp.Trailers() }
+
+// With return copy of e whose response is copied and updated with opts, e may be shared
+func (e *PanicError) With(opts ...Option) *PanicError {
+	return &PanicError{v: e.v, resp: e.resp.With(opts...)}
+}
+
 // Value return panic value
 func (e *PanicError) Value() any { return e.v }
 
@@ -28,5 +44,8 @@ func (e *PanicError) Error() string { return *e.resp.Message }
 // NewPanicError create new PanicError with given value
 func NewPanicError(value any) *PanicError {
 	resp := NewErrUnknown()
+	if err, ok := value.(error); ok {
+		resp.Update(WithCause(err))
+	}
 	return &PanicError{v: value, resp: resp}
 }
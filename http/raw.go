@@ -4,6 +4,7 @@ var _ interface{ HTTPResponse } = (*RawResponse)(nil)
 
 // RawResponse represents a raw HTTP response with status code and byte slice value
 type RawResponse struct {
+	responseHeader
 	s int
 	v []byte
 }
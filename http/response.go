@@ -1,6 +1,10 @@
 package nghttp
 
-import "fmt"
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
 
 var _ interface {
 	error
@@ -34,11 +38,20 @@ type (
 		// http status
 		statusCode int
 
+		// headers, cookies and trailers written with response
+		responseHeader
+
 		// internal metadata, no expose for external
 		//
 		// purpose is to carry data from one layer to another
 		metadata map[string]any `json:"-"`
 
+		// serializes response, nil means DefaultEnvelope
+		envelope Envelope
+
+		// response this one was copied from, see Is
+		root *Response
+
 		// public info will expose to client as json
 		Code Code `json:"code"`
 
@@ -48,6 +61,9 @@ type (
 		// description
 		Meta map[string]any `json:"meta,omitempty"`
 
+		// typed error details, see WithDetails
+		Details Details `json:"details,omitempty"`
+
 		// wanted info
 		Data any `json:"data,omitempty"`
 	}
@@ -69,6 +85,11 @@ func (r *Response) Response() any { return r }
 // With will return a copy of response with given options applied
 func (r *Response) With(opts ...Option) *Response {
 	copy := *r
+	copy.root = r.origin()
+	copy.responseHeader = r.responseHeader.clone()
+	copy.metadata = maps.Clone(r.metadata)
+	copy.Meta = maps.Clone(r.Meta)
+	copy.Details = slices.Clip(r.Details)
 	return copy.Update(opts...)
 }
 
@@ -142,6 +163,32 @@ func WithMessage(message string) Option {
 	}
 }
 
+// MessageID return message id and template args set with WithMessageID
+func (r *Response) MessageID() (id string, args map[string]any) {
+	val, _ := r.GetMetadata(MetadataMessageID)
+	id, _ = val.(string)
+	val, _ = r.GetMetadata(MetadataMessageArgs)
+	args, _ = val.(map[string]any)
+	return id, args
+}
+
+// WithMessageID returns an Option that sets message id translated by message catalogs, keyvalues are template args.
+// Message is kept as is when no catalog has the id.
+/*
+	nghttp.NewErrResourceExhausted().Update(nghttp.WithMessageID("quota.exceeded", "count", 3))
+*/
+func WithMessageID(id string, keyvalues ...any) Option {
+	if len(keyvalues)%2 != 0 {
+		panic("message args should be key-value pairs")
+	}
+
+	args := make(map[string]any, len(keyvalues)/2)
+	for i := 0; i < len(keyvalues); i += 2 {
+		args[fmt.Sprint(keyvalues[i])] = keyvalues[i+1]
+	}
+	return Metadata(MetadataMessageID, id, MetadataMessageArgs, args)
+}
+
 // WithStatusCode returns an Option that sets the HTTP status code for the Response.
 // The statusCode parameter specifies the HTTP status code to be used (e.g., 200, 404, 500).
 // This option is typically used when constructing a new Response to customize its status code.
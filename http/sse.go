@@ -0,0 +1,219 @@
+package nghttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Streamer = (*SSE)(nil)
+
+// DefaultSSEHeartbeat is interval of comment lines keeping idle connections open
+var DefaultSSEHeartbeat = 15 * time.Second
+
+type (
+	// SSEEvent represents a server-sent event
+	SSEEvent struct {
+		// ID is sent back by the browser as Last-Event-ID when reconnecting
+		ID string
+
+		// Event is the event type, empty means "message"
+		Event string
+
+		// Data is written as is for string and []byte, JSON encoded otherwise
+		Data any
+
+		// Retry tells the browser how long to wait before reconnecting
+		Retry time.Duration
+	}
+
+	// SSEHandler produces events until it returns or ctx is canceled
+	SSEHandler func(ctx context.Context, emitter *SSEEmitter) error
+
+	// SSEOption is used to customize SSE
+	SSEOption func(s *SSE)
+
+	// SSE represents a server-sent events response, events are produced by handler while streaming
+	SSE struct {
+		responseHeader
+		handler     SSEHandler
+		heartbeat   time.Duration
+		lastEventID string
+	}
+
+	// SSEEmitter writes events to the client, it is safe for concurrent use
+	SSEEmitter struct {
+		mu          sync.Mutex
+		w           io.Writer
+		flush       func() error
+		cancel      context.CancelFunc
+		lastEventID string
+	}
+)
+
+/*
+NewSSE create new server-sent events response.
+
+The handler runs inside the response handler, after guards, middlewares and interceptors.
+Its ctx is canceled when the client disconnects, or a write fails.
+
+	return ng.Respond(ctx, nghttp.NewSSE(func(ctx context.Context, e *nghttp.SSEEmitter) error {
+		for progress := range job.Progress(ctx, e.LastEventID()) {
+			if err := e.Send(nghttp.SSEEvent{ID: progress.ID, Event: "progress", Data: progress}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+*/
+func NewSSE(handler SSEHandler, opts ...SSEOption) *SSE {
+	s := &SSE{handler: handler, heartbeat: DefaultSSEHeartbeat}
+	s.Headers().Set("Content-Type", "text/event-stream")
+	s.Headers().Set("Cache-Control", "no-cache")
+	s.Headers().Set("Connection", "keep-alive")
+	s.Headers().Set("X-Accel-Buffering", "no")
+
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// WithHeartbeat sets interval of heartbeat comments, zero disables heartbeat
+func WithHeartbeat(interval time.Duration) SSEOption {
+	return func(s *SSE) {
+		s.heartbeat = interval
+	}
+}
+
+// StatusCode return 200 OK
+func (s *SSE) StatusCode() int { return http.StatusOK }
+
+// Response return nil, events are streamed
+func (s *SSE) Response() any { return nil }
+
+// Prepare return copy of s for one request, holding Last-Event-ID of request header
+func (s *SSE) Prepare(req http.Header) Streamer {
+	p := *s
+	p.lastEventID = req.Get("Last-Event-ID")
+	return &p
+}
+
+// Stream runs handler and heartbeat until handler returns or ctx is canceled
+func (s *SSE) Stream(ctx context.Context, w io.Writer, flush func() error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e := &SSEEmitter{w: w, flush: flush, cancel: cancel, lastEventID: s.lastEventID}
+
+	var wg sync.WaitGroup
+	if s.heartbeat > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(s.heartbeat)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if e.Comment("heartbeat") != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	err := s.handler(ctx, e)
+	cancel()
+	wg.Wait()
+
+	// client went away, nothing left to report
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// LastEventID return Last-Event-ID sent by a reconnecting client, empty on first connection
+func (e *SSEEmitter) LastEventID() string { return e.lastEventID }
+
+// Send writes event and flushes it to the client
+func (e *SSEEmitter) Send(event SSEEvent) error {
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", sanitizeSSE(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", sanitizeSSE(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %s\n", strconv.FormatInt(event.Retry.Milliseconds(), 10))
+	}
+
+	data, err := sseData(event.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	b.WriteString("\n")
+
+	return e.write(b.String())
+}
+
+// Data sends unnamed event with given data
+func (e *SSEEmitter) Data(data any) error {
+	return e.Send(SSEEvent{Data: data})
+}
+
+// Comment writes comment line, ignored by browsers
+func (e *SSEEmitter) Comment(text string) error {
+	return e.write(": " + sanitizeSSE(text) + "\n\n")
+}
+
+func (e *SSEEmitter) write(s string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := io.WriteString(e.w, s); err != nil {
+		e.cancel()
+		return err
+	}
+
+	if err := e.flush(); err != nil {
+		e.cancel()
+		return err
+	}
+	return nil
+}
+
+func sseData(data any) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// sanitizeSSE removes line breaks from single line fields
+func sanitizeSSE(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
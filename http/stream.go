@@ -0,0 +1,420 @@
+package nghttp
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+)
+
+var _ Streamer = (*StreamResponse)(nil)
+
+// StreamFormat is body format of StreamResponse
+type StreamFormat int
+
+const (
+	// StreamAuto selects NDJSON when Accept asks for application/x-ndjson, JSON array otherwise
+	StreamAuto StreamFormat = iota
+
+	// StreamJSONArray writes items as array inside the envelope, e.g. {"data":[item, ...],"code":"OK"}
+	StreamJSONArray
+
+	// StreamNDJSON writes one JSON item per line
+	StreamNDJSON
+)
+
+// Trailers set when iteration fails after the body has started
+const (
+	TrailerErrorCode    = "Ng-Error-Code"
+	TrailerErrorMessage = "Ng-Error-Message"
+)
+
+type (
+	// StreamOption is used to customize StreamResponse
+	StreamOption func(s *StreamResponse)
+
+	// StreamResponse writes items of a sequence incrementally, see Stream
+	StreamResponse struct {
+		responseHeader
+		seq        func(ctx context.Context) iter.Seq2[any, error]
+		format     StreamFormat
+		flushEvery int
+		envelope   Envelope
+	}
+
+	// streamed is an item received from the producer goroutine
+	streamed struct {
+		item any
+		err  error
+	}
+)
+
+// streamMarker locates the items member in an envelope body
+var streamMarker = json.RawMessage(`"\u0000ng-stream-items"`)
+
+/*
+Stream create response writing items of seq as they are produced,
+as NDJSON or as JSON array inside the response envelope.
+
+The array member is written first and the other envelope members last, so they describe
+how the stream ended, e.g. {"data":[...],"code":"OK"}. An error yielded by seq ends the body
+with the error envelope instead, {"data":[...],"code":"UNAVAILABLE","message":"..."},
+a terminal {"error": {...}} item for NDJSON and bare arrays of DataEnvelope,
+and is also reported by Ng-Error-Code and Ng-Error-Message trailers.
+Iteration stops when the client disconnects, even while seq is blocked producing an item.
+seq then only stops at its next yield, use StreamContext for a producer that blocks.
+
+	return ng.Respond(ctx, nghttp.Stream(func(yield func(User, error) bool) {
+		rows, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+		if err != nil {
+			yield(User{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var u User
+			err := rows.Scan(&u.ID, &u.Name)
+			if !yield(u, err) || err != nil {
+				return
+			}
+		}
+	}))
+*/
+func Stream[T any](seq iter.Seq2[T, error], opts ...StreamOption) *StreamResponse {
+	return StreamContext(func(ctx context.Context) iter.Seq2[T, error] { return seq }, opts...)
+}
+
+/*
+StreamContext is like Stream, fn receives a ctx canceled when the client disconnects or the stream ends,
+so a producer blocked e.g. on a channel is released.
+
+	return ng.Respond(ctx, nghttp.StreamContext(func(ctx context.Context) iter.Seq2[Event, error] {
+		return func(yield func(Event, error) bool) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-events:
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		}
+	}))
+*/
+func StreamContext[T any](fn func(ctx context.Context) iter.Seq2[T, error], opts ...StreamOption) *StreamResponse {
+	s := &StreamResponse{
+		seq: func(ctx context.Context) iter.Seq2[any, error] {
+			return func(yield func(any, error) bool) {
+				for item, err := range fn(ctx) {
+					if !yield(item, err) {
+						return
+					}
+				}
+			}
+		},
+		flushEvery: 1,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// WithStreamFormat forces body format instead of selecting it by Accept header
+func WithStreamFormat(format StreamFormat) StreamOption {
+	return func(s *StreamResponse) {
+		s.format = format
+	}
+}
+
+// WithFlushEvery flushes after every n items instead of every item
+func WithFlushEvery(n int) StreamOption {
+	return func(s *StreamResponse) {
+		s.flushEvery = max(n, 1)
+	}
+}
+
+// WithStreamEnvelope sets envelope of JSON array and error items, DefaultEnvelope when not set
+func WithStreamEnvelope(envelope Envelope) StreamOption {
+	return func(s *StreamResponse) {
+		s.envelope = envelope
+	}
+}
+
+// Envelope return envelope set by WithStreamEnvelope or WithEnvelope, nil when not set
+func (s *StreamResponse) Envelope() Envelope { return s.envelope }
+
+// WithEnvelope return copy of s using envelope, headers are copied, the sequence is shared
+func (s *StreamResponse) WithEnvelope(envelope Envelope) *StreamResponse {
+	copy := *s
+	copy.responseHeader = s.responseHeader.clone()
+	copy.envelope = envelope
+	return &copy
+}
+
+// StatusCode return 200 OK
+func (s *StreamResponse) StatusCode() int { return http.StatusOK }
+
+// Response return nil, items are streamed
+func (s *StreamResponse) Response() any { return nil }
+
+// Format return body format, StreamAuto until selected by Prepare
+func (s *StreamResponse) Format() StreamFormat { return s.format }
+
+// Prepare return copy of s for one request, with format selected by Accept header and error trailers declared.
+// s is not changed, so a response value can be shared by requests.
+func (s *StreamResponse) Prepare(req http.Header) Streamer {
+	p := *s
+	p.responseHeader = s.responseHeader.clone()
+
+	if p.format == StreamAuto {
+		p.format = StreamJSONArray
+		if strings.Contains(req.Get("Accept"), "application/x-ndjson") {
+			p.format = StreamNDJSON
+		}
+	}
+
+	contentType := "application/json"
+	if p.format == StreamNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	p.Headers().Set("Content-Type", contentType)
+
+	// declared before headers are written, values are set only on error
+	p.Trailers()[TrailerErrorCode] = nil
+	p.Trailers()[TrailerErrorMessage] = nil
+	return &p
+}
+
+// Stream writes items until seq ends, yields an error or ctx is canceled
+func (s *StreamResponse) Stream(ctx context.Context, w io.Writer, flush func() error) error {
+	envelope := cmp.Or[Envelope](s.envelope, DefaultEnvelope)
+	ndjson := s.format == StreamNDJSON
+
+	var key string
+	bare := ndjson
+	if !ndjson {
+		key, bare = streamKey(envelope)
+
+		start := "["
+		if !bare {
+			k, _ := json.Marshal(key)
+			start = "{" + string(k) + ":["
+		}
+		if _, err := io.WriteString(w, start); err != nil {
+			return err
+		}
+	}
+
+	// canceled once writing ends, so the producer stops too
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	items := s.produce(ctx)
+
+	var (
+		n         int
+		streamErr error
+	)
+
+loop:
+	for {
+		var next streamed
+		select {
+		case <-ctx.Done():
+			// client went away, nothing left to report
+			return nil
+		case item, ok := <-items:
+			if !ok {
+				break loop
+			}
+			next = item
+		}
+
+		if next.err != nil {
+			streamErr = next.err
+			break
+		}
+
+		b, err := json.Marshal(next.item)
+		if err != nil {
+			streamErr = err
+			break
+		}
+
+		if !ndjson && n > 0 {
+			b = append([]byte{','}, b...)
+		}
+		if ndjson {
+			b = append(b, '\n')
+		}
+
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+
+		n++
+		if n%s.flushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	var end string
+	switch {
+	case ndjson:
+		end = s.endNDJSON(envelope, streamErr)
+	case bare:
+		end = s.endArray(envelope, n, streamErr)
+	default:
+		end = s.endEnvelope(envelope, key, streamErr)
+	}
+
+	if _, err := io.WriteString(w, end); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// produce runs seq in its own goroutine, so a blocked producer does not delay cancellation
+func (s *StreamResponse) produce(ctx context.Context) <-chan streamed {
+	items := make(chan streamed)
+	go func() {
+		defer close(items)
+		defer func() {
+			if p := recover(); p != nil {
+				select {
+				case items <- streamed{err: fmt.Errorf("nghttp: stream panic: %v", p)}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+
+		for item, err := range s.seq(ctx) {
+			select {
+			case items <- streamed{item: item, err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return items
+}
+
+// endNDJSON return terminal error item, if any
+func (s *StreamResponse) endNDJSON(envelope Envelope, err error) string {
+	if err == nil {
+		return ""
+	}
+	return `{"error":` + string(s.errorBody(envelope, err)) + "}\n"
+}
+
+// endArray closes bare array, an error is appended as terminal item
+func (s *StreamResponse) endArray(envelope Envelope, n int, err error) string {
+	if err == nil {
+		return "]"
+	}
+
+	end := `{"error":` + string(s.errorBody(envelope, err)) + "}]"
+	if n > 0 {
+		end = "," + end
+	}
+	return end
+}
+
+// endEnvelope closes the array and writes remaining envelope members of final response
+func (s *StreamResponse) endEnvelope(envelope Envelope, key string, err error) string {
+	var body []byte
+	if err == nil {
+		body, _ = json.Marshal(envelope.Wrap(NewResponse(nil)))
+	} else {
+		body = s.errorBody(envelope, err)
+	}
+
+	var b strings.Builder
+	b.WriteString("]")
+	for k, v := range objectMembers(body) {
+		if k == key {
+			continue
+		}
+		name, _ := json.Marshal(k)
+		b.WriteString("," + string(name) + ":" + string(v))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// errorBody sets error trailers and return encoded error envelope
+func (s *StreamResponse) errorBody(envelope Envelope, err error) []byte {
+	resp := streamError(err)
+	s.Trailers().Set(TrailerErrorCode, string(resp.Code))
+	s.Trailers().Set(TrailerErrorMessage, resp.Error())
+
+	b, _ := json.Marshal(envelope.Wrap(resp))
+	return b
+}
+
+// streamKey return member of envelope body holding items, bare is true when body is the array itself.
+// Envelopes nesting data deeper fall back to DefaultEnvelope layout.
+func streamKey(envelope Envelope) (key string, bare bool) {
+	body, err := json.Marshal(envelope.Wrap(NewResponse(streamMarker)))
+	if err != nil {
+		return "data", false
+	}
+
+	if bytes.Equal(body, streamMarker) {
+		return "", true
+	}
+
+	for k, v := range objectMembers(body) {
+		if bytes.Equal(v, streamMarker) {
+			return k, false
+		}
+	}
+	return "data", false
+}
+
+// objectMembers iterates members of a JSON object in order
+func objectMembers(data []byte) iter.Seq2[string, json.RawMessage] {
+	return func(yield func(string, json.RawMessage) bool) {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+			return
+		}
+
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return
+			}
+
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				return
+			}
+
+			if !yield(t.(string), v) {
+				return
+			}
+		}
+	}
+}
+
+// streamError return err as *Response, unknown errors are hidden behind INTERNAL
+func streamError(err error) *Response {
+	var resp *Response
+	if errors.As(err, &resp) {
+		return resp
+	}
+	return NewErrInternal().Update(WithCause(err))
+}
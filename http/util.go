@@ -37,3 +37,8 @@ func EmptyResponse() *Response {
 		statusCode: http.StatusOK,
 	}
 }
+
+// Created create 201 Created response with given data and Location header
+func Created(data any, location string, opts ...Option) *Response {
+	return NewResponse(data, WithStatusCode(http.StatusCreated), WithHeader("Location", location)).Update(opts...)
+}
@@ -0,0 +1,26 @@
+package nghttp
+
+var _ interface{ HTTPResponse } = (*WrittenResponse)(nil)
+
+// WrittenResponse represents a response that has already been written to the client
+// by the handler itself, e.g. a mounted http.Handler.
+//
+// ng does not invoke the ResponseHandler for it, so the body is never written twice.
+type WrittenResponse struct {
+	s    int
+	size int64
+}
+
+// StatusCode return http status code that was written
+func (w *WrittenResponse) StatusCode() int { return w.s }
+
+// Response return nil, body is already written
+func (w *WrittenResponse) Response() any { return nil }
+
+// Size return number of body bytes written
+func (w *WrittenResponse) Size() int64 { return w.size }
+
+// NewWrittenResponse create new WrittenResponse with given status code and written size
+func NewWrittenResponse(statusCode int, size int64) *WrittenResponse {
+	return &WrittenResponse{s: statusCode, size: size}
+}
@@ -0,0 +1,71 @@
+package nghttp
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+var _ xml.Marshaler = (*Response)(nil)
+
+// MarshalXML encodes response as <response> element, meta keys become child elements of <meta>
+/*
+	<response>
+	  <code>NOT_FOUND</code>
+	  <message>not found</message>
+	  <meta><id>1</id></meta>
+	</response>
+*/
+//
+// Responses with other envelopes encode the envelope body as <response> element.
+func (r *Response) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if r.envelope != nil && r.envelope != DefaultEnvelope {
+		return e.EncodeElement(r.envelope.Wrap(r), xml.StartElement{Name: xml.Name{Local: "response"}})
+	}
+
+	start.Name = xml.Name{Local: "response"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := e.EncodeElement(r.Code, xml.StartElement{Name: xml.Name{Local: "code"}}); err != nil {
+		return err
+	}
+
+	if r.Message != nil {
+		if err := e.EncodeElement(*r.Message, xml.StartElement{Name: xml.Name{Local: "message"}}); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Meta) > 0 {
+		meta := xml.StartElement{Name: xml.Name{Local: "meta"}}
+		if err := e.EncodeToken(meta); err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(r.Meta))
+		for k := range r.Meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if err := e.EncodeElement(fmt.Sprint(r.Meta[k]), xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+				return err
+			}
+		}
+
+		if err := e.EncodeToken(meta.End()); err != nil {
+			return err
+		}
+	}
+
+	if r.Data != nil {
+		if err := e.EncodeElement(r.Data, xml.StartElement{Name: xml.Name{Local: "data"}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
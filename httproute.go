@@ -0,0 +1,161 @@
+package ng
+
+// Mount standard net/http handlers as ng routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type stripPrefixKey struct{}
+
+// NewHTTPRoute create new route that serves a standard http.Handler.
+//
+// The handler writes directly to the adapter's http.ResponseWriter, so guards,
+// middlewares and interceptors still apply while the ResponseHandler is skipped.
+// The adapter must store http.ResponseWriter and *http.Request in the context.
+/*
+	app.AddRoute(
+		ng.NewHTTPRoute(http.MethodGet, "/debug/pprof/{path...}", http.HandlerFunc(pprof.Index),
+			ng.WithGuards(adminGuard),
+		),
+	)
+*/
+func NewHTTPRoute(method string, path string, handler http.Handler, opts ...Option) Route {
+	return NewRoute(method, path, WithHTTPHandler(handler), opts...)
+}
+
+// WithHTTPHandler adds a standard http.Handler as the route handler
+func WithHTTPHandler(handler http.Handler) HandlerOption {
+	return WithHandler(func(ctx context.Context) error {
+		w, err := Load[http.ResponseWriter](ctx)
+		if err != nil {
+			return errors.New("http.ResponseWriter not found in context, adapter must store it")
+		}
+
+		r, err := Load[*http.Request](ctx)
+		if err != nil {
+			return errors.New("*http.Request not found in context, adapter must store it")
+		}
+
+		r = r.WithContext(ctx)
+		if prefix, ok := stripPrefix(ctx); ok {
+			r = stripRequestPrefix(r, prefix)
+		}
+
+		rw := &responseWriter{ResponseWriter: w}
+		handler.ServeHTTP(rw, r)
+
+		return Respond(ctx, nghttp.NewWrittenResponse(rw.statusCode(), rw.size))
+	})
+}
+
+// WithStripPrefix strips prefix from the request path before calling a mounted http.Handler.
+//
+// Without argument the static part of the route path is stripped,
+// e.g. "/api/debug/pprof/{path...}" strips "/api/debug/pprof".
+func WithStripPrefix(prefix ...string) Option {
+	value := ""
+	if len(prefix) > 0 {
+		value = normolizePath(prefix[0])
+	}
+	return WithMetadata(stripPrefixKey{}, value)
+}
+
+// stripPrefix resolve prefix to strip for current route
+func stripPrefix(ctx context.Context) (string, bool) {
+	route := GetContext(ctx).Route()
+	val, ok := route.Core().Metadata(stripPrefixKey{})
+	if !ok {
+		return "", false
+	}
+
+	if prefix := val.(string); prefix != "" {
+		return prefix, true
+	}
+
+	return staticPath(route.Path()), true
+}
+
+// staticPath return path segments before the first wildcard or param segment
+func staticPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.ContainsAny(seg, "{*:") {
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return path
+}
+
+func stripRequestPrefix(r *http.Request, prefix string) *http.Request {
+	p := strings.TrimPrefix(r.URL.Path, prefix)
+	rp := strings.TrimPrefix(r.URL.RawPath, prefix)
+	if len(p) == len(r.URL.Path) && (r.URL.RawPath == "" || len(rp) == len(r.URL.RawPath)) {
+		return r
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = p
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = rp
+	}
+	return r2
+}
+
+// responseWriter records status and size written by a mounted http.Handler
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
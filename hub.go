@@ -0,0 +1,321 @@
+package ng
+
+// Topic based publish/subscribe hub for streaming subscribers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultHubBuffer is number of messages buffered per subscriber before backpressure applies
+var DefaultHubBuffer = 64
+
+// ErrSlowSubscriber is reported by a subscription disconnected by BackpressureDisconnect
+var ErrSlowSubscriber = errors.New("ng: subscriber is too slow, disconnected")
+
+// ErrSubscriptionClosed is returned when publishing to or reading from a closed subscription
+var ErrSubscriptionClosed = errors.New("ng: subscription closed")
+
+// ErrTopicPattern is returned when subscribing to a topic containing pattern characters
+var ErrTopicPattern = errors.New("ng: topic patterns cannot be subscribed")
+
+// Backpressure decides what happens when a subscriber buffer is full
+type Backpressure int
+
+const (
+	// BackpressureDropOldest drops the oldest buffered message to make room for the new one
+	BackpressureDropOldest Backpressure = iota
+
+	// BackpressureDisconnect closes the subscription with ErrSlowSubscriber
+	BackpressureDisconnect
+)
+
+type (
+	// HubMessage is a message published to a topic
+	HubMessage struct {
+		Topic string
+
+		// ID is sent as SSE event id, optional
+		ID string
+
+		// Event is sent as SSE event type, defaults to Topic
+		Event string
+
+		Data any
+	}
+
+	// HubBackend delivers published messages to subscribers of a topic.
+	//
+	// The in-memory backend serves a single node, implementations backed by
+	// a message broker (Redis, NATS, ...) fan out across nodes.
+	// deliver never blocks, backpressure is applied by the Hub.
+	HubBackend interface {
+		Publish(ctx context.Context, msg HubMessage) error
+		Subscribe(ctx context.Context, topic string, deliver func(HubMessage)) (unsubscribe func(), err error)
+	}
+
+	// HubOption is used to customize Hub
+	HubOption func(h *Hub)
+
+	// Hub fans out published messages to subscribers of a topic
+	Hub struct {
+		backend      HubBackend
+		buffer       int
+		backpressure Backpressure
+	}
+
+	// Subscription receives messages of subscribed topics until closed
+	Subscription struct {
+		mu           sync.Mutex
+		ch           chan HubMessage
+		done         chan struct{}
+		err          error
+		dropped      int
+		backpressure Backpressure
+		unsubscribes []func()
+		topics       []string
+	}
+)
+
+/*
+NewHub create new hub, in-memory backend is used unless WithHubBackend is given.
+
+	hub := ng.NewHub(ng.WithBackpressure(ng.BackpressureDisconnect))
+
+	// orders service
+	hub.Publish("order.updated", order)
+
+	// dashboards
+	app.AddRoute(ng.NewSubscriptionRoute("/events", hub,
+		ng.WithTopics("order.updated", "order.*"),
+		ng.WithGuards(authGuard),
+	))
+*/
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{buffer: DefaultHubBuffer, backpressure: BackpressureDropOldest}
+	for _, o := range opts {
+		o(h)
+	}
+
+	if h.backend == nil {
+		h.backend = NewMemoryHubBackend()
+	}
+	return h
+}
+
+// WithHubBackend sets backend delivering messages, e.g. a broker for multi-node setups
+func WithHubBackend(backend HubBackend) HubOption {
+	return func(h *Hub) {
+		h.backend = backend
+	}
+}
+
+// WithHubBuffer sets number of messages buffered per subscriber
+func WithHubBuffer(size int) HubOption {
+	return func(h *Hub) {
+		h.buffer = max(size, 1)
+	}
+}
+
+// WithBackpressure sets policy applied when a subscriber buffer is full
+func WithBackpressure(policy Backpressure) HubOption {
+	return func(h *Hub) {
+		h.backpressure = policy
+	}
+}
+
+// Publish sends msg to all subscribers of topic, msg can be HubMessage to set ID or Event
+func (h *Hub) Publish(topic string, msg any) error {
+	return h.PublishContext(context.Background(), topic, msg)
+}
+
+// PublishContext is Publish with context passed to backend
+func (h *Hub) PublishContext(ctx context.Context, topic string, msg any) error {
+	m, ok := msg.(HubMessage)
+	if !ok {
+		m = HubMessage{Data: msg}
+	}
+
+	m.Topic = topic
+	if m.Event == "" {
+		m.Event = topic
+	}
+	return h.backend.Publish(ctx, m)
+}
+
+// Subscribe create subscription for given topics, it is closed when ctx is done or Close is called.
+// Topics are literal, those containing "*", "?" or "[" fail with ErrTopicPattern
+func (h *Hub) Subscribe(ctx context.Context, topics ...string) (*Subscription, error) {
+	for _, topic := range topics {
+		if isTopicPattern(topic) {
+			return nil, fmt.Errorf("%w: %s", ErrTopicPattern, topic)
+		}
+	}
+
+	s := &Subscription{
+		ch:           make(chan HubMessage, h.buffer),
+		done:         make(chan struct{}),
+		backpressure: h.backpressure,
+		topics:       topics,
+	}
+
+	for _, topic := range topics {
+		unsubscribe, err := h.backend.Subscribe(ctx, topic, s.deliver)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.unsubscribes = append(s.unsubscribes, unsubscribe)
+	}
+
+	context.AfterFunc(ctx, s.Close)
+	return s, nil
+}
+
+// Topics return subscribed topics
+func (s *Subscription) Topics() []string { return s.topics }
+
+// Messages return channel of received messages
+func (s *Subscription) Messages() <-chan HubMessage { return s.ch }
+
+// Done is closed when subscription is closed
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Err return ErrSlowSubscriber when disconnected by backpressure, ErrSubscriptionClosed after Close
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Dropped return number of messages dropped by BackpressureDropOldest
+func (s *Subscription) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Next waits for next message, returns error when subscription or ctx is done.
+// Messages buffered before a BackpressureDisconnect are returned before ErrSlowSubscriber
+func (s *Subscription) Next(ctx context.Context) (HubMessage, error) {
+	select {
+	case msg := <-s.ch:
+		return msg, nil
+	case <-s.done:
+		if errors.Is(s.Err(), ErrSlowSubscriber) {
+			select {
+			case msg := <-s.ch:
+				return msg, nil
+			default:
+			}
+		}
+		return HubMessage{}, s.Err()
+	case <-ctx.Done():
+		return HubMessage{}, ctx.Err()
+	}
+}
+
+// Close unsubscribes from all topics, it is safe to call more than once
+func (s *Subscription) Close() {
+	s.mu.Lock()
+	unsubscribes := s.unsubscribes
+	s.unsubscribes = nil
+	s.closeLocked(ErrSubscriptionClosed)
+	s.mu.Unlock()
+
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+}
+
+func (s *Subscription) closeLocked(err error) {
+	if s.err != nil {
+		return
+	}
+	s.err = err
+	close(s.done)
+}
+
+// deliver buffers msg without blocking the publisher, applying backpressure when full
+func (s *Subscription) deliver(msg HubMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+
+	for {
+		select {
+		case s.ch <- msg:
+			return
+		default:
+		}
+
+		if s.backpressure == BackpressureDisconnect {
+			// unsubscribing here could deadlock the backend, Close does it
+			s.closeLocked(ErrSlowSubscriber)
+			return
+		}
+
+		select {
+		case <-s.ch:
+			s.dropped++
+		default:
+		}
+	}
+}
+
+var _ HubBackend = (*MemoryHubBackend)(nil)
+
+// MemoryHubBackend delivers messages within a single process
+type MemoryHubBackend struct {
+	mu     sync.RWMutex
+	next   uint64
+	topics map[string]map[uint64]func(HubMessage)
+}
+
+// NewMemoryHubBackend create new in-memory hub backend
+func NewMemoryHubBackend() *MemoryHubBackend {
+	return &MemoryHubBackend{topics: map[string]map[uint64]func(HubMessage){}}
+}
+
+// Publish delivers msg to subscribers of msg.Topic
+func (b *MemoryHubBackend) Publish(ctx context.Context, msg HubMessage) error {
+	b.mu.RLock()
+	subscribers := make([]func(HubMessage), 0, len(b.topics[msg.Topic]))
+	for _, deliver := range b.topics[msg.Topic] {
+		subscribers = append(subscribers, deliver)
+	}
+	b.mu.RUnlock()
+
+	for _, deliver := range subscribers {
+		deliver(msg)
+	}
+	return nil
+}
+
+// Subscribe registers deliver for topic
+func (b *MemoryHubBackend) Subscribe(ctx context.Context, topic string, deliver func(HubMessage)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.next++
+	id := b.next
+	if b.topics[topic] == nil {
+		b.topics[topic] = map[uint64]func(HubMessage){}
+	}
+	b.topics[topic][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.topics[topic], id)
+		if len(b.topics[topic]) == 0 {
+			delete(b.topics, topic)
+		}
+	}, nil
+}
@@ -0,0 +1,163 @@
+package ng
+
+// Subscription routes streaming hub messages as server-sent events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type topicsKey struct{}
+
+// subscriptionTopics caches topics resolved for current request
+type subscriptionTopics []string
+
+// NewSubscriptionRoute create GET route streaming messages of hub topics as server-sent events.
+//
+// Topics allowed on the route are set with WithTopics, clients pick them with
+// ?topic=order.updated&topic=order.42 and get all non-pattern topics otherwise.
+// Guards run before subscribing and can authorize each topic with TopicGuard
+// or SubscriptionTopics.
+/*
+	app.AddRoute(ng.NewSubscriptionRoute("/orders/events", hub,
+		ng.WithTopics("order.updated", "order.*"),
+		ng.WithGuards(ng.TopicGuard(func(ctx context.Context, topic string) error {
+			if !canWatch(ctx, topic) {
+				return nghttp.NewErrPermissionDenied()
+			}
+			return nil
+		})),
+	))
+*/
+func NewSubscriptionRoute(path string, hub *Hub, opts ...Option) Route {
+	return NewRoute(http.MethodGet, path, WithSubscription(hub), opts...)
+}
+
+// WithSubscription adds a handler streaming hub messages of SubscriptionTopics
+func WithSubscription(hub *Hub, opts ...nghttp.SSEOption) HandlerOption {
+	return WithHandler(func(ctx context.Context) error {
+		topics, err := SubscriptionTopics(ctx)
+		if err != nil {
+			return err
+		}
+
+		sub, err := hub.Subscribe(ctx, topics...)
+		if err != nil {
+			return err
+		}
+
+		return Respond(ctx, nghttp.NewSSE(func(ctx context.Context, e *nghttp.SSEEmitter) error {
+			defer sub.Close()
+
+			for {
+				msg, err := sub.Next(ctx)
+				if errors.Is(err, ErrSubscriptionClosed) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+
+				if err := e.Send(nghttp.SSEEvent{ID: msg.ID, Event: msg.Event, Data: msg.Data}); err != nil {
+					return err
+				}
+			}
+		}, opts...))
+	})
+}
+
+// WithTopics sets topics subscribers of the route may request,
+// patterns use path.Match syntax, e.g. "order.*"
+func WithTopics(topics ...string) Option {
+	return WithMetadata(topicsKey{}, topics)
+}
+
+// SubscriptionTopics return topics requested for current subscription route.
+//
+// It fails with INVALID_ARGUMENT when a requested topic is a pattern or is not allowed by WithTopics,
+// or when nothing is requested and the route only has patterns.
+func SubscriptionTopics(ctx context.Context) ([]string, error) {
+	if topics, err := Load[subscriptionTopics](ctx); err == nil {
+		return topics, nil
+	}
+
+	var allowed []string
+	if val, ok := GetContext(ctx).Route().Core().Metadata(topicsKey{}); ok {
+		allowed = val.([]string)
+	}
+
+	var requested []string
+	if r, err := Load[*http.Request](ctx); err == nil {
+		requested = r.URL.Query()["topic"]
+	}
+
+	topics := []string{}
+	if len(requested) == 0 {
+		for _, topic := range allowed {
+			if !isTopicPattern(topic) {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	for _, topic := range requested {
+		if isTopicPattern(topic) {
+			return nil, nghttp.NewErrInvalidArgument().Update(
+				nghttp.WithMessage("topic patterns cannot be subscribed: "+topic),
+				nghttp.Meta("topic", topic),
+			)
+		}
+		if !topicAllowed(allowed, topic) {
+			return nil, nghttp.NewErrInvalidArgument().Update(
+				nghttp.WithMessage("topic not allowed: "+topic),
+				nghttp.Meta("topic", topic),
+			)
+		}
+		if !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+
+	if len(topics) == 0 {
+		return nil, nghttp.NewErrInvalidArgument().Update(nghttp.WithMessage("topic is required"))
+	}
+
+	Store(ctx, subscriptionTopics(topics))
+	return topics, nil
+}
+
+// TopicGuard create guard calling allow for every requested topic, the first error denies the subscription
+func TopicGuard(allow func(ctx context.Context, topic string) error) Guard {
+	return GuardFunc(func(ctx context.Context) error {
+		topics, err := SubscriptionTopics(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, topic := range topics {
+			if err := allow(ctx, topic); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func isTopicPattern(topic string) bool {
+	return strings.ContainsAny(topic, "*?[")
+}
+
+func topicAllowed(allowed []string, topic string) bool {
+	for _, pattern := range allowed {
+		if ok, _ := path.Match(pattern, topic); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package ng
+
+// Localization of response messages with message catalogs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+	ngi18n "github.com/foxie-io/ng/i18n"
+)
+
+type (
+	i18nConfig struct {
+		catalog *ngi18n.Catalog
+		query   string
+		cookie  string
+	}
+
+	// I18nOption configures locale detection of WithI18n
+	I18nOption func(*i18nConfig)
+
+	// resolved locale cached in request context
+	requestLocale string
+)
+
+// LocaleQuery sets query parameter selecting locale, default "lang", empty disables it
+func LocaleQuery(name string) I18nOption {
+	return func(c *i18nConfig) { c.query = name }
+}
+
+// LocaleCookie sets cookie selecting locale, default "lang", empty disables it
+func LocaleCookie(name string) I18nOption {
+	return func(c *i18nConfig) { c.cookie = name }
+}
+
+/*
+WithI18n localizes responses of app, controller or route with catalog.
+
+Locale is detected from the "lang" query parameter, then the "lang" cookie, then Accept-Language.
+Before the ResponseHandler writes, messages of error responses are translated by Code,
+messages set with nghttp.WithMessageID by id, and BadRequest field violations by Reason.
+Meta entries are template args.
+
+	catalog := ngi18n.NewCatalog("en").
+		Add("fr", map[string]string{
+			"NOT_FOUND": "{{.resource}} introuvable",
+			"required":  "le champ {{.field}} est obligatoire",
+		})
+
+	app := ng.NewApp(ng.WithI18n(catalog, ng.LocaleCookie("locale")))
+*/
+func WithI18n(catalog *ngi18n.Catalog, opts ...I18nOption) Option {
+	cfg := &i18nConfig{catalog: catalog, query: "lang", cookie: "lang"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *config) {
+		c.core.i18n = cfg
+	}
+}
+
+// Locale return locale of current request, empty when route has no catalog
+func Locale(ctx context.Context) string {
+	cfg := routeI18n(ctx)
+	if cfg == nil {
+		return ""
+	}
+
+	if locale, err := Load[requestLocale](ctx); err == nil {
+		return string(locale)
+	}
+
+	locale := cfg.detect(ctx)
+	Store(ctx, requestLocale(locale))
+	return locale
+}
+
+/*
+T translates message id to locale of current request, keyvalues are template args.
+id is returned when no catalog has it.
+
+	ng.T(ctx, "cart.items", "count", 3) // "3 articles"
+*/
+func T(ctx context.Context, id string, keyvalues ...any) string {
+	if len(keyvalues)%2 != 0 {
+		panic("T requires key-value pairs")
+	}
+
+	cfg := routeI18n(ctx)
+	if cfg == nil {
+		return id
+	}
+
+	args := make(map[string]any, len(keyvalues)/2)
+	for i := 0; i < len(keyvalues); i += 2 {
+		args[fmt.Sprint(keyvalues[i])] = keyvalues[i+1]
+	}
+
+	if msg, ok := cfg.catalog.Translate(Locale(ctx), id, args); ok {
+		return msg
+	}
+	return id
+}
+
+func routeI18n(ctx context.Context) *i18nConfig {
+	rc := GetContext(ctx)
+	if rc == nil || rc.Route() == nil {
+		return nil
+	}
+	if c, ok := rc.Route().Core().(*core); ok {
+		return c.i18n
+	}
+	return nil
+}
+
+func (cfg *i18nConfig) detect(ctx context.Context) string {
+	r, err := Load[*http.Request](ctx)
+	if err != nil {
+		return cfg.catalog.Fallback()
+	}
+
+	// unsupported query or cookie locales fall through to Accept-Language
+	if cfg.query != "" {
+		if locale, ok := cfg.catalog.Supported(r.URL.Query().Get(cfg.query)); ok {
+			return locale
+		}
+	}
+	if cfg.cookie != "" {
+		if cookie, err := r.Cookie(cfg.cookie); err == nil {
+			if locale, ok := cfg.catalog.Supported(cookie.Value); ok {
+				return locale
+			}
+		}
+	}
+	return cfg.catalog.Match(r.Header.Get("Accept-Language"))
+}
+
+// localize translates message and field violations of error responses and responses with message id
+func localize(ctx context.Context, cfg *i18nConfig, resp nghttp.HTTPResponse) nghttp.HTTPResponse {
+	switch t := resp.(type) {
+	case *nghttp.Response:
+		if localized := localizeResponse(ctx, cfg, t.With()); localized != nil {
+			return localized
+		}
+	case *nghttp.PanicError:
+		localized := t.With()
+		if inner, ok := localized.Response().(*nghttp.Response); ok && localizeResponse(ctx, cfg, inner) != nil {
+			return localized
+		}
+	}
+	return resp
+}
+
+// localizeResponse mutates resp, nil is returned when nothing was translated
+func localizeResponse(ctx context.Context, cfg *i18nConfig, resp *nghttp.Response) *nghttp.Response {
+	id, args := resp.MessageID()
+	if id == "" && !resp.IsError() {
+		return nil
+	}
+
+	locale := Locale(ctx)
+	params := maps.Clone(resp.Meta)
+	if params == nil {
+		params = map[string]any{}
+	}
+	maps.Copy(params, args)
+
+	// locales that supplied messages, for Content-Language
+	var used []string
+	translate := func(id string, args map[string]any) (string, bool) {
+		msg, found, ok := cfg.catalog.TranslateLocale(locale, id, args)
+		if ok && !slices.Contains(used, found) {
+			used = append(used, found)
+		}
+		return msg, ok
+	}
+
+	// messages missing an argument keep the untranslated message
+	for _, key := range []string{id, string(resp.Code)} {
+		if key == "" {
+			continue
+		}
+		if msg, ok := translate(key, params); ok {
+			resp.Update(nghttp.WithMessage(msg))
+			break
+		}
+	}
+
+	resp.Details = slices.Clone(resp.Details)
+	for i, detail := range resp.Details {
+		badRequest, ok := detail.(*nghttp.BadRequest)
+		if !ok {
+			continue
+		}
+
+		copy := &nghttp.BadRequest{FieldViolations: append([]nghttp.FieldViolation(nil), badRequest.FieldViolations...)}
+		for j, v := range copy.FieldViolations {
+			if v.Reason == "" {
+				continue
+			}
+			if msg, ok := translate(v.Reason, map[string]any{"field": v.Field}); ok {
+				copy.FieldViolations[j].Description = msg
+			}
+		}
+		resp.Details[i] = copy
+	}
+
+	if len(used) == 0 {
+		return nil
+	}
+	resp.Headers().Set("Content-Language", strings.Join(used, ", "))
+	return resp
+}
@@ -0,0 +1,260 @@
+// Package ngi18n provides message catalogs with templates, pluralization and locale matching
+/*
+	catalog := ngi18n.NewCatalog("en").
+		Add("en", map[string]string{
+			"NOT_FOUND": "{{.resource}} not found",
+			"welcome":   "Welcome, {{.name}}!",
+		}).
+		AddPlural("en", "cart.items", ngi18n.Plural{One: "{{.count}} item", Other: "{{.count}} items"}).
+		Add("fr", map[string]string{"NOT_FOUND": "{{.resource}} introuvable"})
+
+	msg, _ := catalog.Translate("fr-CA", "NOT_FOUND", map[string]any{"resource": "user"})
+*/
+package ngi18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// CountArg is template argument selecting plural form
+const CountArg = "count"
+
+// Plural holds message forms by CLDR plural category, Other is required
+type Plural struct {
+	Zero  string `json:"zero,omitempty"`
+	One   string `json:"one,omitempty"`
+	Two   string `json:"two,omitempty"`
+	Few   string `json:"few,omitempty"`
+	Many  string `json:"many,omitempty"`
+	Other string `json:"other"`
+}
+
+// message is parsed message of one locale
+type message struct {
+	forms map[Category]*template.Template
+}
+
+// Catalog holds messages by locale and message id, it is safe for concurrent use
+type Catalog struct {
+	fallback string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]*message
+}
+
+// NewCatalog create catalog, messages missing in a locale are looked up in fallback locale
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalize(fallback),
+		messages: map[string]map[string]*message{},
+	}
+}
+
+// Fallback return fallback locale
+func (c *Catalog) Fallback() string { return c.fallback }
+
+// Add adds messages of locale, messages are text/template strings, e.g. "Hello {{.name}}".
+// It panics on invalid templates, like template.Must.
+func (c *Catalog) Add(locale string, messages map[string]string) *Catalog {
+	for id, text := range messages {
+		c.AddPlural(locale, id, Plural{Other: text})
+	}
+	return c
+}
+
+// AddPlural adds message with plural forms, the form is selected by CountArg argument
+func (c *Catalog) AddPlural(locale, id string, p Plural) *Catalog {
+	forms := map[Category]string{Zero: p.Zero, One: p.One, Two: p.Two, Few: p.Few, Many: p.Many, Other: p.Other}
+
+	msg := &message{forms: map[Category]*template.Template{}}
+	for category, text := range forms {
+		if text == "" {
+			continue
+		}
+		msg.forms[category] = template.Must(template.New(id).Option("missingkey=error").Parse(text))
+	}
+
+	locale = normalize(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]*message{}
+	}
+	c.messages[locale][id] = msg
+	return c
+}
+
+/*
+AddJSON adds messages of locale from JSON object, values are strings or plural forms
+
+	{
+		"welcome": "Welcome, {{.name}}!",
+		"cart.items": {"one": "{{.count}} item", "other": "{{.count}} items"}
+	}
+*/
+func (c *Catalog) AddJSON(locale string, data []byte) (err error) {
+	var messages map[string]json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ngi18n: %v", r)
+		}
+	}()
+
+	for id, raw := range messages {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			c.AddPlural(locale, id, Plural{Other: text})
+			continue
+		}
+
+		var p Plural
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("ngi18n: message %q: %w", id, err)
+		}
+		c.AddPlural(locale, id, p)
+	}
+	return nil
+}
+
+// Locales return locales with messages, sorted
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.messages))
+}
+
+// Has reports whether message id exists in locale, its base language or fallback locale
+func (c *Catalog) Has(locale, id string) bool {
+	_, _, ok := c.lookup(locale, id)
+	return ok
+}
+
+/*
+Translate return message id of best locale with args applied, ok is false when message does not exist
+or an argument used by the message is missing.
+
+Locale "pt-BR" falls back to "pt" then to the catalog fallback locale.
+The plural form is selected by args[CountArg] with plural rule of the locale that has the message.
+*/
+func (c *Catalog) Translate(locale, id string, args map[string]any) (string, bool) {
+	msg, _, ok := c.TranslateLocale(locale, id, args)
+	return msg, ok
+}
+
+// TranslateLocale is like Translate, it also return locale that supplied the message,
+// e.g. the fallback locale when locale has no such message
+func (c *Catalog) TranslateLocale(locale, id string, args map[string]any) (msg string, found string, ok bool) {
+	m, found, ok := c.lookup(locale, id)
+	if !ok {
+		return "", "", false
+	}
+
+	tmpl := m.forms[Other]
+	if count, ok := args[CountArg]; ok {
+		if form, ok := m.forms[PluralCategory(found, count)]; ok {
+			tmpl = form
+		}
+	}
+	if tmpl == nil {
+		return "", "", false
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", "", false
+	}
+	return buf.String(), found, true
+}
+
+// lookup return message and locale it was found in
+func (c *Catalog) lookup(locale, id string) (*message, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range []string{normalize(locale), Base(locale), c.fallback} {
+		if msg, ok := c.messages[candidate][id]; ok {
+			return msg, candidate, true
+		}
+	}
+	return nil, "", false
+}
+
+// Supported return supported locale for locale or its base language, e.g. "fr" for "fr-CH"
+func (c *Catalog) Supported(locale string) (string, bool) {
+	supported := c.Locales()
+	for _, candidate := range []string{normalize(locale), Base(locale)} {
+		if slices.Contains(supported, candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+/*
+Match return best supported locale for Accept-Language header values, quality values are honored.
+The fallback locale is returned when nothing matches.
+
+	catalog.Match("fr-CH, fr;q=0.9, en;q=0.8") // "fr"
+*/
+func (c *Catalog) Match(acceptLanguage ...string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	candidates := []candidate{}
+	for _, header := range acceptLanguage {
+		for _, part := range strings.Split(header, ",") {
+			tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if _, err := fmt.Sscanf(v, "%g", &q); err != nil {
+					continue
+				}
+			}
+			if tag = normalize(tag); tag != "" && q > 0 {
+				candidates = append(candidates, candidate{tag, q})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	for _, cand := range candidates {
+		if cand.tag == "*" {
+			return c.fallback
+		}
+		if locale, ok := c.Supported(cand.tag); ok {
+			return locale
+		}
+	}
+	return c.fallback
+}
+
+// Base return base language of locale, e.g. "pt" for "pt-BR"
+func Base(locale string) string {
+	base, _, _ := strings.Cut(normalize(locale), "-")
+	return base
+}
+
+// normalize lowercases locale and uses "-" separator, e.g. "pt_BR" becomes "pt-br"
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
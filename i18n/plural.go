@@ -0,0 +1,163 @@
+package ngi18n
+
+import (
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Category is CLDR plural category
+type Category string
+
+// CLDR plural categories
+const (
+	Zero  Category = "zero"
+	One   Category = "one"
+	Two   Category = "two"
+	Few   Category = "few"
+	Many  Category = "many"
+	Other Category = "other"
+)
+
+// PluralRule return category of n
+type PluralRule func(n float64) Category
+
+var rules sync.Map
+
+// RegisterPluralRule sets plural rule of base language, e.g. "cy"
+func RegisterPluralRule(lang string, rule PluralRule) {
+	rules.Store(Base(lang), rule)
+}
+
+// PluralCategory return category of count for locale, languages without rule use the English one
+func PluralCategory(locale string, count any) Category {
+	n, ok := toFloat(count)
+	if !ok {
+		return Other
+	}
+
+	if rule, ok := rules.Load(Base(locale)); ok {
+		return rule.(PluralRule)(n)
+	}
+	return englishRule(n)
+}
+
+func englishRule(n float64) Category {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+// french: 0 and 1 are singular
+func frenchRule(n float64) Category {
+	if n >= 0 && n < 2 {
+		return One
+	}
+	return Other
+}
+
+// no plural forms
+func otherRule(float64) Category { return Other }
+
+// russian, ukrainian: one (1, 21), few (2-4, 22-24), many
+func eastSlavicRule(n float64) Category {
+	if n != math.Trunc(n) {
+		return Other
+	}
+	i := int64(n)
+	switch {
+	case i%10 == 1 && i%100 != 11:
+		return One
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+// polish: one (1), few (2-4, 22-24), many
+func polishRule(n float64) Category {
+	if n != math.Trunc(n) {
+		return Other
+	}
+	i := int64(n)
+	switch {
+	case i == 1:
+		return One
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+// arabic: zero, one, two, few (3-10), many (11-99)
+func arabicRule(n float64) Category {
+	if n != math.Trunc(n) {
+		return Other
+	}
+	i := int64(n)
+	switch {
+	case i == 0:
+		return Zero
+	case i == 1:
+		return One
+	case i == 2:
+		return Two
+	case i%100 >= 3 && i%100 <= 10:
+		return Few
+	case i%100 >= 11:
+		return Many
+	default:
+		return Other
+	}
+}
+
+func init() {
+	for _, lang := range []string{"fr", "pt"} {
+		RegisterPluralRule(lang, frenchRule)
+	}
+	for _, lang := range []string{"ja", "zh", "ko", "th", "vi", "id", "km"} {
+		RegisterPluralRule(lang, otherRule)
+	}
+	for _, lang := range []string{"ru", "uk", "be"} {
+		RegisterPluralRule(lang, eastSlavicRule)
+	}
+	RegisterPluralRule("pl", polishRule)
+	RegisterPluralRule("ar", arabicRule)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,393 @@
+package ng
+
+// Multipart uploads: stream parts into DTOs with size limits and temp-file spooling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type (
+	uploadLimitsKey struct{}
+	uploadTypesKey  struct{}
+)
+
+// UploadLimits limits multipart uploads, zero fields fall back to DefaultUploadLimits
+type UploadLimits struct {
+	// MaxFileSize is maximum size of a single file
+	MaxFileSize int64
+
+	// MaxTotalSize is maximum size of all parts together
+	MaxTotalSize int64
+
+	// MaxFieldSize is maximum size of a single non-file part, these are read in memory
+	MaxFieldSize int64
+
+	// SpoolThreshold is size above which files are written to temp files instead of memory
+	SpoolThreshold int64
+}
+
+// DefaultUploadLimits is used for routes without WithUploadLimits
+var DefaultUploadLimits = UploadLimits{
+	MaxFileSize:    32 << 20,
+	MaxTotalSize:   64 << 20,
+	MaxFieldSize:   1 << 20,
+	SpoolThreshold: 1 << 20,
+}
+
+// UploadedFile is a file part of a multipart request
+type UploadedFile struct {
+	// Field is form field name
+	Field string
+
+	// Name is file name sent by the client, without directories
+	Name string
+
+	// Size in bytes
+	Size int64
+
+	// ContentType is detected from content, the client provided type is in Header
+	ContentType string
+
+	// Header is MIME header of the part
+	Header textproto.MIMEHeader
+
+	data []byte
+	path string
+}
+
+// Open return reader of file content
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// Spooled reports whether the file was written to a temp file
+func (f *UploadedFile) Spooled() bool { return f.path != "" }
+
+// WithUploadLimits sets multipart limits of app, controller or route
+func WithUploadLimits(limits UploadLimits) Option {
+	return WithMetadata(uploadLimitsKey{}, limits)
+}
+
+// WithAllowedUploadTypes restricts detected content type of uploaded files, e.g. "image/png", "image/*"
+func WithAllowedUploadTypes(types ...string) Option {
+	return WithMetadata(uploadTypesKey{}, types)
+}
+
+/*
+BindMultipart streams multipart/form-data request into dst.
+
+Fields are matched by `form` tag, then field name. File fields are *ng.UploadedFile
+or []*ng.UploadedFile, other fields can be strings, numbers, bools or slices of them.
+Temp files are removed when the context is cleared.
+
+	type AvatarRequest struct {
+		UserID int               `form:"userId"`
+		Avatar *ng.UploadedFile  `form:"avatar"`
+	}
+
+	ng.NewRoute(http.MethodPost, "/avatars",
+		ng.WithScopeHandler(func() ng.Handler {
+			var body AvatarRequest
+			return ng.Handle(ng.BindMultipart(&body), func(ctx context.Context) error { ... })
+		}),
+		ng.WithUploadLimits(ng.UploadLimits{MaxFileSize: 2 << 20}),
+		ng.WithAllowedUploadTypes("image/png", "image/jpeg"),
+	)
+*/
+func BindMultipart(dst any) Handler {
+	return func(ctx context.Context) error {
+		r, err := Load[*http.Request](ctx)
+		if err != nil {
+			return err
+		}
+
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nghttp.NewErrUnsupportedMediaType().Update(nghttp.Meta("contentType", r.Header.Get("Content-Type")))
+		}
+
+		return decodeMultipart(ctx, reader, dst)
+	}
+}
+
+func decodeMultipart(ctx context.Context, reader *multipart.Reader, dst any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return errors.New("BindMultipart requires pointer to struct")
+	}
+	fields := formFields(target.Elem())
+
+	limits := uploadLimits(ctx)
+	allowed := allowedUploadTypes(ctx)
+	remaining := limits.MaxTotalSize
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+		}
+
+		field, ok := fields[part.FormName()]
+		if !ok {
+			// drain unknown parts, they still count against total size
+			n, _ := io.Copy(io.Discard, io.LimitReader(part, remaining+1))
+			if remaining -= n; remaining < 0 {
+				return errTotalTooLarge(limits)
+			}
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, min(limits.MaxFieldSize, remaining)+1))
+			if err != nil {
+				return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+			}
+			if int64(len(value)) > limits.MaxFieldSize {
+				return nghttp.NewErrPayloadTooLarge().Update(nghttp.Meta("field", part.FormName(), "maxFieldSize", limits.MaxFieldSize))
+			}
+			if remaining -= int64(len(value)); remaining < 0 {
+				return errTotalTooLarge(limits)
+			}
+			if err := setFormValue(field, part.FormName(), string(value)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		file, err := readUploadedFile(ctx, part, limits.SpoolThreshold, min(limits.MaxFileSize, remaining), allowed)
+		if err != nil {
+			return err
+		}
+		if file.Size > limits.MaxFileSize {
+			return nghttp.NewErrPayloadTooLarge().Update(nghttp.Meta("field", file.Field, "maxFileSize", limits.MaxFileSize))
+		}
+		if remaining -= file.Size; remaining < 0 {
+			return errTotalTooLarge(limits)
+		}
+
+		if err := setFormFile(field, file); err != nil {
+			return err
+		}
+	}
+}
+
+// readUploadedFile reads part in memory up to threshold, then continues into a temp file.
+// Reading stops after maxSize+1 bytes, the caller checks Size against its limits.
+func readUploadedFile(ctx context.Context, part *multipart.Part, threshold, maxSize int64, allowed []string) (*UploadedFile, error) {
+	file := &UploadedFile{
+		Field:  part.FormName(),
+		Name:   part.FileName(),
+		Header: part.Header,
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(part, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+	}
+	sniff = sniff[:n]
+
+	file.ContentType = http.DetectContentType(sniff)
+	if !uploadTypeAllowed(allowed, file.ContentType) {
+		return nil, nghttp.NewErrUnsupportedMediaType().Update(
+			nghttp.Meta("field", file.Field, "contentType", file.ContentType),
+		)
+	}
+
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(sniff), part), maxSize+1)
+
+	var buf bytes.Buffer
+	size, err := io.CopyN(&buf, content, threshold+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+	}
+
+	if size <= threshold {
+		file.data, file.Size = buf.Bytes(), size
+		return file, nil
+	}
+
+	// the temp file is removed when the context is cleared, refuse to spool without one
+	var path string
+	if !OnClear(ctx, func() { _ = os.Remove(path) }) {
+		return nil, errors.New("ng: cannot spool upload without request context, see ng.NewContext")
+	}
+
+	tmp, err := os.CreateTemp("", "ng-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	path = tmp.Name()
+
+	size, err = io.Copy(tmp, io.MultiReader(&buf, content))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+	}
+
+	file.path, file.Size = path, size
+	return file, nil
+}
+
+func uploadLimits(ctx context.Context) UploadLimits {
+	limits := DefaultUploadLimits
+	val, ok := GetContext(ctx).Route().Core().Metadata(uploadLimitsKey{})
+	if !ok {
+		return limits
+	}
+
+	custom := val.(UploadLimits)
+	if custom.MaxFileSize > 0 {
+		limits.MaxFileSize = custom.MaxFileSize
+	}
+	if custom.MaxTotalSize > 0 {
+		limits.MaxTotalSize = custom.MaxTotalSize
+	}
+	if custom.MaxFieldSize > 0 {
+		limits.MaxFieldSize = custom.MaxFieldSize
+	}
+	if custom.SpoolThreshold > 0 {
+		limits.SpoolThreshold = custom.SpoolThreshold
+	}
+	return limits
+}
+
+func allowedUploadTypes(ctx context.Context) []string {
+	if val, ok := GetContext(ctx).Route().Core().Metadata(uploadTypesKey{}); ok {
+		return val.([]string)
+	}
+	return nil
+}
+
+// uploadTypeAllowed matches media type without parameters, "image/*" matches any image
+func uploadTypeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		if pattern == mediaType || pattern == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func errTotalTooLarge(limits UploadLimits) error {
+	return nghttp.NewErrPayloadTooLarge().Update(nghttp.Meta("maxTotalSize", limits.MaxTotalSize))
+}
+
+// formFields maps form names to settable struct fields
+func formFields(v reflect.Value) map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tag := strings.Split(sf.Tag.Get("form"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+var uploadedFileType = reflect.TypeOf((*UploadedFile)(nil))
+
+func setFormFile(field reflect.Value, file *UploadedFile) error {
+	switch {
+	case field.Type() == uploadedFileType:
+		field.Set(reflect.ValueOf(file))
+	case field.Kind() == reflect.Slice && field.Type().Elem() == uploadedFileType:
+		field.Set(reflect.Append(field, reflect.ValueOf(file)))
+	default:
+		return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("field", file.Field, "error", "unexpected file"))
+	}
+	return nil
+}
+
+func setFormValue(field reflect.Value, name, value string) error {
+	// optional values, e.g. Limit *int `query:"limit"`
+	if field.Kind() == reflect.Pointer && field.Type() != uploadedFileType {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setFormValue(field.Elem(), name, value)
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem() != uploadedFileType {
+		elem := reflect.New(field.Type().Elem()).Elem()
+		if err := setFormValue(elem, name, value); err != nil {
+			return err
+		}
+		field.Set(reflect.Append(field, elem))
+		return nil
+	}
+
+	var err error
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(value, 10, field.Type().Bits())
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(value, 10, field.Type().Bits())
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(value, field.Type().Bits())
+		field.SetFloat(f)
+	default:
+		err = errors.New("unsupported field type " + field.Type().String())
+	}
+
+	if err != nil {
+		return nghttp.NewErrInvalidArgument().Update(nghttp.WithDetails(&nghttp.BadRequest{
+			FieldViolations: []nghttp.FieldViolation{{Field: name, Description: err.Error(), Reason: "type"}},
+		}))
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package ng
+
+// Content negotiation: select codec by Accept and Content-Type headers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// WithCodecs registers codecs, a codec replaces the inherited one with same content type
+func WithCodecs(codecs ...Codec) Option {
+	return func(c *config) {
+		c.core.codecs = append(c.core.codecs, codecs...)
+	}
+}
+
+// WithJSONCodec replaces the JSON codec, e.g. to swap in a faster encoder app-wide
+/*
+	app := ng.NewApp(
+		ng.WithJSONCodec(ng.NewJSONCodec(sonic.Marshal, sonic.Unmarshal)),
+	)
+*/
+func WithJSONCodec(codec Codec) Option {
+	if codec.ContentType() != MIMEApplicationJSON {
+		codec = &mediaCodec{Codec: codec, contentType: MIMEApplicationJSON}
+	}
+	return WithCodecs(codec)
+}
+
+// mediaCodec overrides content type of a codec
+type mediaCodec struct {
+	Codec
+	contentType string
+}
+
+func (c *mediaCodec) ContentType() string { return c.contentType }
+
+// mergeCodecs appends codecs, replacing existing ones with same content type
+func mergeCodecs(codecs []Codec, more ...Codec) []Codec {
+	for _, codec := range more {
+		replaced := false
+		for i, existing := range codecs {
+			if existing.ContentType() == codec.ContentType() {
+				codecs[i], replaced = codec, true
+				break
+			}
+		}
+
+		if !replaced {
+			codecs = append(codecs, codec)
+		}
+	}
+	return codecs
+}
+
+// Codecs return codecs available to current route, DefaultCodecs when none is bound
+func Codecs(ctx context.Context) []Codec {
+	rc := GetContext(ctx)
+	if rc == nil || rc.Route() == nil {
+		return DefaultCodecs
+	}
+
+	if c, ok := rc.Route().Core().(*core); ok && len(c.codecs) > 0 {
+		return c.codecs
+	}
+	return DefaultCodecs
+}
+
+/*
+Negotiate selects codec for the Accept header, honoring quality values.
+
+An empty Accept header selects the first codec, so do ties, e.g. a catch-all range next to
+"application/xml" at the same quality selects JSON, while "application/xml" at a higher quality selects XML.
+ok is false when no codec is acceptable, the caller should respond with 406 Not Acceptable.
+
+	codec, ok := ng.Negotiate(ctx, "application/xml;q=0.9, application/json")
+*/
+func Negotiate(ctx context.Context, accept string) (codec Codec, ok bool) {
+	codecs := acceptableCodecs(Codecs(ctx), accept)
+	if len(codecs) == 0 {
+		return nil, false
+	}
+	return codecs[0], true
+}
+
+// acceptableCodecs return codecs acceptable for the Accept header, most preferred first
+func acceptableCodecs(codecs []Codec, accept string) []Codec {
+	if strings.TrimSpace(accept) == "" {
+		return codecs
+	}
+
+	type candidate struct {
+		codec Codec
+		q     float64
+	}
+
+	ranges := parseAccept(accept)
+	candidates := []candidate{}
+	for _, c := range codecs {
+		if q := acceptQuality(ranges, c.ContentType()); q > 0 {
+			candidates = append(candidates, candidate{codec: c, q: q})
+		}
+	}
+
+	// stable, ties keep registration order so the first codec wins them
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	acceptable := make([]Codec, 0, len(candidates))
+	for _, c := range candidates {
+		acceptable = append(acceptable, c.codec)
+	}
+	return acceptable
+}
+
+// LookupCodec return codec for request Content-Type, empty Content-Type selects the first codec
+func LookupCodec(ctx context.Context, contentType string) (Codec, bool) {
+	codecs := Codecs(ctx)
+	if strings.TrimSpace(contentType) == "" {
+		return codecs[0], true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, c := range codecs {
+		if c.ContentType() == mediaType {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+/*
+EncodeResponse encodes response body with codec negotiated from the Accept header.
+
+When no codec is acceptable, a 406 error response is encoded with the first codec instead.
+When encoding fails, e.g. XML for map data, the next acceptable codec is tried.
+*nghttp.RawResponse values are returned as is, with an empty content type.
+
+	func ResponseHandler(ctx context.Context, info nghttp.HTTPResponse) error {
+		status, contentType, body := ng.EncodeResponse(ctx, r.Header.Get("Accept"), info)
+		...
+	}
+*/
+func EncodeResponse(ctx context.Context, accept string, resp nghttp.HTTPResponse) (statusCode int, contentType string, body []byte) {
+	if raw, ok := resp.(*nghttp.RawResponse); ok {
+		return raw.StatusCode(), "", raw.Value()
+	}
+
+	fallback := Codecs(ctx)[0]
+	codecs := acceptableCodecs(Codecs(ctx), accept)
+	if len(codecs) == 0 {
+		codecs = []Codec{fallback}
+		resp = nghttp.NewErrNotAcceptable()
+	}
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	for _, codec := range codecs {
+		buf.Reset()
+		if err = codec.Encode(&buf, resp.Response()); err == nil {
+			return resp.StatusCode(), codec.ContentType(), buf.Bytes()
+		}
+	}
+
+	resp = nghttp.NewErrInternal().Update(nghttp.WithCause(err))
+	buf.Reset()
+	_ = fallback.Encode(&buf, resp.Response())
+	return resp.StatusCode(), fallback.ContentType(), buf.Bytes()
+}
+
+// DecodeBody decodes r into dst with codec selected by Content-Type.
+//
+// Unknown Content-Type returns 415 error response, decoding failure returns invalid argument error response.
+func DecodeBody(ctx context.Context, contentType string, r io.Reader, dst any) error {
+	codec, ok := LookupCodec(ctx, contentType)
+	if !ok {
+		return nghttp.NewErrUnsupportedMediaType().Update(nghttp.Meta("contentType", contentType))
+	}
+
+	if err := codec.Decode(r, dst); err != nil {
+		return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()))
+	}
+	return nil
+}
+
+/*
+BindBody decodes *http.Request body stored in context into dst, codec is selected by Content-Type
+
+	ng.WithScopeHandler(func() ng.Handler {
+		var body dto.CreateUserRequest
+		return ng.Handle(
+			ng.BindBody(&body),
+			func(ctx context.Context) error { ... },
+		)
+	})
+*/
+func BindBody(dst any) Handler {
+	return func(ctx context.Context) error {
+		r, err := Load[*http.Request](ctx)
+		if err != nil {
+			return err
+		}
+		return DecodeBody(ctx, r.Header.Get("Content-Type"), r.Body, dst)
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	ranges := []acceptRange{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality return quality of the most specific range matching media type
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mediaType == mediaType:
+			s = 2
+		case r.mediaType == typ+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,102 @@
+package ngtest
+
+// Assertions on run results and responses
+
+import (
+	"reflect"
+	"testing"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// AssertAllowed fails the test if guard denied access
+func (res *Result) AssertAllowed(t testing.TB) {
+	t.Helper()
+	if res.Panic != nil {
+		t.Fatalf("expected guard to allow, got panic: %v", res.Panic)
+	}
+	if res.Err != nil {
+		t.Fatalf("expected guard to allow, got error: %v", res.Err)
+	}
+}
+
+// AssertDenied fails the test if guard allowed access
+func (res *Result) AssertDenied(t testing.TB) {
+	t.Helper()
+	if res.Err == nil && res.Panic == nil {
+		t.Fatalf("expected guard to deny, but it allowed")
+	}
+}
+
+// AssertNextCalled fails the test if next was not called
+func (res *Result) AssertNextCalled(t testing.TB) {
+	t.Helper()
+	if !res.NextCalled {
+		t.Fatalf("expected next to be called")
+	}
+}
+
+// AssertNextNotCalled fails the test if next was called
+func (res *Result) AssertNextNotCalled(t testing.TB) {
+	t.Helper()
+	if res.NextCalled {
+		t.Fatalf("expected next not to be called")
+	}
+}
+
+// AssertStatus fails the test if response status code is not expected
+func (res *Result) AssertStatus(t testing.TB, expected int) {
+	t.Helper()
+	AssertStatus(t, res.Response, expected)
+}
+
+// AssertCode fails the test if response code is not expected
+func (res *Result) AssertCode(t testing.TB, expected nghttp.Code) {
+	t.Helper()
+	AssertCode(t, res.Response, expected)
+}
+
+// AssertStatus fails the test if response status code is not expected
+func AssertStatus(t testing.TB, resp nghttp.HTTPResponse, expected int) {
+	t.Helper()
+	if resp == nil {
+		t.Fatalf("expected status %d, got no response", expected)
+	}
+	if resp.StatusCode() != expected {
+		t.Fatalf("expected status %d, got %d", expected, resp.StatusCode())
+	}
+}
+
+// AssertCode fails the test if response is not *nghttp.Response with expected code
+func AssertCode(t testing.TB, resp nghttp.HTTPResponse, expected nghttp.Code) {
+	t.Helper()
+	r := MustResponse(t, resp)
+	if r.Code != expected {
+		t.Fatalf("expected code %s, got %s", expected, r.Code)
+	}
+}
+
+// AssertMeta fails the test if response meta value of key is not expected
+func AssertMeta(t testing.TB, resp nghttp.HTTPResponse, key string, expected any) {
+	t.Helper()
+	r := MustResponse(t, resp)
+	if val, ok := r.Meta[key]; !ok || !reflect.DeepEqual(val, expected) {
+		t.Fatalf("expected meta %s=%v, got %v", key, expected, r.Meta[key])
+	}
+}
+
+// MustResponse return underlying *nghttp.Response, *nghttp.PanicError is unwrapped
+func MustResponse(t testing.TB, resp nghttp.HTTPResponse) *nghttp.Response {
+	t.Helper()
+	switch v := resp.(type) {
+	case *nghttp.Response:
+		return v
+	case *nghttp.PanicError:
+		return v.Response().(*nghttp.Response)
+	case nil:
+		t.Fatalf("expected *nghttp.Response, got no response")
+	default:
+		t.Fatalf("expected *nghttp.Response, got %T", resp)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package ngtest
+
+// In-memory client calling built app routes without an HTTP server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type (
+	// ClientOption is used to customize Client
+	ClientOption func(c *Client)
+
+	// RequestOption is used to customize a single client request
+	RequestOption func(r *http.Request)
+
+	// Client calls built routes through the real Route.Handler pipeline
+	//
+	// Routes are matched with http.ServeMux, echo style ":id" and "*" segments are converted,
+	// so path parameters are available through (*http.Request).PathValue.
+	// The app ResponseHandler must write through the stored http.ResponseWriter,
+	// e.g. ngadapter.ServeMuxResponseHandler.
+	Client struct {
+		app      ng.App
+		mux      *http.ServeMux
+		prepares []func(ctx context.Context)
+		guards   []*guardOverride
+
+		// checks run before the pipeline, the returned function gets the response
+		checks []func(route ng.Route, r *http.Request) func(resp nghttp.HTTPResponse)
+	}
+
+	// ClientResponse is what the ResponseHandler produced for a request
+	ClientResponse struct {
+		// StatusCode is the written http status code
+		StatusCode int
+
+		// Header is the written response header
+		Header http.Header
+
+		// Body is the written response body
+		Body []byte
+
+		// HTTPResponse is the response value handed to the ResponseHandler
+		HTTPResponse nghttp.HTTPResponse
+
+		// Response is HTTPResponse when it is a *nghttp.Response, e.g. localized or enveloped,
+		// else the decoded body, nil if body is not one
+		Response *nghttp.Response
+
+		// Route is the matched route, nil if no route matched
+		Route ng.Route
+
+		// Duration is the time spent in the route handler
+		Duration time.Duration
+
+		// Panic is the value that escaped the route handler, e.g. from the ResponseHandler
+		Panic any
+	}
+
+	clientKey struct{}
+
+	guardOverride struct {
+		target ng.Guard
+		guard  ng.Guard
+		used   bool
+	}
+)
+
+// OverrideGuard replaces target guard of every route for client requests, see ng.ReplaceGuards
+//
+// Guards implementing ng.ID are matched by NgID, func guards such as ng.GuardFunc by function,
+// other guards by equality. NewClient panics if target is not used by any route.
+func OverrideGuard(target ng.Guard, guard ng.Guard) ClientOption {
+	return func(c *Client) {
+		c.guards = append(c.guards, &guardOverride{target: target, guard: guard})
+	}
+}
+
+// OverrideValue stores value into every request context before the pipeline runs,
+// so providers resolved with ng.Load or ng.LoadOrStore can be replaced by fakes.
+// Middlewares storing the same type and keys with ng.Store replace the override.
+func OverrideValue[T any](value T, keys ...ng.PayloadKeyer) ClientOption {
+	return func(c *Client) {
+		c.prepares = append(c.prepares, func(ctx context.Context) {
+			ng.Store(ctx, value, keys...)
+		})
+	}
+}
+
+// AllowGuard is a guard that always allows
+var AllowGuard ng.Guard = ng.GuardFunc(func(ctx context.Context) error { return nil })
+
+// DenyGuard return a guard that always denies with given error
+func DenyGuard(err error) ng.Guard {
+	return ng.GuardFunc(func(ctx context.Context) error { return err })
+}
+
+/*
+NewClient create new in-memory client for built app
+
+	app.Build()
+	client := ngtest.NewClient(app, ngtest.OverrideGuard(AuthGuard{}, ngtest.AllowGuard))
+
+	resp := client.Request(http.MethodPost, "/users", ngtest.WithJSON(dto))
+	resp.AssertStatus(t, http.StatusOK)
+*/
+func NewClient(app ng.App, opts ...ClientOption) *Client {
+	c := &Client{app: app, mux: http.NewServeMux()}
+	for _, o := range opts {
+		o(c)
+	}
+
+	for _, route := range app.Routes() {
+		if len(c.guards) > 0 {
+			route = ng.ReplaceGuards(route, c.replaceGuard)
+		}
+		c.mux.Handle(fmt.Sprintf("%s %s", route.Method(), muxPattern(route.Path())), c.handler(route))
+	}
+
+	for _, o := range c.guards {
+		if !o.used {
+			panic(fmt.Sprintf("ngtest: guard %T is not used by any route", o.target))
+		}
+	}
+
+	return c
+}
+
+// replaceGuard return override of guard if any
+func (c *Client) replaceGuard(guard ng.Guard) ng.Guard {
+	for _, o := range c.guards {
+		if sameGuard(o.target, guard) {
+			o.used = true
+			return o.guard
+		}
+	}
+	return guard
+}
+
+// sameGuard reports whether guard is target
+func sameGuard(target, guard ng.Guard) bool {
+	if tid, ok := target.(ng.ID); ok {
+		id, ok := guard.(ng.ID)
+		return ok && id.NgID() == tid.NgID()
+	}
+
+	tv, v := reflect.ValueOf(target), reflect.ValueOf(guard)
+	if tv.Type() != v.Type() {
+		return false
+	}
+
+	if tv.Kind() == reflect.Func {
+		return tv.Pointer() == v.Pointer()
+	}
+	return tv.Comparable() && tv.Equal(v)
+}
+
+// WithJSON sets JSON encoded request body and content type
+func WithJSON(body any) RequestOption {
+	return func(r *http.Request) {
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(err)
+		}
+		r.Header.Set("Content-Type", "application/json")
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		r.ContentLength = int64(len(data))
+	}
+}
+
+// WithRequestHeader sets request header
+func WithRequestHeader(key, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// Request calls route matching method and path
+func (c *Client) Request(method string, path string, opts ...RequestOption) *ClientResponse {
+	req := httptest.NewRequest(method, path, nil)
+	for _, o := range opts {
+		o(req)
+	}
+	return c.Do(req)
+}
+
+// Do calls route matching given request
+func (c *Client) Do(req *http.Request) *ClientResponse {
+	resp := &ClientResponse{}
+	rec := httptest.NewRecorder()
+
+	c.mux.ServeHTTP(rec, req.WithContext(context.WithValue(req.Context(), clientKey{}, resp)))
+
+	result := rec.Result()
+	resp.StatusCode = result.StatusCode
+	resp.Header = result.Header
+	resp.Body = rec.Body.Bytes()
+
+	if r, ok := resp.HTTPResponse.(*nghttp.Response); ok {
+		resp.Response = r
+	} else {
+		resp.Response = decodeResponse(resp.StatusCode, resp.Body)
+	}
+
+	return resp
+}
+
+func (c *Client) handler(route ng.Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := r.Context().Value(clientKey{}).(*ClientResponse)
+		resp.Route = route
+
+		ctx, rc := ng.NewContext(r.Context())
+		defer rc.Clear()
+
+		req := r.WithContext(ctx)
+		var afterChecks []func(resp nghttp.HTTPResponse)
+		for _, check := range c.checks {
+			afterChecks = append(afterChecks, check(route, req))
+		}
+
+		ng.Store(ctx, w)
+		ng.Store(ctx, req)
+
+		for _, prepare := range c.prepares {
+			prepare(ctx)
+		}
+
+		start := time.Now()
+		defer func() {
+			resp.Duration = time.Since(start)
+			resp.HTTPResponse = rc.GetResponse()
+			if p := recover(); p != nil {
+				resp.Panic = p
+			}
+			for _, after := range afterChecks {
+				after(resp.HTTPResponse)
+			}
+		}()
+
+		_ = route.Handler()(ctx)
+	}
+}
+
+// decodeResponse decodes body as nghttp.Response envelope
+func decodeResponse(statusCode int, body []byte) *nghttp.Response {
+	resp, err := nghttp.ParseResponse(statusCode, body)
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+// muxPattern converts echo style path params into http.ServeMux wildcards
+func muxPattern(path string) string {
+	if path == "" {
+		return "/{$}"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			segments[i] = "{" + seg[1:] + "}"
+		case seg == "*":
+			segments[i] = "{path...}"
+		case strings.HasPrefix(seg, "*"):
+			segments[i] = "{" + seg[1:] + "...}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// AssertStatus fails the test if written status code is not expected
+func (r *ClientResponse) AssertStatus(t testing.TB, expected int) {
+	t.Helper()
+	if r.Panic != nil {
+		t.Fatalf("expected status %d, got panic: %v", expected, r.Panic)
+	}
+	if r.StatusCode != expected {
+		t.Fatalf("expected status %d, got %d: %s", expected, r.StatusCode, r.Body)
+	}
+}
+
+// AssertCode fails the test if decoded response code is not expected
+func (r *ClientResponse) AssertCode(t testing.TB, expected nghttp.Code) {
+	t.Helper()
+	if r.Response == nil {
+		t.Fatalf("expected code %s, got body: %s", expected, r.Body)
+	}
+	if r.Response.Code != expected {
+		t.Fatalf("expected code %s, got %s", expected, r.Response.Code)
+	}
+}
+
+// DecodeData decodes data field of written body into dest
+func (r *ClientResponse) DecodeData(t testing.TB, dest any) {
+	t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(r.Body, &envelope); err != nil {
+		t.Fatalf("failed to decode body: %v: %s", err, r.Body)
+	}
+
+	if err := json.Unmarshal(envelope.Data, dest); err != nil {
+		t.Fatalf("failed to decode data: %v: %s", err, envelope.Data)
+	}
+}
@@ -0,0 +1,141 @@
+package ngtest
+
+// Synthetic request context for unit testing guards, middlewares and interceptors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/foxie-io/ng"
+)
+
+type (
+	// Option is used to customize synthetic context
+	Option func(c *config)
+
+	config struct {
+		parent    context.Context
+		name      string
+		method    string
+		path      string
+		body      io.Reader
+		request   *http.Request
+		routeOpts []ng.Option
+		values    []func(ctx context.Context)
+	}
+
+	// route overrides name of synthetic ng.Route, name is only assigned by controllers
+	route struct {
+		ng.Route
+		name string
+	}
+)
+
+func (r *route) Name() string { return r.name }
+
+// WithParent sets parent context, default is context.Background()
+func WithParent(ctx context.Context) Option {
+	return func(c *config) {
+		c.parent = ctx
+	}
+}
+
+// WithRoute sets method and path of synthetic route, default is GET /
+func WithRoute(method string, path string) Option {
+	return func(c *config) {
+		c.method = method
+		c.path = path
+	}
+}
+
+// WithName sets name of synthetic route, default is "ngtest.Route"
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithRouteOptions applies ng options to synthetic route, e.g. ng.WithSkip, ng.SkipAllGuards
+func WithRouteOptions(opts ...ng.Option) Option {
+	return func(c *config) {
+		c.routeOpts = append(c.routeOpts, opts...)
+	}
+}
+
+// WithMetadata sets route metadata key-value pairs, same as ng.WithMetadata
+func WithMetadata(pairs ...any) Option {
+	return WithRouteOptions(ng.WithMetadata(pairs...))
+}
+
+// WithValue stores value into context storage, same as ng.Store
+func WithValue[T any](value T, keys ...ng.PayloadKeyer) Option {
+	return func(c *config) {
+		c.values = append(c.values, func(ctx context.Context) {
+			ng.Store(ctx, value, keys...)
+		})
+	}
+}
+
+// WithRequest sets *http.Request stored into context, default is built from route method and path
+func WithRequest(r *http.Request) Option {
+	return func(c *config) {
+		c.request = r
+	}
+}
+
+// WithBody sets request body of default *http.Request
+func WithBody(body io.Reader) Option {
+	return func(c *config) {
+		c.body = body
+	}
+}
+
+/*
+NewContext create new request context bound to a synthetic route.
+
+*http.Request and *httptest.ResponseRecorder (as http.ResponseWriter) are stored into context,
+so code written for the ServeMux adapter can run as is.
+
+	ctx, rc := ngtest.NewContext(
+		ngtest.WithRoute(http.MethodGet, "/users/1"),
+		ngtest.WithMetadata(RoleKey{}, "admin"),
+		ngtest.WithValue(User{ID: 1}),
+	)
+*/
+func NewContext(opts ...Option) (context.Context, ng.Context) {
+	c := &config{
+		parent: context.Background(),
+		name:   "ngtest.Route",
+		method: http.MethodGet,
+		path:   "/",
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	r := ng.NewRoute(c.method, c.path, ng.WithHandler(func(ctx context.Context) error { return nil }), c.routeOpts...)
+	ctx, rc := ng.NewRouteContext(c.parent, &route{Route: r, name: c.name})
+
+	req := c.request
+	if req == nil {
+		req = httptest.NewRequest(c.method, c.path, c.body)
+	}
+
+	ng.Store(ctx, req.WithContext(ctx))
+	ng.Store[http.ResponseWriter](ctx, httptest.NewRecorder())
+
+	for _, store := range c.values {
+		store(ctx)
+	}
+
+	return ctx, rc
+}
+
+// Recorder return *httptest.ResponseRecorder stored by NewContext
+func Recorder(ctx context.Context) *httptest.ResponseRecorder {
+	w, _ := ng.MustLoad[http.ResponseWriter](ctx).(*httptest.ResponseRecorder)
+	return w
+}
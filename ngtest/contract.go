@@ -0,0 +1,43 @@
+package ngtest
+
+// Contract checks of client requests and responses against an OpenAPI document
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+/*
+WithContracts fails t when client requests or route responses break spec, so contract drift fails unit tests.
+Checks are the ones of ngopenapi.ValidateContracts, responses are not changed.
+
+	spec, _ := ngopenapi.Parse(specYAML)
+	client := ngtest.NewClient(app, ngtest.WithContracts(t, spec, ngopenapi.WithStrictProperties()))
+*/
+func WithContracts(t testing.TB, spec *ngopenapi.Document, opts ...ngopenapi.ContractOption) ClientOption {
+	contracts := ngopenapi.ValidateContracts(spec, opts...)
+
+	report := func(route ng.Route, violations []ngopenapi.Violation) {
+		t.Helper()
+		path, _ := ngopenapi.Path(route)
+		for _, v := range violations {
+			t.Errorf("contract violation of %s %s: %s", route.Method(), path, v)
+		}
+	}
+
+	return func(c *Client) {
+		c.checks = append(c.checks, func(route ng.Route, r *http.Request) func(resp nghttp.HTTPResponse) {
+			report(route, contracts.CheckRequest(r.Context(), route, r))
+
+			return func(resp nghttp.HTTPResponse) {
+				if resp != nil {
+					report(route, contracts.CheckResponse(route, resp))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package ngtest
+
+// Run guards, middlewares and interceptors in isolation
+
+import (
+	"context"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// Result captures the outcome of running a guard, middleware or interceptor
+type Result struct {
+	// Ctx is the context used for the run
+	Ctx context.Context
+
+	// NextCalled reports whether next was called, for guards whether access was allowed
+	NextCalled bool
+
+	// Err is the error returned by a guard
+	Err error
+
+	// Panic is the value thrown during the run, e.g. ng.ThrowResponse
+	Panic any
+
+	// Response is the response stored in context after the run,
+	// errors and thrown values are converted by ng.DefaultValueHandler like in the request flow
+	Response nghttp.HTTPResponse
+}
+
+/*
+RunGuard runs guard against given context
+
+	ctx, _ := ngtest.NewContext(ngtest.WithValue(User{Role: "user"}))
+	res := ngtest.RunGuard(ctx, &RoleGuard{RequiredRole: "admin"})
+	res.AssertDenied(t)
+	res.AssertCode(t, nghttp.CodePermissionDenied)
+*/
+func RunGuard(ctx context.Context, guard ng.Guard) *Result {
+	ctx = ensureContext(ctx)
+	res := &Result{Ctx: ctx}
+
+	res.capture(func() {
+		res.Err = guard.Allow(ctx)
+		res.NextCalled = res.Err == nil
+		if res.Err != nil {
+			ng.GetContext(ctx).SetResponse(ng.DefaultValueHandler(ctx, res.Err))
+		}
+	})
+
+	return res
+}
+
+/*
+RunMiddleware runs middleware against given context, next runs given handlers
+
+	ctx, _ := ngtest.NewContext()
+	res := ngtest.RunMiddleware(ctx, TokenParser{}, func(ctx context.Context) error {
+		return ng.Respond(ctx, nghttp.NewResponse("ok"))
+	})
+	res.AssertNextCalled(t)
+*/
+func RunMiddleware(ctx context.Context, middleware ng.Middleware, next ...ng.Handler) *Result {
+	ctx = ensureContext(ctx)
+	res := &Result{Ctx: ctx}
+
+	res.capture(func() {
+		middleware.Use(ctx, res.next(next))
+	})
+
+	return res
+}
+
+// RunInterceptor runs interceptor against given context, next runs given handlers
+func RunInterceptor(ctx context.Context, interceptor ng.Interceptor, next ...ng.Handler) *Result {
+	ctx = ensureContext(ctx)
+	res := &Result{Ctx: ctx}
+
+	res.capture(func() {
+		interceptor.Intercept(ctx, res.next(next))
+	})
+
+	return res
+}
+
+// next records call and saves handler error as response, like the request flow does
+func (res *Result) next(handlers []ng.Handler) ng.Handler {
+	return func(ctx context.Context) error {
+		res.NextCalled = true
+
+		defer func() {
+			if r := recover(); r != nil {
+				ng.GetContext(ctx).SetResponse(ng.DefaultValueHandler(ctx, r))
+			}
+		}()
+
+		if err := ng.Handle(handlers...)(ctx); err != nil {
+			ng.GetContext(ctx).SetResponse(ng.DefaultValueHandler(ctx, err))
+		}
+
+		return nil
+	}
+}
+
+func (res *Result) capture(run func()) {
+	rc := ng.GetContext(res.Ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			res.Panic = r
+			rc.SetResponse(ng.DefaultValueHandler(res.Ctx, r))
+		}
+		res.Response = rc.GetResponse()
+	}()
+
+	run()
+}
+
+func ensureContext(ctx context.Context) context.Context {
+	if ctx == nil || ng.GetContext(ctx) == nil || ng.GetContext(ctx).Route() == nil {
+		ctx, _ = NewContext(WithParent(parentOf(ctx)))
+	}
+	return ctx
+}
+
+func parentOf(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
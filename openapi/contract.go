@@ -0,0 +1,628 @@
+package ngopenapi
+
+// Contract validation: requests and responses of routes are checked against operations of a document
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/mail"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// ContractHeader is response header listing violations in ContractAnnotate mode
+const ContractHeader = "X-Contract-Violations"
+
+// ContractMode controls what happens to requests and responses breaking the contract
+type ContractMode int
+
+const (
+	// ContractReject answers INVALID_ARGUMENT for invalid requests and INTERNAL for invalid responses,
+	// violations are listed in meta "violations"
+	ContractReject ContractMode = iota
+
+	// ContractLog logs violations with slog and leaves responses untouched
+	ContractLog
+
+	// ContractAnnotate lists violations in ContractHeader of the response
+	ContractAnnotate
+
+	// ContractReport only calls OnContractViolation handlers, e.g. for metrics or tests
+	ContractReport
+)
+
+var contractModes = map[string]ContractMode{
+	"reject":   ContractReject,
+	"log":      ContractLog,
+	"annotate": ContractAnnotate,
+	"report":   ContractReport,
+}
+
+/*
+ContractModeFromEnv return mode named by environment variable key: "reject", "log", "annotate" or "report",
+fallback when it is unset or unknown.
+
+	// CONTRACTS=reject in development, log in production
+	ngopenapi.WithContractMode(ngopenapi.ContractModeFromEnv("CONTRACTS", ngopenapi.ContractLog))
+*/
+func ContractModeFromEnv(key string, fallback ContractMode) ContractMode {
+	if mode, ok := contractModes[strings.ToLower(os.Getenv(key))]; ok {
+		return mode
+	}
+	return fallback
+}
+
+type (
+	// ContractOption configures Contracts
+	ContractOption func(*Contracts)
+
+	// Violation is a difference between a request or response and its operation
+	Violation struct {
+		// In is "path", "query", "header" or "body" for requests, "response" for responses
+		// and "route" for routes missing from the document
+		In string
+
+		// Field locates the value, e.g. "limit" or "data.items[0].name", empty for the whole value
+		Field string
+
+		Message string
+	}
+
+	/*
+		Contracts validates requests and responses of routes against the operations of a document.
+		Operations are matched by method and path template, parameter names may differ.
+		Hidden routes are skipped. Go writes nil pointers, slices and maps as null, so null is accepted for any schema.
+	*/
+	Contracts struct {
+		doc          *Document
+		requestMode  ContractMode
+		responseMode ContractMode
+		strict       bool
+		maxBodySize  int64
+		handlers     []func(ctx context.Context, route ng.Route, violations []Violation)
+
+		operations map[string]*Operation
+		patterns   sync.Map
+	}
+)
+
+var _ ng.Interceptor = (*Contracts)(nil)
+
+// WithContractMode sets mode of requests and responses, default is ContractReject
+func WithContractMode(mode ContractMode) ContractOption {
+	return func(c *Contracts) {
+		c.requestMode = mode
+		c.responseMode = mode
+	}
+}
+
+// WithResponseContractMode sets mode of responses only, e.g. reject bad requests but log bad responses
+func WithResponseContractMode(mode ContractMode) ContractOption {
+	return func(c *Contracts) {
+		c.responseMode = mode
+	}
+}
+
+// WithStrictProperties reports object properties missing from schemas, e.g. fields renamed in code only
+func WithStrictProperties() ContractOption {
+	return func(c *Contracts) {
+		c.strict = true
+	}
+}
+
+// WithMaxBodySize sets size of request bodies read for validation, larger bodies are passed on unvalidated, 1MB by default
+func WithMaxBodySize(size int64) ContractOption {
+	return func(c *Contracts) {
+		c.maxBodySize = size
+	}
+}
+
+// OnContractViolation adds handler called with violations of a request or response in every mode
+func OnContractViolation(fn func(ctx context.Context, route ng.Route, violations []Violation)) ContractOption {
+	return func(c *Contracts) {
+		c.handlers = append(c.handlers, fn)
+	}
+}
+
+/*
+ValidateContracts return interceptor checking incoming requests and outgoing responses against spec,
+parameters and JSON bodies of requests, status codes, error codes and enveloped bodies of responses.
+
+	spec, _ := ngopenapi.Parse(specYAML)
+
+	app := ng.NewApp(
+		ng.WithInterceptor(ngopenapi.ValidateContracts(spec,
+			ngopenapi.WithContractMode(ngopenapi.ContractModeFromEnv("CONTRACTS", ngopenapi.ContractLog)),
+		)),
+	)
+*/
+func ValidateContracts(spec *Document, opts ...ContractOption) *Contracts {
+	c := &Contracts{doc: spec, maxBodySize: 1 << 20, operations: map[string]*Operation{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			c.operations[operationKey(method, path)] = op
+		}
+	}
+	return c
+}
+
+// operationKey matches paths with differently named parameters, e.g. /users/{id} and /users/{userId}
+func operationKey(method, path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") {
+			segments[i] = "{}"
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.Join(segments, "/")
+}
+
+// Operation return operation of route, nil when it is not documented
+func (c *Contracts) Operation(route ng.Route) *Operation {
+	path, _ := Path(route)
+	return c.operations[operationKey(route.Method(), path)]
+}
+
+// Intercept validates request before next and response after it
+func (c *Contracts) Intercept(ctx context.Context, next ng.Handler) {
+	rc := ng.GetContext(ctx)
+	route, ok := rc.Route().(ng.Route)
+	req, err := ng.Load[*http.Request](ctx)
+	if !ok || err != nil {
+		next(ctx)
+		return
+	}
+
+	var annotations []Violation
+	if violations := c.CheckRequest(ctx, route, req); len(violations) > 0 {
+		c.report(ctx, route, violations)
+		switch c.requestMode {
+		case ContractReject:
+			rc.SetResponse(nghttp.NewErrInvalidArgument().Update(nghttp.Meta("violations", violationStrings(violations))))
+			return
+		case ContractLog:
+			logViolations(ctx, route, violations)
+		case ContractAnnotate:
+			annotations = violations
+		}
+	}
+
+	next(ctx)
+
+	resp := rc.GetResponse()
+	if resp == nil {
+		return
+	}
+	if violations := c.CheckResponse(route, resp); len(violations) > 0 {
+		c.report(ctx, route, violations)
+		switch c.responseMode {
+		case ContractReject:
+			rc.SetResponse(nghttp.NewErrInternal().Update(nghttp.Meta("violations", violationStrings(violations))))
+			return
+		case ContractLog:
+			logViolations(ctx, route, violations)
+		case ContractAnnotate:
+			annotations = append(annotations, violations...)
+		}
+	}
+
+	if len(annotations) > 0 {
+		rc.SetResponse(annotate(resp, strings.Join(violationStrings(annotations), "; ")))
+	}
+}
+
+func (c *Contracts) report(ctx context.Context, route ng.Route, violations []Violation) {
+	for _, fn := range c.handlers {
+		fn(ctx, route, violations)
+	}
+}
+
+func logViolations(ctx context.Context, route ng.Route, violations []Violation) {
+	path, _ := Path(route)
+	slog.WarnContext(ctx, "ngopenapi: contract violation", "route", route.Method()+" "+path, "violations", violationStrings(violations))
+}
+
+// annotate sets header on a copy of shared responses
+func annotate(resp nghttp.HTTPResponse, value string) nghttp.HTTPResponse {
+	switch t := resp.(type) {
+	case *nghttp.Response:
+		return t.With(nghttp.WithHeader(ContractHeader, value))
+	case nghttp.HeaderCarrier:
+		t.Headers().Set(ContractHeader, value)
+	}
+	return resp
+}
+
+// String return violation as "in.field: message"
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.In + ": " + v.Message
+	}
+	return v.In + "." + v.Field + ": " + v.Message
+}
+
+func violationStrings(violations []Violation) []string {
+	list := make([]string, len(violations))
+	for i, v := range violations {
+		list[i] = v.String()
+	}
+	return list
+}
+
+// CheckRequest return violations of request parameters and JSON body, body is read and restored.
+// Path parameters are resolved with ng.PathValue.
+func (c *Contracts) CheckRequest(ctx context.Context, route ng.Route, r *http.Request) []Violation {
+	if Hidden(route) {
+		return nil
+	}
+	op := c.Operation(route)
+	if op == nil {
+		path, _ := Path(route)
+		return []Violation{{In: "route", Message: route.Method() + " " + path + " is not documented"}}
+	}
+
+	v := &validator{contracts: c}
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if value, _ := ng.PathValue(ctx, r, p.Name); value != "" {
+				values = []string{value}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		default:
+			continue
+		}
+		v.parameter(p, values)
+	}
+
+	if op.RequestBody != nil {
+		v.body(op.RequestBody, r)
+	}
+	return v.violations
+}
+
+// CheckResponse return violations of status, error code and JSON body of response written by route
+func (c *Contracts) CheckResponse(route ng.Route, resp nghttp.HTTPResponse) []Violation {
+	op := c.Operation(route)
+	if op == nil || Hidden(route) {
+		return nil
+	}
+
+	status := resp.StatusCode()
+	spec := responseSpec(op, status)
+	if spec == nil {
+		return []Violation{{In: "response", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+
+	r, ok := resp.(*nghttp.Response)
+	if !ok {
+		return nil
+	}
+	if r.IsError() {
+		if len(op.ErrorCodes) > 0 && !slices.Contains(op.ErrorCodes, string(r.Code)) {
+			return []Violation{{In: "response", Field: "code", Message: fmt.Sprintf("error code %s is not documented", r.Code)}}
+		}
+		return nil
+	}
+
+	schema := jsonSchema(spec.Content)
+	if schema == nil {
+		return nil
+	}
+
+	// the body is checked as written, see ng.WithEnvelope
+	envelope := r.Envelope()
+	if envelope == nil {
+		envelope = ng.RouteEnvelope(route)
+	}
+	data, err := json.Marshal(envelope.Wrap(r))
+	if err != nil {
+		return []Violation{{In: "response", Message: err.Error()}}
+	}
+
+	v := &validator{contracts: c, in: "response"}
+	v.json(schema, data)
+	return v.violations
+}
+
+// responseSpec return response of exact status, status class like 4XX, or default
+func responseSpec(op *Operation, status int) *Response {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if resp, ok := op.Responses[key]; ok {
+			return resp
+		}
+	}
+	return nil
+}
+
+func jsonSchema(content map[string]MediaType) *Schema {
+	if media, ok := content[ContentType]; ok {
+		return media.Schema
+	}
+	for _, name := range slices.Sorted(maps.Keys(content)) {
+		if strings.HasSuffix(name, "json") {
+			return content[name].Schema
+		}
+	}
+	return nil
+}
+
+// validator collects violations of a request or response
+type validator struct {
+	contracts  *Contracts
+	in         string
+	violations []Violation
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.violations = append(v.violations, Violation{In: v.in, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) parameter(p Parameter, values []string) {
+	v.in = p.In
+	if len(values) == 0 {
+		if p.Required || p.In == "path" {
+			v.add(p.Name, "is required")
+		}
+		return
+	}
+
+	schema := v.contracts.doc.Resolve(p.Schema)
+	if schema == nil {
+		return
+	}
+	if schema.Type == "array" {
+		var items []any
+		for _, value := range values {
+			items = append(items, parameterValue(v.contracts.doc.Resolve(schema.Items), value))
+		}
+		v.value(p.Name, schema, items)
+		return
+	}
+	v.value(p.Name, schema, parameterValue(schema, values[0]))
+}
+
+// parameterValue converts parameter to the JSON value of schema type, invalid values are left as strings
+func parameterValue(schema *Schema, value string) any {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func (v *validator) body(spec *RequestBody, r *http.Request) {
+	v.in = "body"
+
+	var data []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		limit := v.contracts.maxBodySize
+		if r.ContentLength > limit {
+			return
+		}
+
+		var err error
+		if data, err = io.ReadAll(io.LimitReader(r.Body, limit+1)); err != nil {
+			v.add("", "%v", err)
+			return
+		}
+		if int64(len(data)) > limit {
+			// too large to validate, handler still reads the whole body
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if spec.Required {
+			v.add("", "is required")
+		}
+		return
+	}
+
+	mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if _, ok := spec.Content[mediaType]; !ok && mediaType != "" {
+		v.add("", "content type %s is not documented", mediaType)
+		return
+	}
+	if schema := jsonSchema(spec.Content); schema != nil && strings.HasSuffix(cmp.Or(mediaType, ContentType), "json") {
+		v.json(schema, data)
+	}
+}
+
+func (v *validator) json(schema *Schema, data []byte) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		v.add("", "invalid JSON: %v", err)
+		return
+	}
+	v.value("", schema, value)
+}
+
+// value validates JSON value decoded with json.Number against schema
+func (v *validator) value(field string, schema *Schema, value any) {
+	if schema != nil && schema.Ref != "" {
+		resolved := v.contracts.doc.Resolve(schema)
+		if resolved == nil {
+			v.add(field, "reference %s is not defined", schema.Ref)
+			return
+		}
+		schema = resolved
+	}
+	if schema == nil || value == nil {
+		return
+	}
+
+	if schema.Type != "" && jsonType(value, schema.Type) != schema.Type {
+		v.add(field, "expected %s, got %s", schema.Type, jsonType(value, schema.Type))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		v.add(field, "must be one of %v", schema.Enum)
+	}
+
+	switch t := value.(type) {
+	case string:
+		v.string(field, schema, t)
+	case json.Number:
+		n, _ := t.Float64()
+		if schema.Minimum != nil && n < *schema.Minimum {
+			v.add(field, "must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			v.add(field, "must be at most %v", *schema.Maximum)
+		}
+	case []any:
+		v.length(field, "items", len(t), schema.MinItems, schema.MaxItems)
+		for i, item := range t {
+			v.value(fmt.Sprintf("%s[%d]", field, i), schema.Items, item)
+		}
+	case map[string]any:
+		v.object(field, schema, t)
+	}
+}
+
+func (v *validator) object(field string, schema *Schema, value map[string]any) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			v.add(join(field, name), "is required")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(value)) {
+		switch prop, ok := schema.Properties[name]; {
+		case ok:
+			v.value(join(field, name), prop, value[name])
+		case schema.AdditionalProperties != nil:
+			v.value(join(field, name), schema.AdditionalProperties, value[name])
+		case v.contracts.strict && len(schema.Properties) > 0:
+			v.add(join(field, name), "is not documented")
+		}
+	}
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func (v *validator) string(field string, schema *Schema, s string) {
+	v.length(field, "characters", utf8.RuneCountInString(s), schema.MinLength, schema.MaxLength)
+
+	if schema.Pattern != "" {
+		if re, err := v.contracts.pattern(schema.Pattern); err == nil && !re.MatchString(s) {
+			v.add(field, "must match %s", schema.Pattern)
+		}
+	}
+
+	valid := true
+	switch schema.Format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		valid = err == nil
+	case "email":
+		_, err := mail.ParseAddress(s)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(s)
+	}
+	if !valid {
+		v.add(field, "is not a valid %s", schema.Format)
+	}
+}
+
+func (v *validator) length(field, unit string, n int, minimum, maximum *int) {
+	if minimum != nil && n < *minimum {
+		v.add(field, "must have at least %d %s", *minimum, unit)
+	}
+	if maximum != nil && n > *maximum {
+		v.add(field, "must have at most %d %s", *maximum, unit)
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// pattern return compiled pattern, patterns are compiled once
+func (c *Contracts) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := c.patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.patterns.Store(pattern, re)
+	return re, nil
+}
+
+// jsonType return JSON Schema type of value, integers are numbers without fraction
+func jsonType(value any, expected string) string {
+	switch t := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if expected == "number" {
+			return "number"
+		}
+		if _, err := t.Int64(); err == nil {
+			return "integer"
+		}
+		if f, err := t.Float64(); err == nil && f == float64(int64(f)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,159 @@
+// Package ngdocs serves the OpenAPI document of an app and an offline UI as an ng controller.
+package ngdocs
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+// Content types of served documents
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeYAML = "application/yaml"
+	ContentTypeHTML = "text/html; charset=utf-8"
+)
+
+var (
+	_ ng.ControllerInitializer = (*Docs)(nil)
+
+	//go:embed ui/index.html
+	indexHTML string
+
+	indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+)
+
+type (
+	// Options configures docs controller
+	Options struct {
+		// Path is base path of the UI, default "/docs"
+		Path string
+
+		// Title of the UI, default is title of the document
+		Title string
+
+		// DocOptions configure document generated from the app
+		DocOptions []ngopenapi.DocOption
+
+		// Document is served instead of generating one, e.g. for spec-first APIs
+		Document *ngopenapi.Document
+	}
+
+	// Docs serves GET Path (UI), Path/openapi.json and Path/openapi.yaml
+	Docs struct {
+		app    ng.App
+		opts   Options
+		ngOpts []ng.Option
+
+		once sync.Once
+		doc  *ngopenapi.Document
+		json []byte
+		yaml []byte
+		err  error
+	}
+)
+
+/*
+Controller create docs controller of app, ng options apply to the controller.
+App guards protect the docs like other routes, use ng.SkipAllGuards for public docs.
+The document is generated on first request, once the app is built. Docs routes are hidden from it.
+
+	app.AddController(ngdocs.Controller(app, ngdocs.Options{
+		DocOptions: []ngopenapi.DocOption{
+			ngopenapi.WithInfo(ngopenapi.Info{Title: "Users", Version: "1.2.0"}),
+		},
+	}, ng.SkipAllGuards()))
+*/
+func Controller(app ng.App, opts Options, ngOpts ...ng.Option) *Docs {
+	if opts.Path == "" {
+		opts.Path = "/docs"
+	}
+	return &Docs{app: app, opts: opts, ngOpts: ngOpts}
+}
+
+// InitializeController mounts routes under Options.Path
+func (d *Docs) InitializeController() ng.Controller {
+	opts := []ng.Option{
+		ng.WithPrefix(d.opts.Path),
+		ngopenapi.WithHidden(),
+	}
+	return ng.NewController(append(opts, d.ngOpts...)...)
+}
+
+// Document return served document, generated once
+func (d *Docs) Document() (*ngopenapi.Document, error) {
+	d.load()
+	return d.doc, d.err
+}
+
+func (d *Docs) load() {
+	d.once.Do(func() {
+		d.doc = d.opts.Document
+		if d.doc == nil {
+			d.doc = ngopenapi.Generate(d.app, d.opts.DocOptions...)
+		}
+		if d.json, d.err = d.doc.JSON(); d.err != nil {
+			return
+		}
+		d.yaml, d.err = d.doc.YAML()
+	})
+}
+
+// UI serves the embedded document viewer
+func (d *Docs) UI() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/", ng.WithHandler(func(ctx context.Context) error {
+		doc, err := d.Document()
+		if err != nil {
+			return err
+		}
+
+		// document urls are relative to the request path, so any prefix works
+		base := strings.TrimSuffix(ng.MustLoad[*http.Request](ctx).URL.Path, "/")
+		var buf bytes.Buffer
+		err = indexTemplate.Execute(&buf, map[string]string{
+			"Title":   cmp.Or(d.opts.Title, doc.Info.Title),
+			"SpecURL": base + "/openapi.json",
+			"YAMLURL": base + "/openapi.yaml",
+		})
+		if err != nil {
+			return err
+		}
+		return ng.Respond(ctx, raw(buf.Bytes(), ContentTypeHTML))
+	}))
+}
+
+// JSON serves the document as JSON
+func (d *Docs) JSON() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/openapi.json", ng.WithHandler(func(ctx context.Context) error {
+		if d.load(); d.err != nil {
+			return d.err
+		}
+		return ng.Respond(ctx, raw(d.json, ContentTypeJSON))
+	}))
+}
+
+// YAML serves the document as YAML
+func (d *Docs) YAML() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/openapi.yaml", ng.WithHandler(func(ctx context.Context) error {
+		if d.load(); d.err != nil {
+			return d.err
+		}
+		return ng.Respond(ctx, raw(d.yaml, ContentTypeYAML))
+	}))
+}
+
+func raw(body []byte, contentType string) *nghttp.RawResponse {
+	resp := nghttp.NewRawResponse(http.StatusOK, body)
+	resp.Headers().Set("Content-Type", contentType)
+	resp.Headers().Set("Cache-Control", "no-cache")
+	return resp
+}
@@ -0,0 +1,152 @@
+package ngopenapi
+
+import (
+	"encoding/json"
+)
+
+// Version is OpenAPI version of generated documents
+const Version = "3.1.0"
+
+type (
+	// Document is an OpenAPI 3.1 document
+	Document struct {
+		OpenAPI    string                `json:"openapi"`
+		Info       Info                  `json:"info"`
+		Servers    []Server              `json:"servers,omitempty"`
+		Tags       []Tag                 `json:"tags,omitempty"`
+		Paths      map[string]PathItem   `json:"paths"`
+		Components Components            `json:"components,omitzero"`
+		Security   []SecurityRequirement `json:"security,omitempty"`
+
+		// ErrorCodes lists error codes returned by operations, see nghttp.Codes
+		ErrorCodes []ErrorCode `json:"x-error-codes,omitempty"`
+	}
+
+	// Info describes the API
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Server is a base URL of the API
+	Server struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Tag groups operations
+	Tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem holds operations of a path by lower case method, e.g. "get"
+	PathItem map[string]*Operation
+
+	// Operation describes a single route
+	Operation struct {
+		OperationID string                `json:"operationId,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Description string                `json:"description,omitempty"`
+		Tags        []string              `json:"tags,omitempty"`
+		Deprecated  bool                  `json:"deprecated,omitempty"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses"`
+		Security    []SecurityRequirement `json:"security,omitempty"`
+
+		// ErrorCodes lists error codes the operation may return
+		ErrorCodes []string `json:"x-error-codes,omitempty"`
+	}
+
+	// Parameter is a path, query or header parameter
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	// RequestBody describes request body by media type
+	RequestBody struct {
+		Required bool                 `json:"required,omitempty"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	// Response describes response by media type
+	Response struct {
+		Description string               `json:"description"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType holds schema of a media type
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	// Components holds reusable schemas and security schemes
+	Components struct {
+		Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme describes an authentication method, e.g. {Type: "http", Scheme: "bearer"}
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		Name         string `json:"name,omitempty"`
+		In           string `json:"in,omitempty"`
+		Description  string `json:"description,omitempty"`
+	}
+
+	// SecurityRequirement maps security scheme names to required scopes
+	SecurityRequirement map[string][]string
+
+	// ErrorCode documents an error code, see nghttp.CodeSpec
+	ErrorCode struct {
+		Code        string `json:"code"`
+		Status      int    `json:"status"`
+		Retryable   bool   `json:"retryable,omitempty"`
+		Message     string `json:"message"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Schema is a JSON Schema of OpenAPI 3.1
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Enum                 []any              `json:"enum,omitempty"`
+		Default              any                `json:"default,omitempty"`
+		Example              any                `json:"example,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+	}
+)
+
+// JSON return indented JSON document
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML return YAML document
+func (d *Document) YAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(data)
+}
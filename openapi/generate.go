@@ -0,0 +1,349 @@
+package ngopenapi
+
+import (
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// ContentType is media type of documented request and response bodies
+const ContentType = "application/json"
+
+type (
+	// DocOption configures generated document
+	DocOption func(*generator)
+
+	generator struct {
+		doc           *Document
+		schemas       *schemaBuilder
+		defaultErrors []nghttp.Code
+		errorCodes    map[nghttp.Code]bool
+	}
+)
+
+// WithInfo sets title, version and description of the API, "API" and "1.0.0" by default
+func WithInfo(info Info) DocOption {
+	return func(g *generator) {
+		g.doc.Info = info
+	}
+}
+
+// WithServer adds base URL of the API
+func WithServer(url, description string) DocOption {
+	return func(g *generator) {
+		g.doc.Servers = append(g.doc.Servers, Server{URL: url, Description: description})
+	}
+}
+
+// WithSecurityScheme registers scheme required by WithSecurity, e.g. {Type: "http", Scheme: "bearer"}
+func WithSecurityScheme(name string, scheme SecurityScheme) DocOption {
+	return func(g *generator) {
+		if g.doc.Components.SecuritySchemes == nil {
+			g.doc.Components.SecuritySchemes = map[string]SecurityScheme{}
+		}
+		g.doc.Components.SecuritySchemes[name] = scheme
+	}
+}
+
+// WithDefaultErrors documents codes every operation may return, e.g. nghttp.CodeInternal
+func WithDefaultErrors(codes ...nghttp.Code) DocOption {
+	return func(g *generator) {
+		g.defaultErrors = append(g.defaultErrors, codes...)
+	}
+}
+
+/*
+Generate builds OpenAPI 3.1 document of built app routes.
+
+Paths and methods are read from composed routes. Request parameters and bodies come from
+ng.WithTypedHandler types: fields tagged `path`, `query` and `header` are parameters, other fields
+are the JSON body. Responses are documented with the route envelope, see ng.RouteEnvelope,
+and errors with codes of WithErrors. Schemas follow `json` tags and read `doc`, `example`,
+`default`, `enum`, `format` and `validate` tags.
+
+	app.Build()
+
+	doc := ngopenapi.Generate(app,
+		ngopenapi.WithInfo(ngopenapi.Info{Title: "Users", Version: "1.2.0"}),
+		ngopenapi.WithSecurityScheme("bearer", ngopenapi.SecurityScheme{Type: "http", Scheme: "bearer"}),
+	)
+	yaml, err := doc.YAML()
+*/
+func Generate(app ng.App, opts ...DocOption) *Document {
+	g := &generator{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    Info{Title: "API", Version: "1.0.0"},
+			Paths:   map[string]PathItem{},
+		},
+		schemas:    newSchemaBuilder(),
+		errorCodes: map[nghttp.Code]bool{},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	tags := map[string]bool{}
+	for _, r := range app.Routes() {
+		method := strings.ToLower(r.Method())
+		if Hidden(r) || !slices.Contains(methods, method) {
+			continue
+		}
+
+		path, params := Path(r)
+		op := g.operation(r, path, params)
+		if g.doc.Paths[path] == nil {
+			g.doc.Paths[path] = PathItem{}
+		}
+		g.doc.Paths[path][method] = op
+
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(tags)) {
+		g.doc.Tags = append(g.doc.Tags, Tag{Name: tag})
+	}
+	for _, code := range slices.Sorted(maps.Keys(g.errorCodes)) {
+		spec := codeSpec(code)
+		g.doc.ErrorCodes = append(g.doc.ErrorCodes, ErrorCode{
+			Code:        string(code),
+			Status:      spec.Status,
+			Retryable:   spec.Retryable,
+			Message:     spec.DefaultMessage,
+			Description: spec.Description,
+		})
+	}
+	if len(g.schemas.schemas) > 0 {
+		g.doc.Components.Schemas = g.schemas.schemas
+	}
+	return g.doc
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func (g *generator) operation(r ng.Route, path string, pathParams []string) *Operation {
+	op := &Operation{Responses: map[string]*Response{}}
+	op.Summary, _ = metadata[string](r, summaryKey{})
+	op.Description, _ = metadata[string](r, descriptionKey{})
+	op.Tags, _ = metadata[[]string](r, tagsKey{})
+	op.Deprecated, _ = metadata[bool](r, deprecatedKey{})
+	op.Security, _ = metadata[[]SecurityRequirement](r, securityKey{})
+
+	op.OperationID = OperationID(r)
+
+	types, typed := ng.RouteHandlerTypes(r)
+	codes := slices.Clone(g.defaultErrors)
+
+	if typed {
+		op.Parameters = g.parameters(types.Request)
+		if body := g.requestBody(r.Method(), types.Request); body != nil {
+			op.RequestBody = body
+		}
+		if op.RequestBody != nil || len(op.Parameters) > 0 {
+			codes = append(codes, nghttp.CodeInvalidArgument)
+		}
+	}
+	for _, name := range pathParams {
+		if !slices.ContainsFunc(op.Parameters, func(p Parameter) bool { return p.In == "path" && p.Name == name }) {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if len(op.Security) > 0 {
+		codes = append(codes, nghttp.CodeUnauthenticated)
+	}
+
+	envelope := ng.RouteEnvelope(r)
+	op.Responses[strconv.Itoa(http.StatusOK)] = g.successResponse(envelope, types.Response)
+
+	codes = append(codes, routeErrors(r)...)
+	slices.Sort(codes)
+	codes = slices.Compact(codes)
+	g.errorResponses(op, envelope, codes)
+	return op
+}
+
+// parameters return path, query and header fields of request type
+func (g *generator) parameters(t reflect.Type) []Parameter {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	params := []Parameter{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			params = append(params, g.parameters(sf.Type)...)
+			continue
+		}
+
+		name, in := paramSource(sf)
+		if name == "" || !sf.IsExported() {
+			continue
+		}
+
+		schema := g.schemas.typeSchema(sf.Type)
+		required := applyTags(schema, sf)
+		params = append(params, Parameter{
+			Name:        name,
+			In:          in,
+			Description: schema.Description,
+			Required:    required || in == "path",
+			Schema:      schema,
+		})
+		schema.Description = ""
+	}
+	return params
+}
+
+// requestBody documents body fields of request type, none for GET, HEAD and DELETE
+func (g *generator) requestBody(method string, t reflect.Type) *RequestBody {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return nil
+	}
+	if !hasBodyFields(t) {
+		return nil
+	}
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{ContentType: {Schema: g.schemas.typeSchema(t)}},
+	}
+}
+
+func hasBodyFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return t.Kind() != reflect.Interface
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && hasBodyFields(sf.Type) {
+			return true
+		}
+		if sf.IsExported() && !requestParam(sf) && sf.Tag.Get("json") != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// successResponse documents body written by envelope for response type, data is unknown for untyped routes
+func (g *generator) successResponse(envelope nghttp.Envelope, t reflect.Type) *Response {
+	var data any
+	if t != nil && !t.Implements(reflect.TypeFor[nghttp.HTTPResponse]()) {
+		data = reflect.Zero(t).Interface()
+	}
+
+	var schema *Schema
+	body := envelope.Wrap(nghttp.NewResponse(data))
+	switch {
+	case body == nil:
+		schema = &Schema{}
+	case t != nil && reflect.TypeOf(body) == t:
+		// bare data, e.g. nghttp.DataEnvelope
+		schema = g.schemas.typeSchema(t)
+	default:
+		schema = g.schemas.valueSchema(reflect.ValueOf(body))
+	}
+
+	return &Response{
+		Description: http.StatusText(http.StatusOK),
+		Content:     map[string]MediaType{ContentType: {Schema: schema}},
+	}
+}
+
+// errorResponses groups codes by status, bodies are documented with the envelope error body type
+func (g *generator) errorResponses(op *Operation, envelope nghttp.Envelope, codes []nghttp.Code) {
+	byStatus := map[int][]string{}
+	for _, code := range codes {
+		g.errorCodes[code] = true
+		status := codeSpec(code).Status
+		byStatus[status] = append(byStatus[status], string(code))
+		op.ErrorCodes = append(op.ErrorCodes, string(code))
+	}
+
+	for status, codes := range byStatus {
+		body := envelope.Wrap(nghttp.New(nghttp.Code(codes[0])))
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status) + ": " + strings.Join(codes, ", "),
+			Content:     map[string]MediaType{ContentType: {Schema: g.schemas.typeSchema(reflect.TypeOf(body))}},
+		}
+	}
+}
+
+// codeSpec return registered spec, unknown codes are internal errors like nghttp.New creates
+func codeSpec(code nghttp.Code) nghttp.CodeSpec {
+	if spec, ok := nghttp.LookupCode(code); ok {
+		return spec
+	}
+	return nghttp.CodeSpec{Code: code, Status: http.StatusInternalServerError, DefaultMessage: string(code)}
+}
+
+// Hidden reports whether route is excluded from documents, see WithHidden
+func Hidden(r ng.Route) bool {
+	hidden, _ := metadata[bool](r, hiddenKey{})
+	return hidden
+}
+
+// OperationID return id set with WithOperationID, route name, or id derived from method and path, e.g. getUsersId
+func OperationID(r ng.Route) string {
+	if id, _ := metadata[string](r, operationIDKey{}); id != "" {
+		return id
+	}
+	if r.Name() != "" {
+		return r.Name()
+	}
+
+	path, _ := Path(r)
+	id := strings.ToLower(r.Method())
+	for _, part := range strings.FieldsFunc(path, func(c rune) bool { return c == '/' || c == '{' || c == '}' || c == '-' || c == '_' || c == '.' }) {
+		id += exportName(part)
+	}
+	return id
+}
+
+// Path return OpenAPI path template of route and its parameters,
+// "{id}", ":id", "*path" and "{path...}" are parameters
+func Path(r ng.Route) (string, []string) {
+	return pathTemplate(r.Path())
+}
+
+func pathTemplate(path string) (string, []string) {
+	if path == "" {
+		return "/", nil
+	}
+
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		var name string
+		switch {
+		case seg == "{$}":
+			segments[i] = ""
+			continue
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name = strings.TrimSuffix(seg[1:len(seg)-1], "...")
+		case strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") && len(seg) > 1:
+			name = seg[1:]
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,98 @@
+package ngopenapi
+
+// Route metadata documenting operations, set on app, controller or route like other ng options
+
+import (
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type (
+	summaryKey     struct{}
+	descriptionKey struct{}
+	tagsKey        struct{}
+	deprecatedKey  struct{}
+	securityKey    struct{}
+	operationIDKey struct{}
+	hiddenKey      struct{}
+
+	// errorCodeKey is stored per code, so codes of app, controller and route add up
+	errorCodeKey struct{ code nghttp.Code }
+)
+
+// WithSummary sets short summary of operation
+func WithSummary(summary string) ng.Option {
+	return ng.WithMetadata(summaryKey{}, summary)
+}
+
+// WithDescription sets description of operation, CommonMark is allowed
+func WithDescription(description string) ng.Option {
+	return ng.WithMetadata(descriptionKey{}, description)
+}
+
+// WithTags groups operations, e.g. set once on a controller
+func WithTags(tags ...string) ng.Option {
+	return ng.WithMetadata(tagsKey{}, tags)
+}
+
+// WithDeprecated marks operation as deprecated
+func WithDeprecated() ng.Option {
+	return ng.WithMetadata(deprecatedKey{}, true)
+}
+
+// WithSecurity requires security scheme registered with WithSecurityScheme, scopes are for oauth2 and openIdConnect
+func WithSecurity(scheme string, scopes ...string) ng.Option {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return ng.WithMetadata(securityKey{}, []SecurityRequirement{{scheme: scopes}})
+}
+
+// WithOperationID replaces operation id, route name by default
+func WithOperationID(id string) ng.Option {
+	return ng.WithMetadata(operationIDKey{}, id)
+}
+
+// WithHidden excludes route from generated documents
+func WithHidden() ng.Option {
+	return ng.WithMetadata(hiddenKey{}, true)
+}
+
+/*
+WithErrors documents error codes returned by operation, codes of app, controller and route add up.
+Status and message of each code are read from nghttp.LookupCode.
+
+	ng.NewRoute(http.MethodGet, "/users/{id}",
+		ng.WithTypedHandler(getUser),
+		ngopenapi.WithErrors(nghttp.CodeNotFound, CodeUserSuspended),
+	)
+*/
+func WithErrors(codes ...nghttp.Code) ng.Option {
+	pairs := []any{}
+	for _, code := range codes {
+		pairs = append(pairs, errorCodeKey{code}, true)
+	}
+	return ng.WithMetadata(pairs...)
+}
+
+func metadata[T any](r ng.Route, key any) (T, bool) {
+	val, ok := r.Core().Metadata(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	v, ok := val.(T)
+	return v, ok
+}
+
+// routeErrors return codes documented with WithErrors on route, its controller and app
+func routeErrors(r ng.Route) []nghttp.Code {
+	var codes []nghttp.Code
+	r.Core().RangeMetadata(func(key, _ any) bool {
+		if k, ok := key.(errorCodeKey); ok {
+			codes = append(codes, k.code)
+		}
+		return true
+	})
+	return codes
+}
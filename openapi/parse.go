@@ -0,0 +1,593 @@
+package ngopenapi
+
+// Document input: JSON or YAML documents, e.g. specs of spec-first APIs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+Parse reads JSON or YAML document. YAML support covers documents written by hand or by other tools:
+block and flow collections, plain, quoted and block scalars and comments. Anchors, aliases and tags are rejected.
+
+	data, _ := os.ReadFile("openapi.yaml")
+	doc, err := ngopenapi.Parse(data)
+*/
+func Parse(data []byte) (*Document, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		var err error
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("ngopenapi: %w", err)
+		}
+	}
+
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("ngopenapi: %w", err)
+	}
+	return doc, nil
+}
+
+// Resolve follows $ref of s to components of d, nil when a reference is missing
+func (d *Document) Resolve(s *Schema) *Schema {
+	for hops := 0; s != nil && s.Ref != ""; hops++ {
+		name, ok := strings.CutPrefix(s.Ref, schemaRefPrefix)
+		if !ok || hops > len(d.Components.Schemas) {
+			return nil
+		}
+		s = d.Components.Schemas[name]
+	}
+	return s
+}
+
+func yamlToJSON(data []byte) ([]byte, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	r := &yamlReader{lines: strings.Split(text, "\n")}
+
+	root, err := r.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	if r.skip(); r.pos < len(r.lines) {
+		return nil, r.errorf("unexpected content")
+	}
+
+	// versions like 1.0 are numbers in YAML, info.version is a string
+	if info := root.get("info"); info != nil {
+		if version := info.get("version"); version != nil {
+			if n, ok := version.scalar.(json.Number); ok {
+				version.scalar = n.String()
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writeJSON(&buf, root)
+	return buf.Bytes(), nil
+}
+
+// get return value of key in object node
+func (n *node) get(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+	return nil
+}
+
+func writeJSON(buf *bytes.Buffer, n *node) {
+	switch {
+	case n.object:
+		buf.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			b, _ := json.Marshal(key)
+			buf.Write(b)
+			buf.WriteByte(':')
+			writeJSON(buf, n.values[i])
+		}
+		buf.WriteByte('}')
+	case n.array:
+		buf.WriteByte('[')
+		for i, value := range n.values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, value)
+		}
+		buf.WriteByte(']')
+	default:
+		if v, ok := n.scalar.(json.Number); ok {
+			buf.WriteString(v.String())
+			return
+		}
+		b, _ := json.Marshal(n.scalar)
+		buf.Write(b)
+	}
+}
+
+// yamlReader parses block YAML line by line, nested nodes are more indented
+type yamlReader struct {
+	lines []string
+	pos   int
+}
+
+func (r *yamlReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml line %d: %s", r.pos+1, fmt.Sprintf(format, args...))
+}
+
+// skip moves to next line with content, blank lines, comments and document markers are skipped
+func (r *yamlReader) skip() {
+	for ; r.pos < len(r.lines); r.pos++ {
+		text := strings.TrimSpace(r.lines[r.pos])
+		if text != "" && text[0] != '#' && text != "---" && text != "..." && !strings.HasPrefix(text, "%") {
+			return
+		}
+	}
+}
+
+// peek return indent and content of next line, ok is false at end of input
+func (r *yamlReader) peek() (indent int, text string, ok bool) {
+	if r.skip(); r.pos >= len(r.lines) {
+		return 0, "", false
+	}
+	line := r.lines[r.pos]
+	text = strings.TrimLeft(line, " ")
+	return len(line) - len(text), strings.TrimSpace(stripComment(text)), true
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseNode parses node starting at next line indented by at least minIndent
+func (r *yamlReader) parseNode(minIndent int) (*node, error) {
+	indent, text, ok := r.peek()
+	if !ok || indent < minIndent {
+		return &node{}, nil
+	}
+
+	switch {
+	case isSequenceItem(text):
+		return r.parseSequence(indent)
+	case isMappingLine(text):
+		return r.parseMapping(indent)
+	}
+	r.pos++
+	return r.parseValue(text, indent-1, false)
+}
+
+func (r *yamlReader) parseMapping(indent int) (*node, error) {
+	n := &node{object: true}
+	for {
+		lineIndent, text, ok := r.peek()
+		if !ok || lineIndent < indent || isSequenceItem(text) {
+			return n, nil
+		}
+		if lineIndent > indent {
+			return nil, r.errorf("bad indentation")
+		}
+
+		key, rest, ok := splitKey(text)
+		if !ok {
+			return nil, r.errorf("expected mapping key")
+		}
+		r.pos++
+		value, err := r.parseValue(rest, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		n.keys = append(n.keys, key)
+		n.values = append(n.values, value)
+	}
+}
+
+func (r *yamlReader) parseSequence(indent int) (*node, error) {
+	n := &node{array: true}
+	for {
+		lineIndent, text, ok := r.peek()
+		if !ok || lineIndent != indent || !isSequenceItem(text) {
+			return n, nil
+		}
+
+		var (
+			value *node
+			err   error
+			rest  = strings.TrimSpace(text[1:])
+		)
+		switch {
+		case rest == "":
+			r.pos++
+			value, err = r.parseNode(indent + 1)
+		case isMappingLine(rest) || isSequenceItem(rest):
+			// "- key: value" starts a mapping indented past the dash
+			line := r.lines[r.pos]
+			r.lines[r.pos] = line[:indent] + " " + line[indent+1:]
+			value, err = r.parseNode(indent + 1)
+		default:
+			r.pos++
+			value, err = r.parseValue(rest, indent, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.values = append(n.values, value)
+	}
+}
+
+// parseValue parses value following a key or dash on the line before r.pos, nested lines are indented past indent
+func (r *yamlReader) parseValue(rest string, indent int, inMapping bool) (*node, error) {
+	switch {
+	case rest == "":
+		lineIndent, text, ok := r.peek()
+		switch {
+		case !ok:
+			return &node{}, nil
+		case lineIndent > indent:
+			return r.parseNode(indent + 1)
+		case inMapping && lineIndent == indent && isSequenceItem(text):
+			// sequences may start at the indent of their key
+			return r.parseSequence(indent)
+		}
+		return &node{}, nil
+	case rest[0] == '|' || rest[0] == '>':
+		return r.parseBlockScalar(rest, indent)
+	case rest[0] == '&' || rest[0] == '*' || rest[0] == '!':
+		return nil, r.errorf("anchors, aliases and tags are not supported")
+	case rest[0] == '[' || rest[0] == '{':
+		for !flowClosed(rest) {
+			if _, ok := r.continuation(indent); !ok {
+				return nil, r.errorf("unterminated flow collection")
+			}
+			rest += " " + strings.TrimSpace(stripComment(r.lines[r.pos]))
+			r.pos++
+		}
+		p := &flowParser{s: rest}
+		n, err := p.parse()
+		if err != nil {
+			return nil, r.errorf("%v", err)
+		}
+		return n, nil
+	}
+
+	// plain and quoted scalars may continue on more indented lines
+	for {
+		text, ok := r.continuation(indent)
+		if !ok || (rest[0] != '"' && rest[0] != '\'' && isMappingLine(text)) {
+			break
+		}
+		rest += " " + text
+		r.pos++
+	}
+	value, err := parseYAMLScalar(rest)
+	if err != nil {
+		return nil, r.errorf("%v", err)
+	}
+	return &node{scalar: value}, nil
+}
+
+// continuation return content of next line when it is indented past indent
+func (r *yamlReader) continuation(indent int) (string, bool) {
+	lineIndent, text, ok := r.peek()
+	return text, ok && lineIndent > indent
+}
+
+// parseBlockScalar parses literal (|) and folded (>) scalars with optional chomping indicator
+func (r *yamlReader) parseBlockScalar(header string, indent int) (*node, error) {
+	folded := header[0] == '>'
+	chomp := strings.TrimLeft(header[1:], "0123456789")
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, r.errorf("bad block scalar header %q", header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for ; r.pos < len(r.lines); r.pos++ {
+		line := r.lines[r.pos]
+		text := strings.TrimLeft(line, " ")
+		if text == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lineIndent := len(line) - len(text)
+		if lineIndent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = lineIndent
+		}
+		lines = append(lines, line[min(blockIndent, lineIndent):])
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		switch {
+		case i == 0:
+		case !folded || line == "" || lines[i-1] == "":
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+	}
+
+	text := b.String()
+	switch {
+	case len(lines) == 0 || chomp == "-":
+	case chomp == "+":
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		text += "\n"
+	}
+	return &node{scalar: text}, nil
+}
+
+// stripComment removes "# comment" outside of quotes
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func isMappingLine(text string) bool {
+	_, _, ok := splitKey(text)
+	return ok
+}
+
+// splitKey splits "key: value" into key and value
+func splitKey(text string) (key, rest string, ok bool) {
+	if text == "" || text[0] == '[' || text[0] == '{' || isSequenceItem(text) {
+		return "", "", false
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		end := quoteEnd(text)
+		if end < 0 {
+			return "", "", false
+		}
+		after := strings.TrimLeft(text[end+1:], " ")
+		if !strings.HasPrefix(after, ":") || len(after) > 1 && after[1] != ' ' {
+			return "", "", false
+		}
+		value, err := parseYAMLScalar(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return value.(string), strings.TrimSpace(after[1:]), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ' || text[i+1] == '\t') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// quoteEnd return index of quote closing the quoted scalar at start of text, -1 when unterminated
+func quoteEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// flowClosed reports whether brackets of flow collection are balanced
+func flowClosed(text string) bool {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			end := quoteEnd(text[i:])
+			if end < 0 {
+				return false
+			}
+			i += end
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+var (
+	jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+	yamlNumber = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// parseYAMLScalar resolves quoted or plain scalar to string, json.Number, bool or nil
+func parseYAMLScalar(text string) (any, error) {
+	switch {
+	case text == "":
+		return nil, nil
+	case text[0] == '"':
+		var s string
+		if quoteEnd(text) != len(text)-1 || json.Unmarshal([]byte(text), &s) != nil {
+			return nil, fmt.Errorf("bad double quoted scalar %s", text)
+		}
+		return s, nil
+	case text[0] == '\'':
+		if quoteEnd(text) != len(text)-1 {
+			return nil, fmt.Errorf("bad single quoted scalar %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if jsonNumber.MatchString(text) {
+		return json.Number(text), nil
+	}
+	if yamlNumber.MatchString(text) {
+		f, _ := strconv.ParseFloat(text, 64)
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+	return text, nil
+}
+
+// flowParser parses flow collections like [a, "b"] and {a: 1, b: [c]}
+type flowParser struct {
+	s string
+	i int
+}
+
+func (p *flowParser) space() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+func (p *flowParser) parse() (*node, error) {
+	n, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if p.space(); p.i < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q after flow collection", p.s[p.i:])
+	}
+	return n, nil
+}
+
+func (p *flowParser) value() (*node, error) {
+	p.space()
+	if p.i >= len(p.s) {
+		return nil, fmt.Errorf("unterminated flow collection")
+	}
+
+	switch p.s[p.i] {
+	case '[':
+		p.i++
+		n := &node{array: true}
+		for {
+			if p.space(); p.i < len(p.s) && p.s[p.i] == ']' {
+				p.i++
+				return n, nil
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, value)
+			if err := p.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		p.i++
+		n := &node{object: true}
+		for {
+			if p.space(); p.i < len(p.s) && p.s[p.i] == '}' {
+				p.i++
+				return n, nil
+			}
+			key, err := p.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			if p.space(); p.i >= len(p.s) || p.s[p.i] != ':' {
+				return nil, fmt.Errorf("expected ':' after key %v", key)
+			}
+			p.i++
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, fmt.Sprint(key))
+			n.values = append(n.values, value)
+			if err := p.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	value, err := p.scalar(false)
+	if err != nil {
+		return nil, err
+	}
+	return &node{scalar: value}, nil
+}
+
+// separator consumes "," or peeks closing bracket
+func (p *flowParser) separator(closing byte) error {
+	p.space()
+	switch {
+	case p.i < len(p.s) && p.s[p.i] == ',':
+		p.i++
+		return nil
+	case p.i < len(p.s) && p.s[p.i] == closing:
+		return nil
+	}
+	return fmt.Errorf("expected ',' or %q in flow collection", closing)
+}
+
+func (p *flowParser) scalar(key bool) (any, error) {
+	p.space()
+	start := p.i
+	if p.i < len(p.s) && (p.s[p.i] == '"' || p.s[p.i] == '\'') {
+		end := quoteEnd(p.s[p.i:])
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted scalar")
+		}
+		p.i += end + 1
+		return parseYAMLScalar(p.s[start:p.i])
+	}
+
+	for p.i < len(p.s) && !strings.ContainsRune(",[]{}", rune(p.s[p.i])) {
+		if p.s[p.i] == ':' && (key || p.i+1 == len(p.s) || p.s[p.i+1] == ' ') {
+			break
+		}
+		p.i++
+	}
+	text := strings.TrimSpace(p.s[start:p.i])
+	if key {
+		return text, nil
+	}
+	return parseYAMLScalar(text)
+}
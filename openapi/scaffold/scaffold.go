@@ -0,0 +1,823 @@
+// Package ngscaffold generates DTOs, ng controllers and service interfaces from an OpenAPI document,
+// for APIs designed spec-first. Generated files end with _gen.go and are replaced on every run,
+// service implementations are created once and left to the developer.
+package ngscaffold
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+// Generated files, they are replaced on every run
+const (
+	DTOsFile        = "dtos_gen.go"
+	ControllersFile = "controllers_gen.go"
+	ServicesFile    = "services_gen.go"
+)
+
+// Config configures generated package
+type Config struct {
+	// Package is package name of generated files, default "api"
+	Package string
+}
+
+/*
+Generate return Go files of doc by name:
+
+  - dtos_gen.go: structs of component schemas and request types of operations
+  - controllers_gen.go: a controller per tag, routes call the service with typed handlers
+  - services_gen.go: a service interface per tag
+  - <tag>_service.go: service implementation returning UNIMPLEMENTED, see WriteFiles
+
+Operations are grouped by their first tag, paths share the longest common prefix of their controller.
+Success response schemas wrapping a "data" member, like nghttp.DefaultEnvelope writes, are unwrapped.
+*/
+func Generate(doc *ngopenapi.Document, cfg Config) (map[string][]byte, error) {
+	g := &generator{doc: doc, cfg: cfg, declared: map[string]bool{}}
+	if g.cfg.Package == "" {
+		g.cfg.Package = "api"
+	}
+
+	controllers, err := g.controllers()
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := g.newFile()
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		g.declare(dtos, goName(name), doc.Components.Schemas[name])
+	}
+	for _, c := range controllers {
+		for _, op := range c.operations {
+			if err := g.operationTypes(dtos, op); err != nil {
+				return nil, fmt.Errorf("ngscaffold: %s %s: %w", op.method, op.path, err)
+			}
+		}
+	}
+
+	files := map[string]*file{DTOsFile: dtos, ControllersFile: g.newFile(), ServicesFile: g.newFile()}
+	for _, c := range controllers {
+		g.writeController(files[ControllersFile], c)
+		g.writeService(files[ServicesFile], c)
+
+		stub := g.newFile()
+		g.writeServiceStub(stub, c)
+		files[snakeName(c.tag)+"_service.go"] = stub
+	}
+
+	out := map[string][]byte{}
+	for name, f := range files {
+		src, err := f.source(g.cfg.Package, strings.HasSuffix(name, "_gen.go"))
+		if err != nil {
+			return nil, fmt.Errorf("ngscaffold: %s: %w", name, err)
+		}
+		out[name] = src
+	}
+	return out, nil
+}
+
+/*
+WriteFiles writes Generate output to dir. Files ending with _gen.go are replaced when changed,
+other files are only created when missing, so hand-written services survive spec changes.
+New operations show up as missing methods of the service implementation.
+
+	doc, _ := ngopenapi.Parse(data)
+	err := ngscaffold.WriteFiles("internal/api", doc, ngscaffold.Config{Package: "api"})
+*/
+func WriteFiles(dir string, doc *ngopenapi.Document, cfg Config) error {
+	files, err := Generate(doc, cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		filename := filepath.Join(dir, name)
+		old, err := os.ReadFile(filename)
+		switch {
+		case err == nil && (!strings.HasSuffix(name, "_gen.go") || bytes.Equal(old, files[name])):
+			continue
+		case err != nil && !os.IsNotExist(err):
+			return err
+		}
+		if err := os.WriteFile(filename, files[name], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type (
+	generator struct {
+		doc      *ngopenapi.Document
+		cfg      Config
+		declared map[string]bool
+	}
+
+	controller struct {
+		tag, name, prefix string
+		operations        []*operation
+	}
+
+	operation struct {
+		method, path string
+		id, name     string
+		op           *ngopenapi.Operation
+
+		// request and response are Go types of the typed handler
+		request, response string
+	}
+
+	// file collects declarations and imports of a generated file
+	file struct {
+		body    bytes.Buffer
+		imports map[string]bool
+	}
+)
+
+func (g *generator) newFile() *file {
+	return &file{imports: map[string]bool{}}
+}
+
+// importNames of packages used by generated code, paths without name use their base name
+var importNames = map[string]string{
+	"github.com/foxie-io/ng/http":    "nghttp",
+	"github.com/foxie-io/ng/openapi": "ngopenapi",
+}
+
+func (f *file) source(pkg string, generated bool) ([]byte, error) {
+	var src bytes.Buffer
+	if generated {
+		src.WriteString("// Code generated by ngscaffold. DO NOT EDIT.\n\n")
+	}
+	fmt.Fprintf(&src, "package %s\n", pkg)
+
+	if len(f.imports) > 0 {
+		src.WriteString("\nimport (\n")
+		// standard packages come first
+		paths := slices.SortedFunc(maps.Keys(f.imports), func(a, b string) int {
+			return cmp.Or(-cmp.Compare(boolInt(isStd(a)), boolInt(isStd(b))), cmp.Compare(a, b))
+		})
+		for i, p := range paths {
+			if i > 0 && isStd(paths[i-1]) != isStd(p) {
+				src.WriteString("\n")
+			}
+			if name, ok := importNames[p]; ok {
+				fmt.Fprintf(&src, "\t%s %q\n", name, p)
+			} else {
+				fmt.Fprintf(&src, "\t%q\n", p)
+			}
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(f.body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func isStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func (f *file) printf(format string, args ...any) {
+	fmt.Fprintf(&f.body, format, args...)
+}
+
+// defaultTag groups operations without tags
+const defaultTag = "default"
+
+var methodOrder = []string{"get", "head", "post", "put", "patch", "delete", "options", "trace"}
+
+// controllers groups operations by first tag, tags of the document come first
+func (g *generator) controllers() ([]*controller, error) {
+	byTag := map[string]*controller{}
+	var order []string
+	for _, tag := range g.doc.Tags {
+		order = append(order, tag.Name)
+	}
+
+	methods := map[string]string{}
+	for _, path := range slices.Sorted(maps.Keys(g.doc.Paths)) {
+		for _, method := range methodOrder {
+			op := g.doc.Paths[path][method]
+			if op == nil {
+				continue
+			}
+
+			tag := defaultTag
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			c := byTag[tag]
+			if c == nil {
+				c = &controller{tag: tag, name: goName(tag)}
+				byTag[tag] = c
+				if !slices.Contains(order, tag) {
+					order = append(order, tag)
+				}
+			}
+
+			o := &operation{method: strings.ToUpper(method), path: path, id: op.OperationID, op: op}
+			o.name = goName(cmp.Or(op.OperationID, method+" "+path))
+			if other, taken := methods[o.name]; taken {
+				return nil, fmt.Errorf("ngscaffold: %s %s and %s are both named %s, set operationId", o.method, path, other, o.name)
+			}
+			if o.name == "Service" || o.name == "InitializeController" {
+				return nil, fmt.Errorf("ngscaffold: %s %s: method name %s is reserved, set operationId", o.method, path, o.name)
+			}
+			methods[o.name] = o.method + " " + path
+			c.operations = append(c.operations, o)
+		}
+	}
+
+	var controllers []*controller
+	for _, tag := range order {
+		if c := byTag[tag]; c != nil {
+			c.prefix = commonPrefix(c.operations)
+			controllers = append(controllers, c)
+		}
+	}
+	return controllers, nil
+}
+
+// commonPrefix return leading path segments shared by operations, parameters end the prefix
+func commonPrefix(ops []*operation) string {
+	prefix := strings.Split(strings.Trim(ops[0].path, "/"), "/")
+	for _, op := range ops {
+		segments := strings.Split(strings.Trim(op.path, "/"), "/")
+		n := 0
+		for n < len(prefix) && n < len(segments) && prefix[n] == segments[n] && !strings.HasPrefix(segments[n], "{") {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(prefix) == 0 || prefix[0] == "" {
+		return ""
+	}
+	return "/" + strings.Join(prefix, "/")
+}
+
+// operationTypes declares request type and resolves response type of operation
+func (g *generator) operationTypes(dtos *file, o *operation) error {
+	var body *ngopenapi.Schema
+	if o.op.RequestBody != nil {
+		body = mediaSchema(o.op.RequestBody.Content)
+	}
+	params := slices.DeleteFunc(slices.Clone(o.op.Parameters), func(p ngopenapi.Parameter) bool {
+		return p.In == "cookie"
+	})
+
+	switch resolved := g.doc.Resolve(body); {
+	case body == nil && len(params) == 0:
+		o.request = "struct{}"
+	case body != nil && body.Ref != "" && len(params) == 0:
+		o.request = g.goType(dtos, body, "", false)
+	case body != nil && !isObject(resolved):
+		if len(params) > 0 {
+			return fmt.Errorf("request body of type %q can not be combined with parameters", resolved.Type)
+		}
+		o.request = g.goType(dtos, body, o.name+"Body", true)
+	default:
+		o.request = o.name + "Request"
+		g.declareRequest(dtos, o, params, body)
+	}
+
+	o.response = "*nghttp.Response"
+	if schema := g.successSchema(o.op); schema != nil {
+		o.response = g.goType(dtos, schema, o.name+"Response", true)
+	}
+	return nil
+}
+
+// declareRequest declares struct of parameters and body, referenced bodies are embedded
+func (g *generator) declareRequest(dtos *file, o *operation, params []ngopenapi.Parameter, body *ngopenapi.Schema) {
+	var fields bytes.Buffer
+	names := map[string]bool{}
+
+	if body != nil && body.Ref != "" {
+		embedded := g.goType(dtos, body, "", false)
+		fmt.Fprintf(&fields, "\t%s\n", embedded)
+	} else if body != nil {
+		for _, name := range slices.Sorted(maps.Keys(body.Properties)) {
+			names[goName(name)] = true
+		}
+	}
+
+	for _, p := range params {
+		name := goName(p.Name)
+		if names[name] {
+			name += goName(p.In)
+		}
+		names[name] = true
+
+		tags := []string{fmt.Sprintf("%s:%q", p.In, p.Name)}
+		required := p.Required && needsRequiredRule(g.doc.Resolve(p.Schema))
+		if rules := validateRules(g.doc.Resolve(p.Schema), required, p.Schema != nil && p.Schema.Ref != ""); rules != "" {
+			tags = append(tags, fmt.Sprintf("validate:%q", rules))
+		}
+		if p.Description != "" {
+			tags = append(tags, fmt.Sprintf("doc:%q", p.Description))
+		}
+		fmt.Fprintf(&fields, "\t%s %s %s\n", name, g.goType(dtos, p.Schema, o.name+name, false), quoteTag(strings.Join(tags, " ")))
+	}
+
+	if body != nil && body.Ref == "" {
+		g.writeFields(dtos, &fields, o.name+"Request", body)
+	}
+
+	g.declared[o.request] = true
+	dtos.printf("\n// %s is request of %s %s\ntype %s struct {\n%s}\n", o.request, o.method, o.path, o.request, fields.String())
+}
+
+// successSchema return schema of first 2xx JSON response, envelopes wrapping "data" are unwrapped
+func (g *generator) successSchema(op *ngopenapi.Operation) *ngopenapi.Schema {
+	for _, status := range slices.Sorted(maps.Keys(op.Responses)) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		schema := mediaSchema(op.Responses[status].Content)
+		if schema == nil || schema.Ref != "" {
+			return schema
+		}
+		if data, ok := schema.Properties["data"]; ok {
+			wrapped := true
+			for name := range schema.Properties {
+				wrapped = wrapped && slices.Contains([]string{"data", "code", "message", "meta", "status"}, name)
+			}
+			if wrapped {
+				return data
+			}
+		}
+		return schema
+	}
+	return nil
+}
+
+func mediaSchema(content map[string]ngopenapi.MediaType) *ngopenapi.Schema {
+	if media, ok := content[ngopenapi.ContentType]; ok {
+		return media.Schema
+	}
+	for _, name := range slices.Sorted(maps.Keys(content)) {
+		if strings.HasSuffix(name, "json") {
+			return content[name].Schema
+		}
+	}
+	return nil
+}
+
+func isObject(s *ngopenapi.Schema) bool {
+	return s != nil && (s.Type == "object" || s.Type == "" && s.Properties != nil)
+}
+
+// declare writes named type of component schema
+func (g *generator) declare(dtos *file, name string, s *ngopenapi.Schema) {
+	if g.declared[name] {
+		return
+	}
+	g.declared[name] = true
+
+	var doc bytes.Buffer
+	if s.Description != "" {
+		writeComment(&doc, "", name, name+": "+s.Description)
+	}
+
+	switch {
+	case isObject(s) && len(s.Properties) > 0:
+		var fields bytes.Buffer
+		g.writeFields(dtos, &fields, name, s)
+		dtos.printf("\n%stype %s struct {\n%s}\n", doc.String(), name, fields.String())
+	case s.Type == "string" && len(s.Enum) > 0 && s.Ref == "":
+		dtos.printf("\n%stype %s string\n\n// %s values\nconst (\n", doc.String(), name, name)
+		for _, value := range s.Enum {
+			if v, ok := value.(string); ok {
+				dtos.printf("\t%s%s %s = %q\n", name, goName(v), name, v)
+			}
+		}
+		dtos.printf(")\n")
+	default:
+		dtos.printf("\n%stype %s %s\n", doc.String(), name, g.goType(dtos, s, name+"Item", false))
+	}
+}
+
+// writeFields writes struct fields of object properties, nested objects are declared as parent+field types
+func (g *generator) writeFields(dtos *file, w *bytes.Buffer, parent string, s *ngopenapi.Schema) {
+	for _, prop := range slices.Sorted(maps.Keys(s.Properties)) {
+		schema := s.Properties[prop]
+		required := slices.Contains(s.Required, prop)
+		name := goName(prop)
+
+		typ := g.goType(dtos, schema, parent+name, !required)
+		jsonTag := prop
+		if !required {
+			jsonTag += ",omitempty"
+		}
+		tags := []string{fmt.Sprintf("json:%q", jsonTag)}
+
+		resolved := g.doc.Resolve(schema)
+		if rules := validateRules(resolved, required && needsRequiredRule(resolved), schema.Ref != ""); rules != "" {
+			tags = append(tags, fmt.Sprintf("validate:%q", rules))
+		}
+		if schema.Ref == "" {
+			if format := schema.Format; format != "" && !impliedFormats[format] {
+				tags = append(tags, fmt.Sprintf("format:%q", format))
+			}
+			if example, ok := scalarText(schema.Example); ok {
+				tags = append(tags, fmt.Sprintf("example:%q", example))
+			}
+			if def, ok := scalarText(schema.Default); ok {
+				tags = append(tags, fmt.Sprintf("default:%q", def))
+			}
+		}
+		if schema.Description != "" {
+			tags = append(tags, fmt.Sprintf("doc:%q", schema.Description))
+		}
+		fmt.Fprintf(w, "\t%s %s %s\n", name, typ, quoteTag(strings.Join(tags, " ")))
+	}
+}
+
+// scalarText formats examples and defaults for struct tags, collections are skipped
+func scalarText(v any) (string, bool) {
+	switch v.(type) {
+	case string, float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// impliedFormats are expressed by Go types or validate rules
+var impliedFormats = map[string]bool{
+	"int32": true, "int64": true, "float": true, "double": true, "date-time": true, "byte": true,
+	"email": true, "uri": true, "url": true, "uuid": true,
+}
+
+// needsRequiredRule reports whether validators can tell missing values apart, zero numbers and false are valid
+func needsRequiredRule(s *ngopenapi.Schema) bool {
+	if s == nil {
+		return false
+	}
+	switch s.Type {
+	case "integer", "number", "boolean":
+		return false
+	}
+	return true
+}
+
+// validateRules return validate tag of schema, see applyTags of ngopenapi
+func validateRules(s *ngopenapi.Schema, required, ref bool) string {
+	var rules []string
+	if required {
+		rules = append(rules, "required")
+	}
+	if s == nil || ref {
+		return strings.Join(rules, ",")
+	}
+
+	var limits []string
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			if text := fmt.Sprint(v); text != "" && !strings.ContainsAny(text, " ,") {
+				values = append(values, text)
+			}
+		}
+		if len(values) == len(s.Enum) {
+			limits = append(limits, "oneof="+strings.Join(values, " "))
+		}
+	}
+	switch s.Format {
+	case "email":
+		limits = append(limits, "email")
+	case "uri", "url":
+		limits = append(limits, "url")
+	case "uuid":
+		limits = append(limits, "uuid")
+	}
+	for _, limit := range []struct {
+		rule  string
+		value *float64
+	}{
+		{"min", intLimit(s.MinLength)}, {"max", intLimit(s.MaxLength)},
+		{"min", intLimit(s.MinItems)}, {"max", intLimit(s.MaxItems)},
+		{"min", s.Minimum}, {"max", s.Maximum},
+	} {
+		if limit.value != nil {
+			limits = append(limits, limit.rule+"="+strconv.FormatFloat(*limit.value, 'f', -1, 64))
+		}
+	}
+
+	// optional values are checked when set
+	if !required && len(limits) > 0 && s.Type != "integer" && s.Type != "number" && s.Type != "boolean" {
+		rules = append(rules, "omitempty")
+	}
+	return strings.Join(append(rules, limits...), ",")
+}
+
+func intLimit(n *int) *float64 {
+	if n == nil {
+		return nil
+	}
+	f := float64(*n)
+	return &f
+}
+
+// goType return Go type of schema, inline objects are declared with hint as name
+func (g *generator) goType(dtos *file, s *ngopenapi.Schema, hint string, pointer bool) string {
+	if s == nil {
+		return "any"
+	}
+
+	if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+		typ := goName(name)
+		if pointer && isObject(g.doc.Resolve(s)) {
+			return "*" + typ
+		}
+		return typ
+	}
+
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			dtos.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(dtos, s.Items, strings.TrimSuffix(hint, "Item")+"Item", false)
+	}
+
+	if isObject(s) {
+		if len(s.Properties) > 0 {
+			g.declare(dtos, hint, s)
+			if pointer {
+				return "*" + hint
+			}
+			return hint
+		}
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(dtos, s.AdditionalProperties, hint+"Value", false)
+		}
+		return "map[string]any"
+	}
+	return "any"
+}
+
+func (g *generator) writeController(f *file, c *controller) {
+	f.imports["github.com/foxie-io/ng"] = true
+	f.imports["github.com/foxie-io/ng/openapi"] = true
+	f.imports["net/http"] = true
+
+	controller, service := c.name+"Controller", c.name+"Service"
+	f.printf("\n// %s serves operations tagged %s\ntype %s struct {\n\tng.DefaultControllerInitializer\n\tService %s\n}\n", controller, c.tag, controller, service)
+	f.printf("\n// New%s create controller calling service\nfunc New%s(service %s) *%s {\n\treturn &%s{Service: service}\n}\n", controller, controller, service, controller, controller)
+	f.printf("\n// InitializeController mounts routes under %s\nfunc (c *%s) InitializeController() ng.Controller {\n", cmp.Or(c.prefix, "/"), controller)
+	f.printf("\treturn ng.NewController(\n")
+	if c.prefix != "" {
+		f.printf("\t\tng.WithPrefix(%q),\n", c.prefix)
+	}
+	if c.tag != defaultTag {
+		f.printf("\t\tngopenapi.WithTags(%q),\n", c.tag)
+	}
+	f.printf("\t)\n}\n")
+
+	for _, o := range c.operations {
+		var doc bytes.Buffer
+		writeComment(&doc, "", o.name, cmp.Or(o.op.Summary, "routes "+o.method+" "+o.path))
+		f.printf("\n%sfunc (c *%s) %s() ng.Route {\n", doc.String(), controller, o.name)
+		f.printf("\treturn ng.NewRoute(%s, %q,\n", methodConst(o.method), cmp.Or(strings.TrimPrefix(o.path, c.prefix), "/"))
+		f.printf("\t\tng.WithTypedHandler(c.Service.%s),\n", o.name)
+		for _, opt := range g.routeOptions(o) {
+			f.printf("\t\t%s,\n", opt)
+		}
+		f.printf("\t)\n}\n")
+	}
+}
+
+// routeOptions return ngopenapi options keeping documentation of operation
+func (g *generator) routeOptions(o *operation) []string {
+	var opts []string
+	if o.id != "" {
+		opts = append(opts, fmt.Sprintf("ngopenapi.WithOperationID(%q)", o.id))
+	}
+	if o.op.Summary != "" {
+		opts = append(opts, fmt.Sprintf("ngopenapi.WithSummary(%q)", o.op.Summary))
+	}
+	if o.op.Description != "" {
+		opts = append(opts, fmt.Sprintf("ngopenapi.WithDescription(%q)", o.op.Description))
+	}
+	if len(o.op.Tags) > 1 {
+		opts = append(opts, "ngopenapi.WithTags("+quoteList(o.op.Tags)+")")
+	}
+	if o.op.Deprecated {
+		opts = append(opts, "ngopenapi.WithDeprecated()")
+	}
+
+	// the first requirement is kept, routes take a single scheme
+	security := o.op.Security
+	if security == nil {
+		security = g.doc.Security
+	}
+	if len(security) > 0 && len(security[0]) > 0 {
+		scheme := slices.Sorted(maps.Keys(security[0]))[0]
+		opts = append(opts, "ngopenapi.WithSecurity("+quoteList(append([]string{scheme}, security[0][scheme]...))+")")
+	}
+	if len(o.op.ErrorCodes) > 0 {
+		opts = append(opts, "ngopenapi.WithErrors("+quoteList(o.op.ErrorCodes)+")")
+	}
+	return opts
+}
+
+func (g *generator) writeService(f *file, c *controller) {
+	f.imports["context"] = true
+
+	f.printf("\n// %sService implements operations of %sController\ntype %sService interface {\n", c.name, c.name, c.name)
+	for i, o := range c.operations {
+		if i > 0 {
+			f.printf("\n")
+		}
+		writeComment(&f.body, "\t", o.name, cmp.Or(o.op.Summary, "handles "+o.method+" "+o.path))
+		f.printf("\t%s(ctx context.Context, req %s) (%s, error)\n", o.name, o.request, o.response)
+		g.useTypes(f, o)
+	}
+	f.printf("}\n")
+}
+
+// writeServiceStub writes implementation of service returning UNIMPLEMENTED
+func (g *generator) writeServiceStub(f *file, c *controller) {
+	f.imports["context"] = true
+	f.imports["github.com/foxie-io/ng/http"] = true
+
+	service, impl := c.name+"Service", unexportName(c.name)+"Service"
+	f.printf("\nvar _ %s = (*%s)(nil)\n", service, impl)
+	f.printf("\ntype %s struct{}\n", impl)
+	f.printf("\n// New%s create %s, replace stubs with the implementation\nfunc New%s() %s {\n\treturn &%s{}\n}\n", service, service, service, service, impl)
+	for _, o := range c.operations {
+		f.printf("\nfunc (s *%s) %s(ctx context.Context, req %s) (resp %s, err error) {\n", impl, o.name, o.request, o.response)
+		f.printf("\treturn resp, nghttp.NewErrUnimplemented()\n}\n")
+		g.useTypes(f, o)
+	}
+}
+
+// useTypes imports packages of request and response types
+func (g *generator) useTypes(f *file, o *operation) {
+	for _, typ := range []string{o.request, o.response} {
+		if strings.Contains(typ, "time.") {
+			f.imports["time"] = true
+		}
+		if strings.Contains(typ, "nghttp.") {
+			f.imports["github.com/foxie-io/ng/http"] = true
+		}
+	}
+}
+
+// writeComment writes doc comment of name, text is the summary or description
+func writeComment(w *bytes.Buffer, indent, name, text string) {
+	if text == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if !strings.HasPrefix(lines[0], name+" ") && !strings.HasPrefix(lines[0], name+":") {
+		// "Get a user" becomes "GetUser get a user", acronyms are kept
+		word, _, _ := strings.Cut(lines[0], " ")
+		if len(word) < 2 || strings.ToUpper(word) != word {
+			first := []rune(lines[0])
+			lines[0] = string(unicode.ToLower(first[0])) + string(first[1:])
+		}
+		lines[0] = name + " " + lines[0]
+	}
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func methodConst(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return "http.Method" + string(method[0]) + strings.ToLower(method[1:])
+	}
+	return strconv.Quote(method)
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteTag(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// initialisms are upper cased in Go names, e.g. user_id is UserID
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName return exported Go name of words in s, e.g. "get user-by id" is GetUserByID
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+func unexportName(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = goName(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// snakeName return file name of s, e.g. "User Accounts" is user_accounts
+func snakeName(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// splitWords splits s at separators and lower to upper case changes
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(c) && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, c)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
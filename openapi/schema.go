@@ -0,0 +1,401 @@
+package ngopenapi
+
+// JSON schemas from Go types and struct tags
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType        = reflect.TypeFor[time.Time]()
+	durationType    = reflect.TypeFor[time.Duration]()
+	rawMessageType  = reflect.TypeFor[json.RawMessage]()
+	detailsType     = reflect.TypeFor[nghttp.Details]()
+	marshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaBuilder builds schemas, named structs are added to components once and referenced
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// typeSchema return schema of values of t
+func (b *schemaBuilder) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	case detailsType:
+		return &Schema{Type: "array", Items: &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"@type": {Type: "string", Format: "uri"}},
+			Required:   []string{"@type"},
+		}}
+	}
+
+	// custom encodings can't be derived from fields
+	ptr := reflect.PointerTo(t)
+	if t.Implements(marshalerType) || ptr.Implements(marshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalType) || ptr.Implements(textMarshalType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t, reflect.Value{})
+		}
+		return b.ref(t)
+	}
+	return &Schema{}
+}
+
+// valueSchema return schema of v, interface values are documented by their dynamic type.
+// It is used for envelope bodies like nghttp.StandardBody whose data is any.
+func (b *schemaBuilder) valueSchema(v reflect.Value) *Schema {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if v.Kind() == reflect.Pointer {
+				return b.typeSchema(v.Type())
+			}
+			return &Schema{}
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && v.Type().Name() != "" && !hasInterfaceField(v.Type()):
+		return b.typeSchema(v.Type())
+	case v.Kind() == reflect.Struct:
+		return b.structSchema(v.Type(), v)
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && v.Type().Elem().Kind() == reflect.Interface:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, key := range v.MapKeys() {
+			s.Properties[key.String()] = b.valueSchema(v.MapIndex(key))
+			s.Required = append(s.Required, key.String())
+		}
+		slices.Sort(s.Required)
+		return s
+	}
+	return b.typeSchema(v.Type())
+}
+
+// ref adds named struct to components and return reference to it
+func (b *schemaBuilder) ref(t reflect.Type) *Schema {
+	if name, ok := b.names[t]; ok {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	name := schemaName(t)
+	if _, taken := b.schemas[name]; taken {
+		name = exportName(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+	}
+	for i := 2; b.schemas[name] != nil; i++ {
+		name = strings.TrimRight(name, "0123456789") + strconv.Itoa(i)
+	}
+
+	// registered before fields are walked, so recursive types end in a reference
+	s := &Schema{}
+	b.names[t], b.schemas[name] = name, s
+	*s = *b.structSchema(t, reflect.Value{})
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+// structSchema return object schema of struct fields, v is walked instead of t when valid
+func (b *schemaBuilder) structSchema(t reflect.Type, v reflect.Value) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(s, t, v)
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	return s
+}
+
+func (b *schemaBuilder) addFields(s *Schema, t reflect.Type, v reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		var field reflect.Value
+		if v.IsValid() {
+			field = v.Field(i)
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" && opts == "" || requestParam(sf) {
+			continue
+		}
+
+		// embedded structs without json name are flattened like encoding/json does
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if field.IsValid() && field.Kind() == reflect.Pointer {
+					field = reflect.Value{}
+				}
+				b.addFields(s, ft, field)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		omitempty := hasOption(opts, "omitempty") || hasOption(opts, "omitzero")
+		required := !omitempty
+
+		var fs *Schema
+		switch {
+		case field.IsValid() && omitempty && field.IsZero():
+			// not written by this value
+			continue
+		case field.IsValid():
+			fs = b.valueSchema(field)
+			required = true
+		default:
+			fs = b.typeSchema(sf.Type)
+		}
+
+		if applyTags(fs, sf) {
+			required = true
+		}
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// applyTags reads `doc` (or `description`), `example`, `default`, `enum`, `format` and `validate` tags,
+// it reports whether validate has the required rule
+func applyTags(s *Schema, sf reflect.StructField) (required bool) {
+	kind := sf.Type.Kind()
+	if kind == reflect.Pointer {
+		kind = sf.Type.Elem().Kind()
+	}
+
+	if doc := cmp.Or(sf.Tag.Get("doc"), sf.Tag.Get("description")); doc != "" {
+		s.Description = doc
+	}
+	if s.Ref != "" {
+		return strings.Contains(","+sf.Tag.Get("validate")+",", ",required,")
+	}
+
+	if example, ok := sf.Tag.Lookup("example"); ok {
+		s.Example = parseScalar(kind, example)
+	}
+	if def, ok := sf.Tag.Lookup("default"); ok {
+		s.Default = parseScalar(kind, def)
+	}
+	if format := sf.Tag.Get("format"); format != "" {
+		s.Format = format
+	}
+	if enum := sf.Tag.Get("enum"); enum != "" {
+		s.Enum = parseEnum(kind, strings.Split(enum, ","))
+	}
+
+	// rules after dive apply to elements
+	rules, _, _ := strings.Cut(sf.Tag.Get("validate"), ",dive")
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			s.Enum = parseEnum(kind, strings.Fields(param))
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			if name != "max" && name != "lte" {
+				setLimit(s, n, true)
+			}
+			if name != "min" && name != "gte" {
+				setLimit(s, n, false)
+			}
+		}
+	}
+	return required
+}
+
+// setLimit sets length of strings and arrays, value of numbers
+func setLimit(s *Schema, n float64, lower bool) {
+	length := int(n)
+	switch {
+	case s.Type == "string" && lower:
+		s.MinLength = &length
+	case s.Type == "string":
+		s.MaxLength = &length
+	case s.Type == "array" && lower:
+		s.MinItems = &length
+	case s.Type == "array":
+		s.MaxItems = &length
+	case lower:
+		s.Minimum = &n
+	default:
+		s.Maximum = &n
+	}
+}
+
+func parseEnum(kind reflect.Kind, values []string) []any {
+	enum := make([]any, 0, len(values))
+	for _, v := range values {
+		enum = append(enum, parseScalar(kind, strings.TrimSpace(v)))
+	}
+	return enum
+}
+
+// parseScalar parses tag value as kind, strings are kept when parsing fails
+func parseScalar(kind reflect.Kind, s string) any {
+	switch kind {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		var v any
+		if json.Unmarshal([]byte(s), &v) == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// requestParam reports whether field is bound from path, query or header by ng.DefaultBinder
+func requestParam(sf reflect.StructField) bool {
+	name, _ := paramSource(sf)
+	return name != ""
+}
+
+// paramSource return name and location of `path` (or `param`), `query` or `header` tagged field
+func paramSource(sf reflect.StructField) (name, in string) {
+	for _, tag := range []string{"path", "param", "query", "header"} {
+		if name, _, _ = strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			if tag == "param" {
+				tag = "path"
+			}
+			return name, tag
+		}
+	}
+	return "", ""
+}
+
+func hasInterfaceField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() == reflect.Interface {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaName return component name of t, packages are dropped from type arguments,
+// e.g. Page[github.com/acme/dtos.User] is PageUser
+func schemaName(t reflect.Type) string {
+	var name, ident strings.Builder
+	flush := func() {
+		name.WriteString(exportName(ident.String()))
+		ident.Reset()
+	}
+
+	for _, c := range t.Name() {
+		switch {
+		case c == '.':
+			ident.Reset()
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '/' || c == '-':
+			ident.WriteRune(c)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return name.String()
+}
+
+func exportName(s string) string {
+	s = strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return c
+		}
+		return -1
+	}, s)
+	if s == "" {
+		return ""
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,193 @@
+package ngopenapi
+
+// YAML output: JSON documents are re-emitted as block style YAML, keeping key order
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// node is a decoded JSON value keeping object key order
+type node struct {
+	keys   []string
+	values []*node
+	object bool
+	array  bool
+	scalar json.Token
+}
+
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	root, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		n := &node{object: true}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key.(string))
+			n.values = append(n.values, value)
+		}
+		_, err := dec.Token()
+		return n, err
+	case json.Delim('['):
+		n := &node{array: true}
+		for dec.More() {
+			value, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, value)
+		}
+		_, err := dec.Token()
+		return n, err
+	case json.Delim('}'), json.Delim(']'):
+		return nil, io.ErrUnexpectedEOF
+	}
+	return &node{scalar: tok}, nil
+}
+
+// inline reports whether n is written on the line of its key or sequence dash
+func (n *node) inline() bool {
+	return !(n.object || n.array) || len(n.values) == 0
+}
+
+func writeYAML(buf *bytes.Buffer, n *node, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch {
+	case n.inline():
+		buf.WriteString(pad)
+		writeScalar(buf, n)
+		buf.WriteByte('\n')
+	case n.object:
+		for i, key := range n.keys {
+			buf.WriteString(pad)
+			writeString(buf, key)
+			writeValue(buf, n.values[i], indent)
+		}
+	case n.array:
+		for _, value := range n.values {
+			buf.WriteString(pad)
+			buf.WriteString("-")
+			switch {
+			case value.inline():
+				buf.WriteByte(' ')
+				writeScalar(buf, value)
+				buf.WriteByte('\n')
+			case value.object:
+				// first key shares the line with the dash
+				buf.WriteByte(' ')
+				writeString(buf, value.keys[0])
+				writeValue(buf, value.values[0], indent+1)
+				rest := &node{object: true, keys: value.keys[1:], values: value.values[1:]}
+				if len(rest.keys) > 0 {
+					writeYAML(buf, rest, indent+1)
+				}
+			default:
+				buf.WriteByte('\n')
+				writeYAML(buf, value, indent+1)
+			}
+		}
+	}
+}
+
+// writeValue writes ": value" after a key at indent
+func writeValue(buf *bytes.Buffer, value *node, indent int) {
+	buf.WriteByte(':')
+	if value.inline() {
+		buf.WriteByte(' ')
+		writeScalar(buf, value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	writeYAML(buf, value, indent+1)
+}
+
+func writeScalar(buf *bytes.Buffer, n *node) {
+	switch {
+	case n.object:
+		buf.WriteString("{}")
+	case n.array:
+		buf.WriteString("[]")
+	default:
+		switch v := n.scalar.(type) {
+		case string:
+			writeString(buf, v)
+		case json.Number:
+			buf.WriteString(v.String())
+		case bool:
+			buf.WriteString(strconv.FormatBool(v))
+		default:
+			buf.WriteString("null")
+		}
+	}
+}
+
+// writeString writes s plain when YAML reads it back as the same string, JSON quoted otherwise
+func writeString(buf *bytes.Buffer, s string) {
+	if plainString(s) {
+		buf.WriteString(s)
+		return
+	}
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+func plainString(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return false
+	}
+	// numbers, including YAML 1.1 forms like 0x1F, 1_000 and 12:30, and dates,
+	// versions like 3.1.0 stay plain
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if s[0] >= '0' && s[0] <= '9' && strings.Trim(s, "0123456789.") != "" {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`.+") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, c := range s {
+		if c < ' ' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+// Package ngproblem renders error responses as RFC 9457 problem details
+/*
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithErrorFormat(ngproblem.Format),
+	)
+
+	ngproblem.RegisterType(nghttp.CodeNotFound, "https://api.example.com/problems/not-found")
+*/
+package ngproblem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+const (
+	// ContentType is media type of problem details
+	ContentType = "application/problem+json"
+
+	// DefaultType is type of problems without registered type URI
+	DefaultType = "about:blank"
+)
+
+var _ ng.ErrorFormat = Format
+
+// Problem is a problem details object, extension members are written next to standard members
+type Problem struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// standard members cannot be overridden by extensions
+var reserved = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// MarshalJSON writes extensions as top level members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !reserved[k] {
+			members[k] = v
+		}
+	}
+
+	members["type"], members["title"], members["status"] = p.Type, p.Title, p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON reads unknown members into extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type standard Problem
+	if err := json.Unmarshal(data, (*standard)(p)); err != nil {
+		return err
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for k := range reserved {
+		delete(members, k)
+	}
+	p.Extensions = nil
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+var types sync.Map
+
+// RegisterType maps code to problem type URI, codes without type use DefaultType
+func RegisterType(code nghttp.Code, uri string) {
+	types.Store(code, uri)
+}
+
+// TypeOf return problem type URI registered for code
+func TypeOf(code nghttp.Code) string {
+	if uri, ok := types.Load(code); ok {
+		return uri.(string)
+	}
+	return DefaultType
+}
+
+/*
+FromResponse converts error response to problem details.
+
+Title is the HTTP status text, detail is the response message and instance is the request path.
+Code, Meta entries and typed details become extension members, a Meta entry named "code"
+cannot replace the response code.
+*/
+func FromResponse(ctx context.Context, resp *nghttp.Response) Problem {
+	p := Problem{
+		Type:       TypeOf(resp.Code),
+		Title:      http.StatusText(resp.StatusCode()),
+		Status:     resp.StatusCode(),
+		Extensions: map[string]any{},
+	}
+
+	if resp.Message != nil {
+		p.Detail = *resp.Message
+	}
+
+	if r, err := ng.Load[*http.Request](ctx); err == nil {
+		p.Instance = r.URL.Path
+	}
+
+	maps.Copy(p.Extensions, resp.Meta)
+	if len(resp.Details) > 0 {
+		p.Extensions["details"] = resp.Details
+	}
+
+	// written last, clients rely on the machine-readable code
+	p.Extensions["code"] = resp.Code
+	return p
+}
+
+// Format is ng.ErrorFormat writing error responses as application/problem+json with the route JSON codec,
+// headers, cookies and trailers of the error response are kept
+func Format(ctx context.Context, resp *nghttp.Response) nghttp.HTTPResponse {
+	codec, ok := ng.LookupCodec(ctx, ng.MIMEApplicationJSON)
+	if !ok {
+		codec = ng.JSONCodec
+	}
+
+	var body bytes.Buffer
+	if err := codec.Encode(&body, FromResponse(ctx, resp)); err != nil {
+		return nil
+	}
+
+	raw := nghttp.NewRawResponse(resp.StatusCode(), body.Bytes())
+	maps.Copy(raw.Headers(), resp.Headers())
+	maps.Copy(raw.Trailers(), resp.Trailers())
+	for _, c := range resp.Cookies() {
+		raw.AddCookie(c)
+	}
+	raw.Headers().Set("Content-Type", ContentType)
+	return raw
+}
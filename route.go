@@ -2,6 +2,7 @@ package ng
 
 import (
 	"context"
+	"slices"
 
 	nghttp "github.com/foxie-io/ng/http"
 )
@@ -24,6 +25,9 @@ type (
 		method  string
 		path    string
 		handler Handler
+
+		// set for gateway upgrade routes
+		gateway *Gateway
 	}
 )
 
@@ -60,10 +64,17 @@ func (r *route) addPreCore(preCores ...Core) Route {
 	var (
 		responseHander ResponseHandler
 		valueHandler   ValueHandler
+		errorFormat    ErrorFormat
+		envelope       nghttp.Envelope
+		errorMappings  []ErrorMapping
+		i18n           *i18nConfig
+		binder         Binder
+		validator      Validator
 		preExcutes     = []PreHandler{}
 		middlewares    = []Middleware{}
 		guards         = []Guard{}
 		interceptors   = []Interceptor{}
+		codecs         = []Codec{}
 		prefix         string
 	)
 
@@ -79,6 +90,8 @@ func (r *route) addPreCore(preCores ...Core) Route {
 		middlewares = append(middlewares, core.middlewares...)
 		guards = append(guards, core.guards...)
 		interceptors = append(interceptors, core.interceptors...)
+		codecs = mergeCodecs(codecs, core.codecs...)
+		errorMappings = append(slices.Clone(core.errorMappings), errorMappings...)
 
 		// merge metadata
 		core.metadata.Range(func(key, value any) bool {
@@ -93,6 +106,26 @@ func (r *route) addPreCore(preCores ...Core) Route {
 		if core.valueHandler != nil {
 			valueHandler = core.valueHandler
 		}
+
+		if core.errorFormat != nil {
+			errorFormat = core.errorFormat
+		}
+
+		if core.envelope != nil {
+			envelope = core.envelope
+		}
+
+		if core.i18n != nil {
+			i18n = core.i18n
+		}
+
+		if core.binder != nil {
+			binder = core.binder
+		}
+
+		if core.validator != nil {
+			validator = core.validator
+		}
 	}
 
 	// final route info
@@ -101,12 +134,19 @@ func (r *route) addPreCore(preCores ...Core) Route {
 	// final handlers
 	r.core.responseHandler = responseHander
 	r.core.valueHandler = valueHandler
+	r.core.errorFormat = errorFormat
+	r.core.envelope = envelope
+	r.core.errorMappings = errorMappings
+	r.core.i18n = i18n
+	r.core.binder = binder
+	r.core.validator = validator
 
 	// final middlewares
 	r.core.preExecutes = preExcutes
 	r.core.middlewares = middlewares
 	r.core.guards = guards
 	r.core.interceptors = interceptors
+	r.core.codecs = codecs
 	return r
 }
 
@@ -115,10 +155,23 @@ func (r *route) build() {
 		panic("core already built")
 	}
 
+	// inherited codecs on top of default ones
+	r.core.codecs = mergeCodecs(append([]Codec{}, DefaultCodecs...), r.core.codecs...)
+
 	r.handler = r.buildRequestFlow()
+	if r.gateway != nil {
+		r.bindGateway()
+	}
 	r.core.built.Store(true)
 }
 
+// bindGateway wraps every gateway message with route interceptors
+func (r *route) bindGateway() {
+	tranformResponse, _ := r.buildResponseHandler()
+	messageHandler := r.withSavedResponseState(tranformResponse, r.gateway.handleMessage)
+	r.gateway.bind(r.withSavedResponseState(tranformResponse, r.core.buildInterceptorChain(messageHandler)), tranformResponse)
+}
+
 func (r *route) buildResponseHandler() (ValueHandler, ResponseHandler) {
 	responseHandler := r.core.responseHandler
 	if responseHandler == nil {
@@ -159,14 +212,19 @@ func (r *route) withSavedResponseState(tranformValue ValueHandler, next Handler)
 
 // DefaultValueHandler default value handler implementation
 /*
-if the value is nil, return empty response (*nghttp.Response)
 if the value is of type nghttp.HttpResponse, return it directly
-otherwise, return error response with code ErrUnknown and raw value in metadata("raw")
+if the value is an error handled by error mappings of the route, return mapped response
+otherwise, return error response with code ErrUnknown and raw value, see nghttp.NewPanicError
 */
 var DefaultValueHandler ValueHandler = func(ctx context.Context, val any) nghttp.HTTPResponse {
 	switch t := val.(type) {
 	case nghttp.HTTPResponse:
 		return t
+	case error:
+		if resp, ok := mapError(ctx, t); ok {
+			return resp
+		}
+		return nghttp.NewPanicError(val)
 	default:
 		return nghttp.NewPanicError(val)
 	}
@@ -183,6 +241,11 @@ func (r *route) buildRequestFlow() Handler {
 	// interceptor around route handler
 	interceptorChain := r.core.buildInterceptorChain(routeHandler)
 
+	// gateway interceptors wrap every message instead of the upgrade, see bindGateway
+	if r.gateway != nil {
+		interceptorChain = routeHandler
+	}
+
 	// guard before interceptor
 	guardChain := r.withSavedResponseState(tranformResponse, r.core.buildGuardChain(interceptorChain))
 
@@ -198,10 +261,32 @@ func (r *route) buildRequestFlow() Handler {
 			defer rc.Clear()
 		}
 
+		rc.setRoute(r)
+
 		defer func() {
 			// final response handling
 			val := rc.GetResponse()
 			httpResp := tranformResponse(ctx, val)
+
+			// handler already wrote to client, e.g. mounted http.Handler
+			if _, written := httpResp.(*nghttp.WrittenResponse); written {
+				return
+			}
+
+			if r.core.i18n != nil {
+				httpResp = localize(ctx, r.core.i18n, httpResp)
+			}
+
+			if r.core.errorFormat != nil {
+				httpResp = formatError(ctx, r.core.errorFormat, httpResp)
+			}
+
+			if r.core.envelope != nil {
+				httpResp = applyEnvelope(r.core.envelope, httpResp)
+			}
+
+			// context holds the response as written, e.g. for tests and OnClear hooks
+			rc.SetResponse(httpResp)
 			err = finalResponse(ctx, httpResp)
 		}()
 
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type BodyController struct {
+	ng.DefaultControllerInitializer
+	dir string
+}
+
+func (c *BodyController) respond(method, path string, resp func() nghttp.HTTPResponse) ng.Route {
+	return ng.NewRoute(method, path, ng.WithHandler(func(ctx context.Context) error {
+		return ng.Respond(ctx, resp())
+	}))
+}
+
+func (c *BodyController) Created() ng.Route {
+	return c.respond(http.MethodPost, "/created", func() nghttp.HTTPResponse {
+		return nghttp.Created("ok", "/items/1")
+	})
+}
+
+func (c *BodyController) NoContent() ng.Route {
+	return c.respond(http.MethodDelete, "/no-content", func() nghttp.HTTPResponse {
+		return nghttp.NoContent()
+	})
+}
+
+func (c *BodyController) Redirect() ng.Route {
+	return c.respond(http.MethodGet, "/redirect", func() nghttp.HTTPResponse {
+		return nghttp.Redirect("/target", http.StatusTemporaryRedirect)
+	})
+}
+
+func (c *BodyController) File() ng.Route {
+	return c.respond(http.MethodGet, "/file", func() nghttp.HTTPResponse {
+		return nghttp.File(filepath.Join(c.dir, "hello.txt"))
+	})
+}
+
+func (c *BodyController) MissingFile() ng.Route {
+	return c.respond(http.MethodGet, "/missing", func() nghttp.HTTPResponse {
+		return nghttp.File(filepath.Join(c.dir, "missing.txt"))
+	})
+}
+
+func (c *BodyController) Reader() ng.Route {
+	return c.respond(http.MethodGet, "/reader", func() nghttp.HTTPResponse {
+		return nghttp.Reader(strings.NewReader("a,b\n1,2\n"), "text/csv", -1)
+	})
+}
+
+func (c *BodyController) Attachment() ng.Route {
+	return c.respond(http.MethodGet, "/attachment", func() nghttp.HTTPResponse {
+		return nghttp.Attachment("report.json", strings.NewReader(`{}`)).Update(
+			nghttp.WithHeader("Cache-Control", "no-cache"),
+		)
+	})
+}
+
+func TestBodyResponses(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&BodyController{dir: dir})
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	do := func(t *testing.T, method, path string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		body    string
+		headers map[string]string
+	}{
+		{"created", http.MethodPost, "/created", 201, `{"code":"OK","data":"ok"}`, map[string]string{"Location": "/items/1"}},
+		{"no content", http.MethodDelete, "/no-content", 204, "", nil},
+		{"redirect", http.MethodGet, "/redirect", 307, "", map[string]string{"Location": "/target", "Content-Length": "0"}},
+		{"file", http.MethodGet, "/file", 200, "hello file", map[string]string{"Content-Type": "text/plain; charset=utf-8", "Content-Length": "10"}},
+		{"missing file", http.MethodGet, "/missing", 404, `{"code":"NOT_FOUND","message":"not found"}`, map[string]string{"Content-Type": "application/json"}},
+		{"reader", http.MethodGet, "/reader", 200, "a,b\n1,2\n", map[string]string{"Content-Type": "text/csv"}},
+		{"attachment", http.MethodGet, "/attachment", 200, `{}`, map[string]string{
+			"Content-Disposition": `attachment; filename=report.json`,
+			"Content-Type":        "application/json",
+			"Cache-Control":       "no-cache",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := do(t, tt.method, tt.path)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, resp.StatusCode)
+			}
+			if body != tt.body {
+				t.Fatalf("expected body %q, got %q", tt.body, body)
+			}
+			for k, v := range tt.headers {
+				if resp.Header.Get(k) != v {
+					t.Fatalf("expected header %s=%q, got %q", k, v, resp.Header.Get(k))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+var (
+	errUserNotFound  = nghttp.NewErrNotFound().Update(nghttp.WithMessage("user not found"))
+	errOrderNotFound = nghttp.NewErrNotFound().Update(nghttp.WithMessage("order not found"))
+)
+
+func findUser() error {
+	return nghttp.Wrap(sql.ErrNoRows, errUserNotFound)
+}
+
+func TestErrorCause(t *testing.T) {
+	nghttp.EnableStackCapture(true)
+	defer nghttp.EnableStackCapture(false)
+
+	var logged error
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithMiddleware(ng.MiddlewareFunc(func(ctx context.Context, next ng.Handler) {
+			next(ctx)
+			if err, ok := ng.GetContext(ctx).GetResponse().(error); ok {
+				logged = err
+			}
+		})),
+	)
+	app.AddRoute(
+		ng.NewRoute(http.MethodGet, "/users/{id}", ng.WithHandler(func(ctx context.Context) error {
+			return findUser()
+		})),
+		ng.NewRoute(http.MethodGet, "/panic", ng.WithHandler(func(ctx context.Context) error {
+			panic(io.ErrUnexpectedEOF)
+		})),
+	)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) string {
+		t.Helper()
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	t.Run("wrapped cause", func(t *testing.T) {
+		body := get(t, "/users/1")
+		if body != `{"code":"NOT_FOUND","message":"user not found"}` {
+			t.Fatalf("cause must not be serialized, got %s", body)
+		}
+
+		if !errors.Is(logged, sql.ErrNoRows) || !errors.Is(logged, errUserNotFound) {
+			t.Fatalf("expected logged error to match cause and sentinel, got %v", logged)
+		}
+
+		var resp *nghttp.Response
+		if !errors.As(logged, &resp) || !strings.Contains(resp.Stack(), "test.findUser") {
+			t.Fatalf("expected stack with findUser, got %q", resp.Stack())
+		}
+
+		if errUserNotFound.Unwrap() != nil {
+			t.Fatalf("sentinel response should not be mutated")
+		}
+	})
+
+	t.Run("sentinels", func(t *testing.T) {
+		err := findUser()
+		if errors.Is(err, errOrderNotFound) || errors.Is(err, nghttp.NewErrNotFound()) {
+			t.Fatal("expected sentinels with same code not to match")
+		}
+		if !nghttp.HasCode(err, nghttp.CodeNotFound) || nghttp.HasCode(err, nghttp.CodeInternal) {
+			t.Fatal("expected HasCode to match by code")
+		}
+
+		// code of a response wrapped as cause
+		if !nghttp.HasCode(nghttp.NewErrInternal().WithCause(err), nghttp.CodeNotFound) {
+			t.Fatal("expected HasCode to look into causes")
+		}
+	})
+
+	t.Run("stack location", func(t *testing.T) {
+		tests := []struct {
+			name string
+			wrap func() (*nghttp.Response, int)
+		}{
+			{"Wrap", func() (*nghttp.Response, int) {
+				_, _, line, _ := runtime.Caller(0)
+				return nghttp.Wrap(io.EOF, errUserNotFound), line + 1
+			}},
+			{"Response.WithCause", func() (*nghttp.Response, int) {
+				_, _, line, _ := runtime.Caller(0)
+				return errUserNotFound.WithCause(io.EOF), line + 1
+			}},
+			{"WithCause", func() (*nghttp.Response, int) {
+				_, _, line, _ := runtime.Caller(0)
+				return nghttp.NewErrInternal().Update(nghttp.WithCause(io.EOF)), line + 1
+			}},
+		}
+
+		_, file, _, _ := runtime.Caller(0)
+		for _, tt := range tests {
+			resp, line := tt.wrap()
+			_, first, _ := strings.Cut(resp.Stack(), "\n\t")
+			first, _, _ = strings.Cut(first, "\n")
+			if expected := fmt.Sprintf("%s:%d", file, line); first != expected {
+				t.Fatalf("%s: expected stack to start at %s, got %s", tt.name, expected, first)
+			}
+		}
+	})
+
+	t.Run("panic cause", func(t *testing.T) {
+		get(t, "/panic")
+		if !errors.Is(logged, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected panic value as cause, got %v", logged)
+		}
+	})
+
+	t.Run("stack capture disabled", func(t *testing.T) {
+		nghttp.EnableStackCapture(false)
+		if stack := nghttp.Wrap(sql.ErrNoRows, errUserNotFound).Stack(); stack != "" {
+			t.Fatalf("expected no stack, got %s", stack)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	"github.com/foxie-io/ng/ngtest"
+)
+
+type authGuard struct {
+	ng.DefaultID[authGuard]
+}
+
+func (authGuard) Allow(ctx context.Context) error {
+	return nghttp.NewErrUnauthenticated()
+}
+
+type greeter interface {
+	Greet(name string) string
+}
+
+type englishGreeter struct{}
+
+func (englishGreeter) Greet(name string) string { return "hello " + name }
+
+type fakeGreeter struct{}
+
+func (fakeGreeter) Greet(name string) string { return "fake " + name }
+
+type GreetController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *GreetController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/greet"))
+}
+
+func (c *GreetController) Greet() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/:name",
+		ng.WithHandler(func(ctx context.Context) error {
+			g, _ := ng.MustLoadOrStore[greeter](ctx, englishGreeter{})
+			name := ng.MustLoad[*http.Request](ctx).PathValue("name")
+			return ng.Respond(ctx, nghttp.NewResponse(g.Greet(name)))
+		}),
+	)
+}
+
+func (c *GreetController) Public() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/",
+		ng.SkipAllGuards(),
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse("public"))
+		}),
+	)
+}
+
+func newGreetApp() ng.App {
+	app := ng.NewApp(
+		ng.WithGuards(authGuard{}),
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+	)
+	app.AddController(&GreetController{})
+	return app.Build()
+}
+
+func TestClient(t *testing.T) {
+	t.Run("guard applies", func(t *testing.T) {
+		client := ngtest.NewClient(newGreetApp())
+
+		resp := client.Request(http.MethodGet, "/greet/bob")
+		resp.AssertStatus(t, http.StatusUnauthorized)
+		resp.AssertCode(t, nghttp.CodeUnauthenticated)
+
+		if resp.Route == nil || resp.Route.Name() != "test.GreetController.Greet" {
+			t.Fatalf("unexpected route: %v", resp.Route)
+		}
+	})
+
+	t.Run("skip all guards", func(t *testing.T) {
+		client := ngtest.NewClient(newGreetApp())
+
+		resp := client.Request(http.MethodGet, "/greet")
+		resp.AssertStatus(t, http.StatusOK)
+
+		var data string
+		resp.DecodeData(t, &data)
+		if data != "public" {
+			t.Fatalf("expected public, got %s", data)
+		}
+	})
+
+	t.Run("override guard and provider", func(t *testing.T) {
+		client := ngtest.NewClient(newGreetApp(),
+			ngtest.OverrideGuard(authGuard{}, ngtest.AllowGuard),
+			ngtest.OverrideValue[greeter](fakeGreeter{}),
+		)
+
+		resp := client.Request(http.MethodGet, "/greet/bob")
+		resp.AssertStatus(t, http.StatusOK)
+		resp.AssertCode(t, nghttp.CodeOk)
+
+		if resp.Response.Data != "fake bob" {
+			t.Fatalf("expected fake bob, got %v", resp.Response.Data)
+		}
+
+		if resp.Header.Get("content-type") != "application/json" {
+			t.Fatalf("unexpected content type %s", resp.Header.Get("content-type"))
+		}
+	})
+
+	t.Run("override guard func", func(t *testing.T) {
+		denyAll := ng.GuardFunc(func(ctx context.Context) error { return nghttp.NewErrPermissionDenied() })
+		app := ng.NewApp(
+			ng.WithGuards(denyAll),
+			ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		)
+		app.AddController(&GreetController{})
+		app.Build()
+
+		ngtest.NewClient(app).Request(http.MethodGet, "/greet/bob").AssertCode(t, nghttp.CodePermissionDenied)
+		ngtest.NewClient(app, ngtest.OverrideGuard(denyAll, ngtest.AllowGuard)).Request(http.MethodGet, "/greet/bob").AssertStatus(t, http.StatusOK)
+
+		// original routes are not changed
+		ngtest.NewClient(app).Request(http.MethodGet, "/greet/bob").AssertCode(t, nghttp.CodePermissionDenied)
+	})
+
+	t.Run("unused guard override", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic for guard not used by any route")
+			}
+		}()
+		ngtest.NewClient(newGreetApp(), ngtest.OverrideGuard(ngtest.DenyGuard(nil), ngtest.AllowGuard))
+	})
+
+	t.Run("response as written", func(t *testing.T) {
+		app := ng.NewApp(
+			ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+			ng.WithI18n(newTestCatalog(t)),
+			ng.WithEnvelope(nghttp.JSendEnvelope),
+		)
+		app.AddRoute(ng.NewRoute(http.MethodGet, "/users/{id}", ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrNotFound().Update(nghttp.Meta("resource", "user"))
+		})))
+		app.Build()
+
+		resp := ngtest.NewClient(app).Request(http.MethodGet, "/users/1", ngtest.WithRequestHeader("Accept-Language", "fr"))
+		resp.AssertCode(t, nghttp.CodeNotFound)
+		written, ok := resp.HTTPResponse.(*nghttp.Response)
+		if !ok || written.Envelope() == nil || resp.Response.Message == nil || *resp.Response.Message != "user introuvable" {
+			t.Fatalf("expected localized enveloped response, got %#v", resp.HTTPResponse)
+		}
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		client := ngtest.NewClient(newGreetApp())
+		resp := client.Request(http.MethodDelete, "/greet/bob")
+		if resp.Route != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Fatalf("expected no route, got %d", resp.StatusCode)
+		}
+	})
+}
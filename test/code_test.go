@@ -0,0 +1,76 @@
+package test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+const (
+	CodePaymentRequired nghttp.Code = "PAYMENT_REQUIRED"
+	CodeQuotaExceeded   nghttp.Code = "QUOTA_EXCEEDED"
+)
+
+func TestCodeRegistry(t *testing.T) {
+	nghttp.RegisterCode(CodePaymentRequired, nghttp.CodeSpec{
+		Status:      http.StatusPaymentRequired,
+		Description: "The subscription has expired.",
+	})
+	nghttp.RegisterCode(CodeQuotaExceeded, nghttp.CodeSpec{
+		Status:         http.StatusServiceUnavailable,
+		Retryable:      true,
+		DefaultMessage: "quota exceeded, try later",
+	})
+
+	t.Run("new", func(t *testing.T) {
+		resp := nghttp.New(CodePaymentRequired, nghttp.Meta("plan", "pro"))
+		if resp.StatusCode() != http.StatusPaymentRequired || resp.Error() != "payment required" || resp.Meta["plan"] != "pro" {
+			t.Fatalf("unexpected response %d %s %v", resp.StatusCode(), resp.Error(), resp.Meta)
+		}
+
+		unknown := nghttp.New("NOT_REGISTERED")
+		if unknown.StatusCode() != http.StatusInternalServerError || unknown.Error() != "NOT_REGISTERED" {
+			t.Fatalf("unexpected unknown code response %d %s", unknown.StatusCode(), unknown.Error())
+		}
+
+		if nghttp.NewErrNotFound().StatusCode() != http.StatusNotFound || nghttp.NewErrNotFound().Error() != "not found" {
+			t.Fatalf("built-in constructors should use the registry")
+		}
+	})
+
+	t.Run("classification", func(t *testing.T) {
+		tests := []struct {
+			code                      nghttp.Code
+			client, server, retryable bool
+		}{
+			{CodePaymentRequired, true, false, false},
+			{CodeQuotaExceeded, false, true, true},
+			{nghttp.CodeNotFound, true, false, false},
+			{nghttp.CodeTooManyRequests, false, false, true},
+			{nghttp.CodeCanceled, false, false, false},
+			{nghttp.CodeInternal, false, true, true},
+			{"NOT_REGISTERED", false, false, false},
+		}
+
+		for _, tt := range tests {
+			if tt.code.IsClientError() != tt.client || tt.code.IsServerError() != tt.server || tt.code.IsRetryable() != tt.retryable {
+				t.Fatalf("unexpected classification of %s", tt.code)
+			}
+		}
+	})
+
+	t.Run("catalog", func(t *testing.T) {
+		catalog := nghttp.Codes()
+		if !slices.IsSortedFunc(catalog, func(a, b nghttp.CodeSpec) int { return strings.Compare(string(a.Code), string(b.Code)) }) {
+			t.Fatalf("expected catalog sorted by code")
+		}
+
+		i := slices.IndexFunc(catalog, func(s nghttp.CodeSpec) bool { return s.Code == CodePaymentRequired })
+		if i < 0 || catalog[i].Class != nghttp.ClassClient || catalog[i].Description != "The subscription has expired." {
+			t.Fatalf("expected registered code in catalog, got %+v", catalog)
+		}
+	})
+}
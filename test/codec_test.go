@@ -0,0 +1,200 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	"github.com/foxie-io/ng/ngtest"
+)
+
+type product struct {
+	ID    int    `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Price int    `json:"price" csv:"price_cents" xml:"price"`
+}
+
+type ProductController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *ProductController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/products"))
+}
+
+func (c *ProductController) List() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse([]product{
+				{ID: 1, Name: "pen", Price: 100},
+				{ID: 2, Name: "book, blue", Price: 250},
+			}))
+		}),
+	)
+}
+
+func (c *ProductController) Create() ng.Route {
+	return ng.NewRoute(http.MethodPost, "/",
+		ng.WithScopeHandler(func() ng.Handler {
+			var body product
+			return ng.Handle(
+				ng.BindBody(&body),
+				func(ctx context.Context) error {
+					return ng.Respond(ctx, nghttp.NewResponse(body))
+				},
+			)
+		}),
+	)
+}
+
+func (c *ProductController) Stats() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/stats",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(map[string]any{"count": 2}))
+		}),
+	)
+}
+
+func newProductApp(opts ...ng.Option) ng.App {
+	app := ng.NewApp(append(opts, ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))...)
+	app.AddController(&ProductController{})
+	return app.Build()
+}
+
+func TestContentNegotiation(t *testing.T) {
+	client := ngtest.NewClient(newProductApp())
+
+	tests := []struct {
+		name        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"default json", "", 200, "application/json", `{"code":"OK","data":[{"id":1,"name":"pen","price":100},{"id":2,"name":"book, blue","price":250}]}`},
+		{"wildcard", "*/*", 200, "application/json", `{"code":"OK","data":[{"id":1,"name":"pen","price":100},{"id":2,"name":"book, blue","price":250}]}`},
+		{"csv", "text/csv", 200, "text/csv", "id,name,price_cents\n1,pen,100\n2,\"book, blue\",250\n"},
+		{"ndjson", "application/x-ndjson", 200, "application/x-ndjson", "{\"id\":1,\"name\":\"pen\",\"price\":100}\n{\"id\":2,\"name\":\"book, blue\",\"price\":250}\n"},
+		{"quality", "application/json;q=0.5, application/xml", 200, "application/xml", `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><code>OK</code><data><id>1</id><name>pen</name><price>100</price></data><data><id>2</id><name>book, blue</name><price>250</price></data></response>`},
+		{"excluded", "application/json;q=0, */*;q=0.1", 200, "application/xml", ""},
+		{"lower wildcard", "application/xml, */*;q=0.1", 200, "application/xml", ""},
+		{"tie", "application/xml, */*", 200, "application/json", ""},
+		{"not acceptable", "image/png", 406, "application/json", `{"code":"NOT_ACCEPTABLE","message":"not acceptable"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := client.Request(http.MethodGet, "/products", ngtest.WithRequestHeader("Accept", tt.accept))
+			resp.AssertStatus(t, tt.status)
+
+			if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected content type %s, got %s", tt.contentType, ct)
+			}
+
+			if tt.body != "" && string(resp.Body) != tt.body {
+				t.Fatalf("expected body %q, got %q", tt.body, resp.Body)
+			}
+		})
+	}
+}
+
+func TestNegotiateMapData(t *testing.T) {
+	client := ngtest.NewClient(newProductApp())
+
+	tests := []struct {
+		name        string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", 200, "application/json"},
+		{"encode fallback", "application/xml, application/json;q=0.5", 200, "application/json"},
+		{"no fallback", "application/xml", 500, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := client.Request(http.MethodGet, "/products/stats", ngtest.WithRequestHeader("Accept", tt.accept))
+			resp.AssertStatus(t, tt.status)
+
+			if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected content type %s, got %s", tt.contentType, ct)
+			}
+
+			if tt.status == http.StatusOK && string(resp.Body) != `{"code":"OK","data":{"count":2}}` {
+				t.Fatalf("unexpected body %s", resp.Body)
+			}
+		})
+	}
+
+	// media types listed without wildcard are still honored
+	resp := client.Request(http.MethodGet, "/products", ngtest.WithRequestHeader("Accept", "application/xml"))
+	if ct := resp.Header.Get("Content-Type"); ct != "application/xml" {
+		t.Fatalf("expected xml, got %s", ct)
+	}
+}
+
+func TestBindBody(t *testing.T) {
+	client := ngtest.NewClient(newProductApp())
+
+	t.Run("json", func(t *testing.T) {
+		resp := client.Request(http.MethodPost, "/products", ngtest.WithJSON(product{ID: 3, Name: "cup"}))
+		resp.AssertStatus(t, http.StatusOK)
+
+		var p product
+		resp.DecodeData(t, &p)
+		if p.Name != "cup" {
+			t.Fatalf("expected cup, got %v", p)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		req := newRequest(http.MethodPost, "/products", "application/xml; charset=utf-8", `<product><id>4</id><name>mug</name></product>`)
+		resp := client.Do(req)
+		resp.AssertStatus(t, http.StatusOK)
+
+		var p product
+		resp.DecodeData(t, &p)
+		if p.ID != 4 || p.Name != "mug" {
+			t.Fatalf("expected mug, got %v", p)
+		}
+	})
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		resp := client.Do(newRequest(http.MethodPost, "/products", "application/yaml", "name: mug"))
+		resp.AssertStatus(t, http.StatusUnsupportedMediaType)
+		resp.AssertCode(t, nghttp.CodeUnsupportedMediaType)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		resp := client.Do(newRequest(http.MethodPost, "/products", "application/json", "{"))
+		resp.AssertStatus(t, http.StatusBadRequest)
+		resp.AssertCode(t, nghttp.CodeInvalidArgument)
+	})
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	var encoded int
+	marshal := func(v any) ([]byte, error) {
+		encoded++
+		return json.Marshal(v)
+	}
+
+	client := ngtest.NewClient(newProductApp(ng.WithJSONCodec(ng.NewJSONCodec(marshal, json.Unmarshal))))
+	client.Request(http.MethodGet, "/products").AssertStatus(t, http.StatusOK)
+
+	if encoded != 1 {
+		t.Fatalf("expected custom json codec to be used once, got %d", encoded)
+	}
+}
+
+func newRequest(method, target, contentType, body string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
@@ -69,3 +69,23 @@ func BenchmarkAcquireContext(b *testing.B) {
 		ng.NewContext(context.Background())
 	}
 }
+
+func TestOnClear(t *testing.T) {
+	if ng.OnClear(context.Background(), func() {}) {
+		t.Fatal("expected OnClear to report missing request context")
+	}
+
+	ctx, rc := ng.NewContext(context.Background())
+
+	var order []int
+	for i := range 2 {
+		if !ng.OnClear(ctx, func() { order = append(order, i) }) {
+			t.Fatal("expected OnClear to register hook")
+		}
+	}
+
+	rc.Clear()
+	if len(order) != 2 || order[0] != 1 || order[1] != 0 {
+		t.Fatalf("expected hooks in reverse order, got %v", order)
+	}
+}
@@ -0,0 +1,240 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	"github.com/foxie-io/ng/ngtest"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+const contractSpec = `openapi: 3.1.0
+info: {title: Items, version: 1.0.0}
+paths:
+  /items/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  code: {type: string}
+                  data: {$ref: "#/components/schemas/Item"}
+        "404": {description: not found}
+      x-error-codes: [NOT_FOUND]
+  /items:
+    post:
+      parameters:
+        - {name: dry, in: query, schema: {type: boolean}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string, minLength: 1}
+      responses:
+        "200": {description: ok}
+components:
+  schemas:
+    Item:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer}
+        name: {type: string, maxLength: 5}
+`
+
+type ContractItem struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+func newContractApp(opts ...ng.Option) ng.App {
+	app := ng.NewApp(append([]ng.Option{ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler)}, opts...)...)
+	app.AddRoute(
+		ng.NewRoute(http.MethodGet, "/items/{id}", ng.WithTypedHandler(func(ctx context.Context, req struct {
+			ID int `path:"id"`
+		}) (*ContractItem, error) {
+			switch req.ID {
+			case 1:
+				return &ContractItem{ID: 1, Name: "cup"}, nil
+			case 2:
+				// drifted from the spec
+				return &ContractItem{ID: 2, Name: "teapot", Color: "red"}, nil
+			case 3:
+				return nil, nghttp.NewErrPermissionDenied()
+			}
+			return nil, nghttp.NewErrNotFound()
+		})),
+		ng.NewRoute(http.MethodPost, "/items", ng.WithTypedHandler(func(ctx context.Context, req struct {
+			Name string `json:"name"`
+		}) (*ContractItem, error) {
+			return &ContractItem{ID: 9, Name: req.Name}, nil
+		})),
+		ng.NewRoute(http.MethodGet, "/undocumented", ng.WithHandler(func(ctx context.Context) error { return nil })),
+	)
+	return app.Build()
+}
+
+// recordingT records errors of ngtest.WithContracts
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestValidateContracts(t *testing.T) {
+	spec, err := ngopenapi.Parse([]byte(contractSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		client := ngtest.NewClient(newContractApp(ng.WithInterceptor(ngopenapi.ValidateContracts(spec))))
+
+		client.Request(http.MethodGet, "/items/1").AssertStatus(t, http.StatusOK)
+		client.Request(http.MethodGet, "/items/7").AssertCode(t, nghttp.CodeNotFound)
+
+		resp := client.Request(http.MethodGet, "/items/abc")
+		resp.AssertCode(t, nghttp.CodeInvalidArgument)
+		if violations := resp.Response.Meta["violations"]; !reflect.DeepEqual(violations, []string{"path.id: expected integer, got string"}) {
+			t.Fatalf("unexpected violations %v", violations)
+		}
+
+		resp = client.Request(http.MethodPost, "/items?dry=maybe", ngtest.WithJSON(map[string]any{"name": ""}))
+		resp.AssertCode(t, nghttp.CodeInvalidArgument)
+		expected := []string{"query.dry: expected boolean, got string", "body.name: must have at least 1 characters"}
+		if violations := resp.Response.Meta["violations"]; !reflect.DeepEqual(violations, expected) {
+			t.Fatalf("unexpected violations %v", violations)
+		}
+
+		resp = client.Request(http.MethodGet, "/items/2")
+		resp.AssertCode(t, nghttp.CodeInternal)
+		if violations := resp.Response.Meta["violations"]; !reflect.DeepEqual(violations, []string{"response.data.name: must have at most 5 characters"}) {
+			t.Fatalf("unexpected violations %v", violations)
+		}
+
+		client.Request(http.MethodGet, "/items/3").AssertCode(t, nghttp.CodeInternal)
+		client.Request(http.MethodGet, "/undocumented").AssertCode(t, nghttp.CodeInvalidArgument)
+	})
+
+	t.Run("adapter path params", func(t *testing.T) {
+		app := newContractApp(ng.WithInterceptor(ngopenapi.ValidateContracts(spec)))
+		var route ng.Route
+		for _, r := range app.Routes() {
+			if r.Method() == http.MethodGet && strings.HasPrefix(r.Path(), "/items/") {
+				route = r
+			}
+		}
+
+		// dispatched like a router other than http.ServeMux, without a matched pattern
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		ctx, rc := ng.NewContext(r.Context())
+		defer rc.Clear()
+		ng.Store(ctx, http.ResponseWriter(w))
+		ng.Store(ctx, r)
+		ng.Store(ctx, ng.PathParams(func(name string) string { return "1" }))
+		_ = route.Handler()(ctx)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("max body size", func(t *testing.T) {
+		client := ngtest.NewClient(newContractApp(ng.WithInterceptor(ngopenapi.ValidateContracts(spec, ngopenapi.WithMaxBodySize(8)))))
+
+		// known length above the limit is not read
+		client.Request(http.MethodPost, "/items", ngtest.WithJSON(map[string]any{"name": ""})).AssertStatus(t, http.StatusOK)
+
+		// unknown length is read up to the limit, then passed on whole
+		req := httptest.NewRequest(http.MethodPost, "/items", io.MultiReader(strings.NewReader(`{"name":`), strings.NewReader(`"saucepan"}`)))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "application/json")
+		resp := client.Do(req)
+		resp.AssertStatus(t, http.StatusOK)
+		if !strings.Contains(string(resp.Body), `"name":"saucepan"`) {
+			t.Fatalf("expected whole body, got %s", resp.Body)
+		}
+
+		client.Request(http.MethodPost, "/items", ngtest.WithJSON(map[string]any{})).AssertCode(t, nghttp.CodeInvalidArgument)
+	})
+
+	t.Run("annotate", func(t *testing.T) {
+		t.Setenv("CONTRACTS", "annotate")
+		mode := ngopenapi.ContractModeFromEnv("CONTRACTS", ngopenapi.ContractReject)
+		client := ngtest.NewClient(newContractApp(ng.WithInterceptor(ngopenapi.ValidateContracts(spec,
+			ngopenapi.WithContractMode(mode),
+			ngopenapi.WithStrictProperties(),
+		))))
+
+		resp := client.Request(http.MethodGet, "/items/2")
+		resp.AssertStatus(t, http.StatusOK)
+		expected := "response.data.color: is not documented; response.data.name: must have at most 5 characters"
+		if header := resp.Header.Get(ngopenapi.ContractHeader); header != expected {
+			t.Fatalf("unexpected header %q", header)
+		}
+
+		resp = client.Request(http.MethodGet, "/items/1")
+		if header := resp.Header.Get(ngopenapi.ContractHeader); header != "" {
+			t.Fatalf("expected no violations, got %q", header)
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		var reported []string
+		client := ngtest.NewClient(newContractApp(ng.WithInterceptor(ngopenapi.ValidateContracts(spec,
+			ngopenapi.WithContractMode(ngopenapi.ContractReport),
+			ngopenapi.OnContractViolation(func(ctx context.Context, route ng.Route, violations []ngopenapi.Violation) {
+				for _, v := range violations {
+					reported = append(reported, route.Method()+" "+v.String())
+				}
+			}),
+		))))
+
+		client.Request(http.MethodGet, "/items/3").AssertCode(t, nghttp.CodePermissionDenied)
+		if !reflect.DeepEqual(reported, []string{"GET response: status 403 is not documented"}) {
+			t.Fatalf("unexpected violations %v", reported)
+		}
+	})
+
+	t.Run("ngtest", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		client := ngtest.NewClient(newContractApp(), ngtest.WithContracts(rt, spec))
+
+		client.Request(http.MethodGet, "/items/1").AssertStatus(t, http.StatusOK)
+		client.Request(http.MethodPost, "/items", ngtest.WithJSON(map[string]any{"name": "bowl"})).AssertStatus(t, http.StatusOK)
+		if len(rt.errors) != 0 {
+			t.Fatalf("expected no violations, got %v", rt.errors)
+		}
+
+		client.Request(http.MethodGet, "/items/2").AssertStatus(t, http.StatusOK)
+		if len(rt.errors) != 1 || !strings.Contains(rt.errors[0], "GET /items/{id}: response.data.name: must have at most 5 characters") {
+			t.Fatalf("unexpected errors %v", rt.errors)
+		}
+	})
+}
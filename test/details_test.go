@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type PaymentInfo struct {
+	Plan string `json:"plan"`
+}
+
+func (*PaymentInfo) TypeURL() string { return "example.com/PaymentInfo" }
+
+func TestErrorDetails(t *testing.T) {
+	nghttp.RegisterDetail(func() nghttp.Detail { return &PaymentInfo{} })
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddRoute(ng.NewRoute(http.MethodPost, "/signup", ng.WithHandler(func(ctx context.Context) error {
+		return nghttp.NewErrInvalidArgument().Update(nghttp.WithDetails(
+			&nghttp.BadRequest{FieldViolations: []nghttp.FieldViolation{{Field: "email", Description: "invalid email"}}},
+			&nghttp.RetryInfo{RetryDelay: 1500 * time.Millisecond},
+			&nghttp.ErrorInfo{Reason: "EMAIL_INVALID", Domain: "example.com", Metadata: map[string]string{"field": "email"}},
+			&nghttp.QuotaFailure{Violations: []nghttp.QuotaViolation{{Subject: "user:1", Description: "daily limit"}}},
+			&nghttp.LocalizedMessage{Locale: "fr", Message: "adresse invalide"},
+			&PaymentInfo{Plan: "pro"},
+		))
+	})))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/signup", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+
+	for _, member := range []string{
+		`"@type":"type.googleapis.com/google.rpc.BadRequest"`,
+		`"fieldViolations":[{"field":"email","description":"invalid email"}]`,
+		`"retryDelay":"1.5s"`,
+		`"@type":"example.com/PaymentInfo"`,
+	} {
+		if !strings.Contains(string(body), member) {
+			t.Fatalf("expected %s in %s", member, body)
+		}
+	}
+
+	resp, err := nghttp.ParseResponse(res.StatusCode, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusBadRequest || resp.Code != nghttp.CodeInvalidArgument || len(resp.Details) != 6 {
+		t.Fatalf("unexpected response %d %s %d details", resp.StatusCode(), resp.Code, len(resp.Details))
+	}
+
+	badRequest, ok := nghttp.DetailOf[*nghttp.BadRequest](resp)
+	if !ok || badRequest.FieldViolations[0].Field != "email" {
+		t.Fatalf("expected bad request detail, got %+v", badRequest)
+	}
+	if retry, ok := nghttp.DetailOf[*nghttp.RetryInfo](resp); !ok || retry.RetryDelay != 1500*time.Millisecond {
+		t.Fatalf("expected retry info, got %+v", retry)
+	}
+	if info, ok := nghttp.DetailOf[*nghttp.ErrorInfo](resp); !ok || info.Metadata["field"] != "email" {
+		t.Fatalf("expected error info, got %+v", info)
+	}
+	if quota, ok := nghttp.DetailOf[*nghttp.QuotaFailure](resp); !ok || quota.Violations[0].Subject != "user:1" {
+		t.Fatalf("expected quota failure, got %+v", quota)
+	}
+	if msg, ok := nghttp.DetailOf[*nghttp.LocalizedMessage](resp); !ok || msg.Locale != "fr" {
+		t.Fatalf("expected localized message, got %+v", msg)
+	}
+	if payment, ok := nghttp.DetailOf[*PaymentInfo](resp); !ok || payment.Plan != "pro" {
+		t.Fatalf("expected custom detail, got %+v", payment)
+	}
+}
+
+func TestGRPCCodes(t *testing.T) {
+	for g := nghttp.GRPCOk; g <= nghttp.GRPCUnauthenticated; g++ {
+		if got := nghttp.CodeFromGRPC(g).GRPCCode(); got != g {
+			t.Fatalf("expected round trip of %s, got %s", g, got)
+		}
+	}
+
+	tests := []struct {
+		code nghttp.Code
+		grpc nghttp.GRPCCode
+	}{
+		{nghttp.CodeCanceled, nghttp.GRPCCanceled},
+		{nghttp.CodeBadRequest, nghttp.GRPCInvalidArgument},
+		{nghttp.CodeTooManyRequests, nghttp.GRPCResourceExhausted},
+		{CodePaymentRequired, nghttp.GRPCFailedPrecondition},
+		{"NOT_REGISTERED", nghttp.GRPCUnknown},
+	}
+	nghttp.RegisterCode(CodePaymentRequired, nghttp.CodeSpec{Status: http.StatusPaymentRequired})
+
+	for _, tt := range tests {
+		if got := tt.code.GRPCCode(); got != tt.grpc {
+			t.Fatalf("expected %s to be %s, got %s", tt.code, tt.grpc, got)
+		}
+	}
+
+	if nghttp.GRPCCanceled.String() != "CANCELLED" || nghttp.CodeFromGRPC(99) != nghttp.CodeUnknown {
+		t.Fatalf("unexpected gRPC code names")
+	}
+
+	resp := nghttp.FromGRPC(nghttp.GRPCNotFound, "user 1 not found")
+	if resp.StatusCode() != http.StatusNotFound || resp.Error() != "user 1 not found" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode(), resp.Error())
+	}
+}
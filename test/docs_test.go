@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+	ngdocs "github.com/foxie-io/ng/openapi/docs"
+)
+
+func TestDocsController(t *testing.T) {
+	app := ng.NewApp(
+		ng.WithPrefix("/api"),
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			if ng.MustLoad[*http.Request](ctx).Header.Get("Authorization") != "token" {
+				return nghttp.NewErrUnauthenticated()
+			}
+			return nil
+		})),
+	)
+	app.AddController(
+		&DocController{},
+		ngdocs.Controller(app, ngdocs.Options{
+			DocOptions: []ngopenapi.DocOption{ngopenapi.WithInfo(ngopenapi.Info{Title: "Private", Version: "1.0.0"})},
+		}),
+		ngdocs.Controller(app, ngdocs.Options{Path: "/public", Title: "Public API"}, ng.SkipAllGuards()),
+	)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(t *testing.T, path, token string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("private docs", func(t *testing.T) {
+		if resp, _ := get(t, "/api/docs/openapi.json", ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %d", resp.StatusCode)
+		}
+
+		resp, body := get(t, "/api/docs/openapi.json", "token")
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != ngdocs.ContentTypeJSON {
+			t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+
+		var doc ngopenapi.Document
+		if err := json.Unmarshal([]byte(body), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Info.Title != "Private" || doc.Paths["/api/users/{id}"]["get"] == nil {
+			t.Fatalf("unexpected document %s", body)
+		}
+		for path := range doc.Paths {
+			if strings.Contains(path, "openapi") || strings.HasPrefix(path, "/api/public") {
+				t.Fatalf("expected docs routes to be hidden, got %s", path)
+			}
+		}
+	})
+
+	t.Run("public docs", func(t *testing.T) {
+		resp, body := get(t, "/api/public/openapi.yaml", "")
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != ngdocs.ContentTypeYAML {
+			t.Fatalf("unexpected response %d %s", resp.StatusCode, body)
+		}
+		if !strings.HasPrefix(body, "openapi: 3.1.0\n") || !strings.Contains(body, "  /api/users/{id}:\n") {
+			t.Fatalf("unexpected yaml %s", body)
+		}
+
+		resp, body = get(t, "/api/public", "")
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+			t.Fatalf("unexpected ui response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		for _, s := range []string{"<title>Public API</title>", `var specURL = "/api/public/openapi.json";`, `href="/api/public/openapi.yaml"`} {
+			if !strings.Contains(body, s) {
+				t.Fatalf("expected ui to contain %s", s)
+			}
+		}
+		if strings.Contains(body, "<script src=") || strings.Contains(body, "https://cdn") {
+			t.Fatal("expected ui without external assets")
+		}
+	})
+}
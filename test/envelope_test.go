@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type EnvelopeController struct{}
+
+func (c *EnvelopeController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/jsend"), ng.WithEnvelope(nghttp.JSendEnvelope))
+}
+
+func (c *EnvelopeController) Get() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/ok",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(map[string]int{"id": 1}))
+		}),
+	)
+}
+
+func (c *EnvelopeController) Fail() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/fail",
+		ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("title", "required"))
+		}),
+	)
+}
+
+func (c *EnvelopeController) Error() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/error",
+		ng.WithHandler(func(ctx context.Context) error {
+			panic("boom")
+		}),
+	)
+}
+
+func (c *EnvelopeController) Bare() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/bare",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse([]string{"a", "b"}))
+		}),
+		ng.WithEnvelope(nghttp.DataEnvelope),
+	)
+}
+
+func (c *EnvelopeController) Own() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/own",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse("x", nghttp.WithEnvelope(nghttp.DefaultEnvelope)))
+		}),
+	)
+}
+
+func TestEnvelope(t *testing.T) {
+	var (
+		shared      = nghttp.NewErrNotFound()
+		sharedPanic = nghttp.NewPanicError("boom")
+	)
+
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithEnvelope(nghttp.GoogleEnvelope),
+	)
+	app.AddController(&EnvelopeController{})
+	app.AddRoute(
+		ng.NewRoute(http.MethodGet, "/google/ok", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse("hi"))
+		})),
+		ng.NewRoute(http.MethodGet, "/google/missing", ng.WithHandler(func(ctx context.Context) error {
+			return shared
+		})),
+		ng.NewRoute(http.MethodGet, "/google/panic", ng.WithHandler(func(ctx context.Context) error {
+			return sharedPanic
+		})),
+
+		// same sentinels behind another envelope
+		ng.NewRoute(http.MethodGet, "/shared/missing", ng.WithHandler(func(ctx context.Context) error {
+			return shared
+		}), ng.WithEnvelope(nghttp.JSendEnvelope)),
+		ng.NewRoute(http.MethodGet, "/shared/panic", ng.WithHandler(func(ctx context.Context) error {
+			return sharedPanic
+		}), ng.WithEnvelope(nghttp.JSendEnvelope)),
+	)
+	app.Build()
+
+	for _, r := range app.Routes() {
+		if r.Path() == "/jsend/bare" && ng.RouteEnvelope(r) != nghttp.DataEnvelope {
+			t.Fatalf("expected route envelope to win")
+		}
+	}
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		accept   string
+		status   int
+		expected string
+	}{
+		{"/jsend/ok", "", 200, `{"status":"success","data":{"id":1}}`},
+		{"/jsend/fail", "", 400, `{"status":"fail","data":{"title":"required"},"message":"invalid argument","code":"INVALID_ARGUMENT"}`},
+		{"/jsend/error", "", 500, `{"status":"error","data":null,"message":"unknown","code":"UNKNOWN"}`},
+		{"/jsend/bare", "", 200, `["a","b"]`},
+		{"/jsend/own", "", 200, `{"code":"OK","data":"x"}`},
+		{"/google/ok", "", 200, `{"data":"hi"}`},
+		{"/google/ok", ng.MIMEApplicationXML, 200, `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><data>hi</data></response>`},
+		{"/google/missing", "", 404, `{"error":{"code":404,"status":"NOT_FOUND","message":"not found"}}`},
+		{"/google/panic", "", 500, `{"error":{"code":500,"status":"UNKNOWN","message":"unknown"}}`},
+		{"/shared/missing", "", 404, `{"status":"fail","data":null,"message":"not found","code":"NOT_FOUND"}`},
+		{"/shared/panic", "", 500, `{"status":"error","data":null,"message":"unknown","code":"UNKNOWN"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+tt.accept, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status || string(body) != tt.expected {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, tt.expected, resp.StatusCode, body)
+			}
+		})
+	}
+
+	if shared.Envelope() != nil || sharedPanic.Response().(*nghttp.Response).Envelope() != nil {
+		t.Fatalf("shared responses should not be mutated")
+	}
+}
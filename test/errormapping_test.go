@@ -0,0 +1,140 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+var errDiskFull = errors.New("disk full")
+
+type QuotaError struct{ Limit int }
+
+func (e *QuotaError) Error() string { return "quota exceeded" }
+
+type MappingController struct{}
+
+func (c *MappingController) InitializeController() ng.Controller {
+	return ng.NewController(
+		ng.WithPrefix("/mapping"),
+		ng.WithErrorMapping(ng.MapErrorAs(func(err *QuotaError) nghttp.HTTPResponse {
+			return nghttp.NewErrResourceExhausted().Update(nghttp.Meta("limit", err.Limit))
+		})),
+	)
+}
+
+func (c *MappingController) Missing() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/missing",
+		ng.WithHandler(func(ctx context.Context) error {
+			return sql.ErrNoRows
+		}),
+	)
+}
+
+func (c *MappingController) Quota() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/quota",
+		ng.WithHandler(func(ctx context.Context) error {
+			return errors.Join(errors.New("upload"), &QuotaError{Limit: 10})
+		}),
+	)
+}
+
+func (c *MappingController) Gone() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/gone",
+		ng.WithHandler(func(ctx context.Context) error {
+			return sql.ErrNoRows
+		}),
+		ng.WithErrorMapping(ng.MapError(sql.ErrNoRows, func(err error) nghttp.HTTPResponse {
+			return nghttp.NewErrNotFound().Update(nghttp.WithStatusCode(http.StatusGone))
+		})),
+	)
+}
+
+func (c *MappingController) Unmapped() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/unmapped",
+		ng.WithHandler(func(ctx context.Context) error {
+			return errDiskFull
+		}),
+	)
+}
+
+func isNoRows(err error) bool { return errors.Is(err, sql.ErrNoRows) }
+
+func isQuotaError(err error) bool {
+	var quota *QuotaError
+	return errors.As(err, &quota)
+}
+
+func TestErrorMapping(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	var cause error
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithErrorMapping(ng.MapError(sql.ErrNoRows, func(err error) nghttp.HTTPResponse {
+			return nghttp.NewErrNotFound()
+		})),
+		ng.WithMiddleware(ng.MiddlewareFunc(func(ctx context.Context, next ng.Handler) {
+			next(ctx)
+			cause, _ = ng.GetContext(ctx).GetResponse().(error)
+		})),
+	)
+	app.AddController(&MappingController{})
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		status   int
+		expected string
+		cause    func(err error) bool
+	}{
+		{"/mapping/missing", 404, `{"code":"NOT_FOUND","message":"not found"}`, isNoRows},
+		{"/mapping/quota", 429, `{"code":"RESOURCE_EXHAUSTED","message":"resource exhausted","meta":{"limit":10}}`, isQuotaError},
+		{"/mapping/gone", 410, `{"code":"NOT_FOUND","message":"not found"}`, isNoRows},
+		{"/mapping/unmapped", 500, `{"code":"UNKNOWN","message":"unknown"}`, func(err error) bool { return errors.Is(err, errDiskFull) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.status || string(body) != tt.expected {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, tt.expected, res.StatusCode, body)
+			}
+
+			// responses keep the original error as cause
+			if !tt.cause(cause) {
+				t.Fatalf("unexpected cause %v", cause)
+			}
+		})
+	}
+
+	// library code leaves logging of unmapped errors to the app
+	if logs.Len() != 0 {
+		t.Fatalf("expected no logs, got %s", logs.String())
+	}
+}
@@ -0,0 +1,291 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngws "github.com/foxie-io/ng/ws"
+)
+
+type chatUser string
+
+func TestGateway(t *testing.T) {
+	var (
+		intercepted  atomic.Int32
+		disconnected = make(chan string, 4)
+	)
+
+	chat := ng.NewGateway("/ws/chat",
+		ng.WithMiddleware(ng.MiddlewareFunc(func(ctx context.Context, next ng.Handler) {
+			r := ng.MustLoad[*http.Request](ctx)
+			ng.Store(ctx, chatUser(r.URL.Query().Get("user")))
+			next(ctx)
+		})),
+		ng.WithInterceptor(ng.InterceptorFunc(func(ctx context.Context, next ng.Handler) {
+			intercepted.Add(1)
+			next(ctx)
+		})),
+		ng.OnConnect(func(ctx context.Context, client *ng.GatewayClient) error {
+			client.Join("lobby")
+			return nil
+		}),
+		ng.OnDisconnect(func(ctx context.Context, client *ng.GatewayClient, err error) {
+			disconnected <- string(ng.MustLoad[chatUser](ctx))
+		}),
+		ng.OnMessage("whoami", func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(ng.MustLoad[chatUser](ctx)))
+		}),
+		ng.OnMessage("chat.send", ng.ScopeHandler(func() ng.Handler {
+			var body struct {
+				Room string `json:"room"`
+				Text string `json:"text"`
+			}
+
+			return ng.Handle(
+				ng.BindMessage(&body),
+				func(ctx context.Context) error {
+					client := ng.MustLoad[*ng.GatewayClient](ctx)
+					return client.Gateway().To(body.Room).Except(client).Emit("chat.message", map[string]string{
+						"from": string(ng.MustLoad[chatUser](ctx)),
+						"text": body.Text,
+					})
+				},
+			)
+		})),
+	)
+
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			if ng.MustLoad[*http.Request](ctx).URL.Query().Get("user") == "" {
+				return nghttp.NewErrUnauthenticated()
+			}
+			return nil
+		})),
+	)
+	app.AddController(chat)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/chat"
+
+	dial := func(t *testing.T, user string) *ngws.Conn {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		conn, _, err := ngws.Dial(ctx, wsURL+"?user="+user, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	send := func(t *testing.T, conn *ngws.Conn, frame string) {
+		t.Helper()
+		if err := conn.WriteMessage(ngws.TextMessage, []byte(frame)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(t *testing.T, conn *ngws.Conn) map[string]any {
+		t.Helper()
+		_ = conn.NetConn().SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		frame := map[string]any{}
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatal(err)
+		}
+		return frame
+	}
+
+	t.Run("guard rejects upgrade", func(t *testing.T) {
+		_, resp, err := ngws.Dial(context.Background(), wsURL, nil)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected 401, got %v %v", resp, err)
+		}
+	})
+
+	t.Run("plain request is rejected", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/ws/chat?user=alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		// same origin and non-browser clients without Origin are allowed
+		for _, origin := range []string{server.URL, ""} {
+			header := http.Header{}
+			if origin != "" {
+				header.Set("Origin", origin)
+			}
+			conn, _, err := ngws.Dial(ctx, wsURL+"?user=origin", header)
+			if err != nil {
+				t.Fatalf("origin %q: %v", origin, err)
+			}
+			conn.Close()
+
+			select {
+			case <-disconnected:
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected disconnect hook")
+			}
+		}
+
+		_, resp, err := ngws.Dial(ctx, wsURL+"?user=origin", http.Header{"Origin": {"https://evil.example"}})
+		if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected 403 for foreign origin, got %v %v", resp, err)
+		}
+
+		// rejection code follows the status
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/ws/chat?user=origin", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Origin", "https://evil.example")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusForbidden || string(body) != `{"code":"PERMISSION_DENIED","message":"origin not allowed"}` {
+			t.Fatalf("unexpected rejection %d %s", res.StatusCode, body)
+		}
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		alice := dial(t, "alice")
+		bob := dial(t, "bob")
+
+		send(t, alice, `{"event":"whoami","id":"1"}`)
+		if frame := read(t, alice); frame["event"] != "whoami" || frame["id"] != "1" || frame["code"] != "OK" || frame["data"] != "alice" {
+			t.Fatalf("unexpected reply %v", frame)
+		}
+
+		send(t, alice, `{"event":"chat.send","data":{"room":"lobby","text":"hi"}}`)
+		if frame := read(t, bob); frame["event"] != "chat.message" || frame["data"].(map[string]any)["from"] != "alice" {
+			t.Fatalf("unexpected broadcast %v", frame)
+		}
+
+		send(t, bob, `{"event":"unknown","id":"2"}`)
+		if frame := read(t, bob); frame["code"] != string(nghttp.CodeNotFound) || frame["id"] != "2" {
+			t.Fatalf("unexpected error reply %v", frame)
+		}
+
+		send(t, bob, `not json`)
+		if frame := read(t, bob); frame["event"] != "error" || frame["code"] != string(nghttp.CodeInvalidArgument) {
+			t.Fatalf("unexpected error reply %v", frame)
+		}
+
+		if n := intercepted.Load(); n != 3 {
+			t.Fatalf("expected 3 intercepted messages, got %d", n)
+		}
+
+		_ = alice.WriteClose(ngws.CloseNormal, "")
+		select {
+		case user := <-disconnected:
+			if user != "alice" {
+				t.Fatalf("expected alice to disconnect, got %s", user)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected disconnect hook")
+		}
+
+		if n := len(chat.Clients()); n != 1 {
+			t.Fatalf("expected 1 client left, got %d", n)
+		}
+		bob.Close()
+	})
+}
+
+func TestUpgradeCheckOrigin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ngws.Upgrade(w, r, nil, ngws.WithCheckOrigin(func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://app.example"
+		}))
+		if err != nil {
+			http.Error(w, err.Error(), err.(*ngws.HandshakeError).Status)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := ngws.Dial(ctx, wsURL, http.Header{"Origin": {"https://app.example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	for _, origin := range []string{server.URL, ""} {
+		_, resp, err := ngws.Dial(ctx, wsURL, http.Header{"Origin": {origin}})
+		if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("origin %q: expected 403, got %v %v", origin, resp, err)
+		}
+	}
+}
+
+func TestWriteCloseReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ngws.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// 2 byte runes, 125 byte payload limit falls inside one
+		_ = conn.WriteClose(ngws.CloseNormal, strings.Repeat("é", 100))
+		conn.Close()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, _, err := ngws.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var closeErr *ngws.CloseError
+	if _, _, err := conn.ReadMessage(); !errors.As(err, &closeErr) {
+		t.Fatalf("expected close error, got %v", err)
+	}
+	if !utf8.ValidString(closeErr.Text) || closeErr.Text != strings.Repeat("é", 61) {
+		t.Fatalf("expected reason cut at rune boundary, got %q", closeErr.Text)
+	}
+}
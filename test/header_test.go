@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type HeaderController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *HeaderController) Session() ng.Route {
+	return ng.NewRoute(http.MethodPost, "/session",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse("created",
+				nghttp.WithStatusCode(http.StatusCreated),
+				nghttp.WithHeader("Location", "/session/1"),
+				nghttp.WithHeader("Cache-Control", "no-store"),
+				nghttp.WithCookie(&http.Cookie{Name: "sid", Value: "abc", HttpOnly: true}),
+				nghttp.WithTrailer("X-Checksum", "42"),
+			))
+		}),
+	)
+}
+
+func (c *HeaderController) Limited() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/limited",
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			return nghttp.NewErrTooManyRequests().Update(
+				nghttp.WithHeader("RateLimit-Remaining", "0"),
+				nghttp.WithHeader("Retry-After", "30"),
+			)
+		})),
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.EmptyResponse())
+		}),
+	)
+}
+
+func TestResponseHeaders(t *testing.T) {
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&HeaderController{})
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("headers cookies trailers", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/session", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.ReadAll(resp.Body)
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Location") != "/session/1" || resp.Header.Get("Cache-Control") != "no-store" {
+			t.Fatalf("unexpected headers %v", resp.Header)
+		}
+		if cookies := resp.Cookies(); len(cookies) != 1 || cookies[0].Name != "sid" || !cookies[0].HttpOnly {
+			t.Fatalf("unexpected cookies %v", cookies)
+		}
+		if resp.Trailer.Get("X-Checksum") != "42" {
+			t.Fatalf("unexpected trailers %v", resp.Trailer)
+		}
+	})
+
+	t.Run("guard error headers", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/limited")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Retry-After") != "30" || resp.Header.Get("RateLimit-Remaining") != "0" {
+			t.Fatalf("unexpected headers %v", resp.Header)
+		}
+	})
+}
+
+func TestResponseWithClonesHeaders(t *testing.T) {
+	base := nghttp.NewErrNotFound().Update(nghttp.WithHeader("X-Base", "1"))
+	copy := base.With(nghttp.WithHeader("X-Copy", "1"))
+
+	if base.Headers().Get("X-Copy") != "" {
+		t.Fatal("expected With to not mutate original headers")
+	}
+	if copy.Headers().Get("X-Base") != "1" {
+		t.Fatal("expected copy to keep original headers")
+	}
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type MountController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *MountController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/mount"))
+}
+
+func (c *MountController) Hello() ng.Route {
+	return ng.NewHTTPRoute(http.MethodGet, "/hello", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("hello"))
+	}))
+}
+
+func (c *MountController) Private() ng.Route {
+	return ng.NewHTTPRoute(http.MethodGet, "/private", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}),
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			return nghttp.NewErrPermissionDenied()
+		})),
+	)
+}
+
+func (c *MountController) Files() ng.Route {
+	return ng.NewHTTPRoute(http.MethodGet, "/files/{path...}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}),
+		ng.WithStripPrefix(),
+	)
+}
+
+func TestHTTPRoute(t *testing.T) {
+	var statuses []int
+
+	app := ng.NewApp(
+		ng.WithPrefix("/api"),
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithMiddleware(ng.MiddlewareFunc(func(ctx context.Context, next ng.Handler) {
+			next(ctx)
+			statuses = append(statuses, ng.GetContext(ctx).GetResponse().StatusCode())
+		})),
+	)
+
+	app.AddController(&MountController{})
+	app.AddRoute(ng.NewHTTPRoute(http.MethodGet, "/legacy/{path...}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}), ng.WithStripPrefix("/api/legacy")))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("handler writes directly", testMuxtEndpoint(server.URL+"/api/mount/hello", http.MethodGet, "hello", http.StatusAccepted))
+	t.Run("guard denies mounted handler", testMuxtEndpoint(server.URL+"/api/mount/private", http.MethodGet, `{"code":"PERMISSION_DENIED","message":"permission denied"}`, http.StatusForbidden))
+	t.Run("strip static prefix", testMuxtEndpoint(server.URL+"/api/mount/files/a/b.txt", http.MethodGet, "/a/b.txt", http.StatusOK))
+	t.Run("strip explicit prefix", testMuxtEndpoint(server.URL+"/api/legacy/x", http.MethodGet, "/x", http.StatusOK))
+
+	expected := []int{http.StatusAccepted, http.StatusForbidden, http.StatusOK, http.StatusOK}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, statuses)
+		}
+	}
+}
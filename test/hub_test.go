@@ -0,0 +1,158 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+func TestHubBackpressure(t *testing.T) {
+	t.Run("drop oldest", func(t *testing.T) {
+		hub := ng.NewHub(ng.WithHubBuffer(2))
+		sub, err := hub.Subscribe(context.Background(), "order.updated")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+
+		for i := 1; i <= 4; i++ {
+			_ = hub.Publish("order.updated", i)
+		}
+		_ = hub.Publish("order.created", 99)
+
+		for _, expected := range []int{3, 4} {
+			msg, err := sub.Next(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Data != expected || msg.Event != "order.updated" {
+				t.Fatalf("expected %d, got %+v", expected, msg)
+			}
+		}
+
+		if sub.Dropped() != 2 {
+			t.Fatalf("expected 2 dropped, got %d", sub.Dropped())
+		}
+	})
+
+	t.Run("disconnect slow subscriber", func(t *testing.T) {
+		hub := ng.NewHub(ng.WithHubBuffer(1), ng.WithBackpressure(ng.BackpressureDisconnect))
+		sub, _ := hub.Subscribe(context.Background(), "order.updated")
+		defer sub.Close()
+
+		_ = hub.Publish("order.updated", 1)
+		_ = hub.Publish("order.updated", 2)
+
+		<-sub.Done()
+		if !errors.Is(sub.Err(), ng.ErrSlowSubscriber) {
+			t.Fatalf("expected ErrSlowSubscriber, got %v", sub.Err())
+		}
+
+		msg, err := sub.Next(context.Background())
+		if err != nil || msg.Data != 1 {
+			t.Fatalf("expected buffered message 1, got %v, %v", msg.Data, err)
+		}
+		if _, err := sub.Next(context.Background()); !errors.Is(err, ng.ErrSlowSubscriber) {
+			t.Fatalf("expected ErrSlowSubscriber, got %v", err)
+		}
+	})
+
+	t.Run("pattern topic", func(t *testing.T) {
+		hub := ng.NewHub()
+		if _, err := hub.Subscribe(context.Background(), "order.*"); !errors.Is(err, ng.ErrTopicPattern) {
+			t.Fatalf("expected ErrTopicPattern, got %v", err)
+		}
+	})
+
+	t.Run("closed with context", func(t *testing.T) {
+		hub := ng.NewHub()
+		ctx, cancel := context.WithCancel(context.Background())
+		sub, _ := hub.Subscribe(ctx, "order.updated")
+
+		cancel()
+		<-sub.Done()
+		if !errors.Is(sub.Err(), ng.ErrSubscriptionClosed) {
+			t.Fatalf("expected ErrSubscriptionClosed, got %v", sub.Err())
+		}
+	})
+}
+
+func TestSubscriptionRoute(t *testing.T) {
+	hub := ng.NewHub()
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddRoute(ng.NewSubscriptionRoute("/events", hub,
+		ng.WithTopics("order.updated", "order.*"),
+		ng.WithGuards(ng.TopicGuard(func(ctx context.Context, topic string) error {
+			if topic == "order.secret" {
+				return nghttp.NewErrPermissionDenied()
+			}
+			return nil
+		})),
+	))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(t *testing.T, ctx context.Context, query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events"+query, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resp := get(t, ctx, "?topic=order.42")
+		defer resp.Body.Close()
+
+		_ = hub.Publish("order.updated", "ignored")
+		_ = hub.Publish("order.42", ng.HubMessage{ID: "1", Data: map[string]string{"status": "paid"}})
+
+		reader := bufio.NewReader(resp.Body)
+		var lines []string
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+
+		expected := []string{"id: 1", "event: order.42", `data: {"status":"paid"}`}
+		if strings.Join(lines, "|") != strings.Join(expected, "|") {
+			t.Fatalf("expected %v, got %v", expected, lines)
+		}
+	})
+
+	t.Run("rejected topics", func(t *testing.T) {
+		for query, status := range map[string]int{
+			"?topic=order.secret": http.StatusForbidden,
+			"?topic=user.1":       http.StatusBadRequest,
+			"?topic=order.*":      http.StatusBadRequest,
+		} {
+			resp := get(t, context.Background(), query)
+			resp.Body.Close()
+			if resp.StatusCode != status {
+				t.Fatalf("%s: expected %d, got %d", query, status, resp.StatusCode)
+			}
+		}
+	})
+}
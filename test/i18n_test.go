@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngi18n "github.com/foxie-io/ng/i18n"
+)
+
+func newTestCatalog(t *testing.T) *ngi18n.Catalog {
+	catalog := ngi18n.NewCatalog("en").
+		Add("en", map[string]string{
+			"NOT_FOUND": "{{.resource}} not found",
+			"required":  "{{.field}} is required",
+			"welcome":   "Welcome, {{.name}}!",
+		}).
+		AddPlural("en", "cart.items", ngi18n.Plural{One: "{{.count}} item left", Other: "{{.count}} items left"}).
+		Add("fr", map[string]string{
+			"NOT_FOUND": "{{.resource}} introuvable",
+			"required":  "le champ {{.field}} est obligatoire",
+			"welcome":   "Bienvenue, {{.name}} !",
+		})
+
+	err := catalog.AddJSON("ru", []byte(`{
+		"cart.items": {"one": "остался {{.count}} товар", "few": "осталось {{.count}} товара", "many": "осталось {{.count}} товаров"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return catalog
+}
+
+func TestI18n(t *testing.T) {
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithI18n(newTestCatalog(t)),
+	)
+	app.AddRoute(
+		ng.NewRoute(http.MethodGet, "/users/{id}", ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrNotFound().Update(nghttp.Meta("resource", "user"))
+		})),
+		ng.NewRoute(http.MethodGet, "/orders/{id}", ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrNotFound()
+		})),
+		ng.NewRoute(http.MethodGet, "/cart", ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrResourceExhausted().Update(nghttp.WithMessageID("cart.items", "count", 3))
+		})),
+		ng.NewRoute(http.MethodPost, "/signup", ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrInvalidArgument().Update(nghttp.WithDetails(&nghttp.BadRequest{
+				FieldViolations: []nghttp.FieldViolation{{Field: "email", Description: "email is required", Reason: "required"}},
+			}))
+		})),
+		ng.NewRoute(http.MethodGet, "/welcome", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(ng.T(ctx, "welcome", "name", "Ana")))
+		})),
+	)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   map[string]string
+		language string
+		expected string
+	}{
+		{"code message", "GET", "/users/1", map[string]string{"Accept-Language": "fr-CH, en;q=0.8"}, "fr",
+			`{"code":"NOT_FOUND","message":"user introuvable","meta":{"resource":"user"}}`},
+		{"fallback locale", "GET", "/users/1", map[string]string{"Accept-Language": "de"}, "en",
+			`{"code":"NOT_FOUND","message":"user not found","meta":{"resource":"user"}}`},
+		{"query wins", "GET", "/users/1?lang=en", map[string]string{"Accept-Language": "fr"}, "en",
+			`{"code":"NOT_FOUND","message":"user not found","meta":{"resource":"user"}}`},
+		{"cookie", "GET", "/users/1", map[string]string{"Cookie": "lang=fr"}, "fr",
+			`{"code":"NOT_FOUND","message":"user introuvable","meta":{"resource":"user"}}`},
+		{"missing argument", "GET", "/orders/1", map[string]string{"Accept-Language": "fr"}, "",
+			`{"code":"NOT_FOUND","message":"not found"}`},
+		{"plural", "GET", "/cart", map[string]string{"Accept-Language": "ru"}, "ru",
+			`{"code":"RESOURCE_EXHAUSTED","message":"осталось 3 товара"}`},
+		{"plural fallback", "GET", "/cart", map[string]string{"Accept-Language": "fr"}, "en",
+			`{"code":"RESOURCE_EXHAUSTED","message":"3 items left"}`},
+		{"unsupported query", "GET", "/cart?lang=ja", nil, "en",
+			`{"code":"RESOURCE_EXHAUSTED","message":"3 items left"}`},
+		{"field violations", "POST", "/signup", map[string]string{"Accept-Language": "fr"}, "fr",
+			`{"code":"INVALID_ARGUMENT","message":"invalid argument","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"email","description":"le champ email est obligatoire","reason":"required"}]}]}`},
+		{"handler translation", "GET", "/welcome", map[string]string{"Accept-Language": "fr"}, "",
+			`{"code":"OK","data":"Bienvenue, Ana !"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if string(body) != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, body)
+			}
+
+			if tt.language != "" && res.Header.Get("Content-Language") != tt.language {
+				t.Fatalf("expected Content-Language %s, got %q", tt.language, res.Header.Get("Content-Language"))
+			}
+		})
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale   string
+		count    any
+		expected ngi18n.Category
+	}{
+		{"en", 1, ngi18n.One},
+		{"en", 0, ngi18n.Other},
+		{"fr", 0, ngi18n.One},
+		{"ru", 21, ngi18n.One},
+		{"ru", 22, ngi18n.Few},
+		{"ru", 12, ngi18n.Many},
+		{"pl", 25, ngi18n.Many},
+		{"ar", 2, ngi18n.Two},
+		{"ja", 1, ngi18n.Other},
+		{"en", "n/a", ngi18n.Other},
+	}
+
+	for _, tt := range tests {
+		if got := ngi18n.PluralCategory(tt.locale, tt.count); got != tt.expected {
+			t.Fatalf("expected %s for %v in %s, got %s", tt.expected, tt.count, tt.locale, got)
+		}
+	}
+}
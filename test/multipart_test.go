@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type UploadRequest struct {
+	Title string             `form:"title"`
+	Tags  []string           `form:"tag"`
+	Count int                `form:"count"`
+	Files []*ng.UploadedFile `form:"file"`
+}
+
+type UploadResult struct {
+	Title   string   `json:"title"`
+	Tags    []string `json:"tags"`
+	Count   int      `json:"count"`
+	Names   []string `json:"names"`
+	Types   []string `json:"types"`
+	Sizes   []int64  `json:"sizes"`
+	Spooled []bool   `json:"spooled"`
+	Content []string `json:"content"`
+}
+
+func TestBindMultipart(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddRoute(ng.NewRoute(http.MethodPost, "/upload",
+		ng.WithScopeHandler(func() ng.Handler {
+			var body UploadRequest
+			return ng.Handle(
+				ng.BindMultipart(&body),
+				func(ctx context.Context) error {
+					result := UploadResult{Title: body.Title, Tags: body.Tags, Count: body.Count}
+					for _, f := range body.Files {
+						r, err := f.Open()
+						if err != nil {
+							return err
+						}
+						content, _ := io.ReadAll(r)
+						r.Close()
+
+						result.Names = append(result.Names, f.Name)
+						result.Types = append(result.Types, f.ContentType)
+						result.Sizes = append(result.Sizes, f.Size)
+						result.Spooled = append(result.Spooled, f.Spooled())
+						result.Content = append(result.Content, string(content[:min(len(content), 5)]))
+					}
+					return ng.Respond(ctx, nghttp.NewResponse(result))
+				},
+			)
+		}),
+		ng.WithUploadLimits(ng.UploadLimits{MaxFileSize: 64, MaxTotalSize: 100, MaxFieldSize: 32, SpoolThreshold: 16}),
+		ng.WithAllowedUploadTypes("text/*"),
+	))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	type part struct{ field, filename, content string }
+	post := func(t *testing.T, parts ...part) (int, string) {
+		t.Helper()
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for _, p := range parts {
+			if p.filename == "" {
+				_ = mw.WriteField(p.field, p.content)
+				continue
+			}
+			w, _ := mw.CreateFormFile(p.field, p.filename)
+			_, _ = w.Write([]byte(p.content))
+		}
+		mw.Close()
+
+		resp, err := http.Post(server.URL+"/upload", mw.FormDataContentType(), &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("fields and files", func(t *testing.T) {
+		status, body := post(t,
+			part{"title", "", "report"},
+			part{"tag", "", "a"},
+			part{"tag", "", "b"},
+			part{"count", "", "2"},
+			part{"file", "small.txt", "hello"},
+			part{"file", "../big.txt", strings.Repeat("x", 40)},
+		)
+		if status != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", status, body)
+		}
+
+		expected := `{"code":"OK","data":{"title":"report","tags":["a","b"],"count":2,` +
+			`"names":["small.txt","big.txt"],"types":["text/plain; charset=utf-8","text/plain; charset=utf-8"],` +
+			`"sizes":[5,40],"spooled":[false,true],"content":["hello","xxxxx"]}}`
+		if body != expected {
+			t.Fatalf("expected %s, got %s", expected, body)
+		}
+
+		// spooled files are removed once the request context is cleared
+		entries, _ := os.ReadDir(tmp)
+		if len(entries) != 0 {
+			t.Fatalf("expected temp files to be removed, found %d", len(entries))
+		}
+	})
+
+	t.Run("rejected uploads", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			parts  []part
+			status int
+			code   string
+		}{
+			{"file too large", []part{{"file", "a.txt", strings.Repeat("x", 65)}}, 413, `"code":"PAYLOAD_TOO_LARGE"`},
+			{"total too large", []part{{"file", "a.txt", strings.Repeat("x", 60)}, {"file", "b.txt", strings.Repeat("x", 60)}}, 413, `"maxTotalSize":100`},
+			{"type not allowed", []part{{"file", "a.png", "\x89PNG\r\n\x1a\n0000"}}, 415, `"contentType":"image/png"`},
+			{"field too large", []part{{"title", "", strings.Repeat("x", 33)}}, 413, `"maxFieldSize":32`},
+			{"invalid value", []part{{"count", "", "two"}}, 400, `"field":"count"`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				status, body := post(t, tt.parts...)
+				if status != tt.status || !strings.Contains(body, tt.code) {
+					t.Fatalf("expected %d with %s, got %d %s", tt.status, tt.code, status, body)
+				}
+			})
+		}
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/upload", "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Fatalf("expected 415, got %d", resp.StatusCode)
+		}
+	})
+}
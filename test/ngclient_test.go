@@ -0,0 +1,206 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	ngclient "github.com/foxie-io/ng/client"
+	nghttp "github.com/foxie-io/ng/http"
+	ngproblem "github.com/foxie-io/ng/problem"
+)
+
+type ClientItem struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags,omitempty"`
+	Owner string   `json:"owner,omitempty"`
+}
+
+type ClientCreateItem struct {
+	Owner string `header:"X-Owner"`
+	Name  string `json:"name"`
+}
+
+type ClientItemController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *ClientItemController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/items"))
+}
+
+func (c *ClientItemController) Get() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/{id}",
+		ng.WithTypedHandler(func(ctx context.Context, req struct {
+			ID int `path:"id"`
+		}) (*ClientItem, error) {
+			if req.ID != 1 {
+				return nil, nghttp.NewErrNotFound().Update(nghttp.Meta("id", req.ID))
+			}
+			return &ClientItem{ID: 1, Name: "first"}, nil
+		}),
+	)
+}
+
+func (c *ClientItemController) List() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/",
+		ng.WithTypedHandler(func(ctx context.Context, req struct {
+			Tags []string `query:"tag"`
+		}) ([]ClientItem, error) {
+			return []ClientItem{{ID: 1, Name: "first", Tags: req.Tags}}, nil
+		}),
+		ng.WithEnvelope(nghttp.DataEnvelope),
+	)
+}
+
+func (c *ClientItemController) Create() ng.Route {
+	return ng.NewRoute(http.MethodPost, "/",
+		ng.WithTypedHandler(func(ctx context.Context, req ClientCreateItem) (ClientItem, error) {
+			return ClientItem{ID: 2, Name: req.Name, Owner: req.Owner}, nil
+		}),
+	)
+}
+
+func TestGenerateClient(t *testing.T) {
+	app := ng.NewApp(ng.WithPrefix("/api"), ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&ClientItemController{})
+	app.AddRoute(ng.NewRoute(http.MethodGet, "/health", ng.WithHandler(func(ctx context.Context) error { return nil })))
+	app.Build()
+
+	src, err := ngclient.Generate(app, ngclient.Config{Package: "items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "client_gen.go", src, parser.AllErrors); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+
+	for _, s := range []string{
+		"// Code generated by ngclient. DO NOT EDIT.",
+		"package items",
+		`ngclient "github.com/foxie-io/ng/client"`,
+		`"github.com/foxie-io/ng/test"`,
+		"func NewClient(baseURL string, opts ...ngclient.Option) *Client {",
+		"func (c *Client) ClientItemGet(ctx context.Context, req struct {\n\tID int `path:\"id\"`\n}) (*test.ClientItem, error) {",
+		`return ngclient.Do[*test.ClientItem](ctx, c.Client, ngclient.Call{Method: http.MethodGet, Path: "/api/items/{id}"}, req)`,
+		`ngclient.Call{Method: http.MethodGet, Path: "/api/items", Bare: true}`,
+		"func (c *Client) ClientItemCreate(ctx context.Context, req test.ClientCreateItem) (test.ClientItem, error) {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("expected generated client to contain %q, got\n%s", s, src)
+		}
+	}
+	if strings.Contains(string(src), "Health") {
+		t.Fatal("expected untyped routes to be skipped")
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	app := ng.NewApp(ng.WithPrefix("/api"), ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&ClientItemController{})
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := ngclient.New(server.URL, ngclient.WithHeader("X-Owner", "alice"))
+	ctx := context.Background()
+
+	t.Run("typed response", func(t *testing.T) {
+		item, err := ngclient.Do[*ClientItem](ctx, c, ngclient.Call{Method: http.MethodGet, Path: "/api/items/{id}"}, struct {
+			ID int `path:"id"`
+		}{1})
+		if err != nil || item.Name != "first" {
+			t.Fatalf("unexpected item %+v, %v", item, err)
+		}
+
+		items, err := ngclient.Do[[]ClientItem](ctx, c, ngclient.Call{Method: http.MethodGet, Path: "/api/items", Bare: true}, struct {
+			Tags []string `query:"tag"`
+		}{[]string{"a", "b"}})
+		if err != nil || !reflect.DeepEqual(items[0].Tags, []string{"a", "b"}) {
+			t.Fatalf("unexpected items %+v, %v", items, err)
+		}
+
+		created, err := ngclient.Do[ClientItem](ctx, c, ngclient.Call{Method: http.MethodPost, Path: "/api/items"}, ClientCreateItem{Name: "second"})
+		if err != nil || !reflect.DeepEqual(created, ClientItem{ID: 2, Name: "second", Owner: "alice"}) {
+			t.Fatalf("unexpected created item %+v, %v", created, err)
+		}
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		_, err := ngclient.Do[*ClientItem](ctx, c, ngclient.Call{Method: http.MethodGet, Path: "/api/items/{id}"}, struct {
+			ID int `path:"id"`
+		}{7})
+
+		var resp *nghttp.Response
+		if !errors.As(err, &resp) || resp.Code != nghttp.CodeNotFound || resp.StatusCode() != http.StatusNotFound || resp.Meta["id"] != float64(7) {
+			t.Fatalf("expected NOT_FOUND response, got %#v", err)
+		}
+		if !nghttp.HasCode(err, nghttp.CodeNotFound) {
+			t.Fatal("expected error to match NOT_FOUND")
+		}
+	})
+
+	t.Run("problem response", func(t *testing.T) {
+		app := ng.NewApp(ng.WithPrefix("/api"), ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler), ng.WithErrorFormat(ngproblem.Format))
+		app.AddController(&ClientItemController{})
+		app.Build()
+
+		mux := http.NewServeMux()
+		ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		_, err := ngclient.Do[*ClientItem](ctx, ngclient.New(server.URL), ngclient.Call{Method: http.MethodGet, Path: "/api/items/{id}"}, struct {
+			ID int `path:"id"`
+		}{7})
+
+		var resp *nghttp.Response
+		if !errors.As(err, &resp) || resp.Code != nghttp.CodeNotFound || resp.StatusCode() != http.StatusNotFound || resp.Meta["id"] != float64(7) {
+			t.Fatalf("expected NOT_FOUND response, got %#v", err)
+		}
+		if resp.Error() != nghttp.NewErrNotFound().Error() {
+			t.Fatalf("expected detail as message, got %q", resp.Error())
+		}
+		if _, ok := resp.Meta["instance"]; ok {
+			t.Fatalf("expected standard members outside meta, got %v", resp.Meta)
+		}
+	})
+
+	t.Run("foreign error bodies", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			status int
+			body   string
+			code   nghttp.Code
+			msg    string
+		}{
+			{"google", 404, `{"error":{"code":404,"status":"NOT_FOUND","message":"no item"}}`, nghttp.CodeNotFound, "no item"},
+			{"jsend", 400, `{"status":"fail","data":{"field":"name"},"message":"bad name","code":"INVALID_ARGUMENT"}`, nghttp.CodeInvalidArgument, "bad name"},
+			{"problem", 409, `{"type":"about:blank","title":"Conflict","status":409,"detail":"name taken","code":"ALREADY_EXISTS","name":"a"}`, nghttp.CodeAlreadyExists, "name taken"},
+			{"problem without code", 404, `{"type":"about:blank","title":"Not Found","status":404}`, nghttp.CodeNotFound, "Not Found"},
+			{"text", 503, "maintenance", nghttp.CodeUnavailable, "maintenance"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				resp := ngclient.ParseError(tt.status, []byte(tt.body))
+				if resp.Code != tt.code || resp.Error() != tt.msg || resp.StatusCode() != tt.status {
+					t.Fatalf("unexpected response %s %q %d", resp.Code, resp.Error(), resp.StatusCode())
+				}
+			})
+		}
+	})
+}
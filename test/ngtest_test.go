@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+	"github.com/foxie-io/ng/ngtest"
+)
+
+type role string
+
+type roleKey struct{}
+
+type roleGuard struct {
+	ng.DefaultID[roleGuard]
+}
+
+func (roleGuard) Allow(ctx context.Context) error {
+	required, ok := ng.GetContext(ctx).Route().Core().Metadata(roleKey{})
+	if !ok {
+		return nil
+	}
+
+	if current, _ := ng.Load[role](ctx); current != required {
+		return nghttp.NewErrPermissionDenied().Update(nghttp.Meta("required", string(required.(role))))
+	}
+	return nil
+}
+
+func TestRunGuard(t *testing.T) {
+	t.Run("allow without metadata", func(t *testing.T) {
+		ctx, _ := ngtest.NewContext()
+		ngtest.RunGuard(ctx, roleGuard{}).AssertAllowed(t)
+	})
+
+	t.Run("deny with metadata", func(t *testing.T) {
+		ctx, _ := ngtest.NewContext(
+			ngtest.WithMetadata(roleKey{}, role("admin")),
+			ngtest.WithValue(role("user")),
+		)
+
+		res := ngtest.RunGuard(ctx, roleGuard{})
+		res.AssertDenied(t)
+		res.AssertStatus(t, http.StatusForbidden)
+		res.AssertCode(t, nghttp.CodePermissionDenied)
+		ngtest.AssertMeta(t, res.Response, "required", "admin")
+	})
+
+	t.Run("allow matching role", func(t *testing.T) {
+		ctx, _ := ngtest.NewContext(
+			ngtest.WithMetadata(roleKey{}, role("admin")),
+			ngtest.WithValue(role("admin")),
+		)
+		ngtest.RunGuard(ctx, roleGuard{}).AssertAllowed(t)
+	})
+}
+
+func TestRunMiddleware(t *testing.T) {
+	authHeader := ng.MiddlewareFunc(func(ctx context.Context, next ng.Handler) {
+		r := ng.MustLoad[*http.Request](ctx)
+		if r.Header.Get("Authorization") == "" {
+			ng.ThrowResponse(nghttp.NewErrUnauthenticated())
+		}
+		ng.Store(ctx, role(r.Header.Get("Authorization")))
+		next(ctx)
+	})
+
+	t.Run("throw before next", func(t *testing.T) {
+		ctx, _ := ngtest.NewContext(ngtest.WithRoute(http.MethodGet, "/me"))
+
+		res := ngtest.RunMiddleware(ctx, authHeader)
+		res.AssertNextNotCalled(t)
+		res.AssertCode(t, nghttp.CodeUnauthenticated)
+	})
+
+	t.Run("next receives stored value", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "admin")
+		ctx, _ := ngtest.NewContext(ngtest.WithRequest(req))
+
+		res := ngtest.RunMiddleware(ctx, authHeader, func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(ng.MustLoad[role](ctx)))
+		})
+		res.AssertNextCalled(t)
+		res.AssertStatus(t, http.StatusOK)
+
+		if data := ngtest.MustResponse(t, res.Response).Data; data != role("admin") {
+			t.Fatalf("expected admin, got %v", data)
+		}
+	})
+}
+
+func TestRunInterceptor(t *testing.T) {
+	wrapError := ng.InterceptorFunc(func(ctx context.Context, next ng.Handler) {
+		next(ctx)
+		rc := ng.GetContext(ctx)
+		if resp, ok := rc.GetResponse().(*nghttp.Response); ok && resp.Code != nghttp.CodeOk {
+			rc.SetResponse(resp.With(nghttp.Meta("route", rc.Route().Name())))
+		}
+	})
+
+	ctx, _ := ngtest.NewContext(ngtest.WithName("UserController.Get"))
+	res := ngtest.RunInterceptor(ctx, wrapError, func(ctx context.Context) error {
+		return nghttp.NewErrNotFound()
+	})
+
+	res.AssertNextCalled(t)
+	res.AssertStatus(t, http.StatusNotFound)
+	ngtest.AssertMeta(t, res.Response, "route", "UserController.Get")
+}
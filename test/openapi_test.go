@@ -0,0 +1,305 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngopenapi "github.com/foxie-io/ng/openapi"
+)
+
+const CodeUserSuspended nghttp.Code = "USER_SUSPENDED"
+
+type DocUser struct {
+	ID        int        `json:"id" example:"42"`
+	Name      string     `json:"name" doc:"Display name"`
+	Role      string     `json:"role" enum:"admin,member"`
+	Email     string     `json:"email,omitempty" validate:"email"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Manager   *DocUser   `json:"manager,omitempty"`
+	Internal  string     `json:"-"`
+	Labels    []DocLabel `json:"labels,omitempty"`
+}
+
+type DocLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type DocPage[T any] struct {
+	Items []T `json:"items"`
+	Next  int `json:"next,omitempty"`
+}
+
+type DocListUsersRequest struct {
+	Limit  int      `query:"limit" validate:"min=1,max=100" doc:"Page size"`
+	Roles  []string `query:"role"`
+	Tenant string   `header:"X-Tenant" validate:"required"`
+}
+
+type DocCreateUserRequest struct {
+	Tenant string `header:"X-Tenant"`
+	Name   string `json:"name" validate:"required,min=2"`
+	Email  string `json:"email,omitempty"`
+}
+
+type DocController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *DocController) InitializeController() ng.Controller {
+	return ng.NewController(ng.WithPrefix("/users"), ngopenapi.WithTags("users"), ngopenapi.WithErrors(CodeUserSuspended))
+}
+
+func (c *DocController) List() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/",
+		ng.WithTypedHandler(func(ctx context.Context, req DocListUsersRequest) (DocPage[DocUser], error) {
+			return DocPage[DocUser]{}, nil
+		}),
+		ngopenapi.WithSummary("List users"),
+	)
+}
+
+func (c *DocController) Get() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/{id}",
+		ng.WithTypedHandler(func(ctx context.Context, req struct {
+			ID int `path:"id"`
+		}) (*DocUser, error) {
+			return &DocUser{}, nil
+		}),
+		ngopenapi.WithErrors(nghttp.CodeNotFound),
+		ngopenapi.WithSecurity("bearer", "users:read"),
+		ngopenapi.WithDeprecated(),
+	)
+}
+
+func (c *DocController) Create() ng.Route {
+	return ng.NewRoute(http.MethodPost, "/",
+		ng.WithTypedHandler(func(ctx context.Context, req DocCreateUserRequest) (DocUser, error) {
+			return DocUser{}, nil
+		}),
+		ng.WithEnvelope(nghttp.DataEnvelope),
+		ngopenapi.WithOperationID("createUser"),
+	)
+}
+
+func TestOpenAPIGenerate(t *testing.T) {
+	nghttp.RegisterCode(CodeUserSuspended, nghttp.CodeSpec{Status: http.StatusForbidden})
+
+	app := ng.NewApp(ng.WithPrefix("/api"), ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&DocController{})
+	app.AddRoute(
+		ng.NewRoute(http.MethodDelete, "/sessions/:token", ng.WithHandler(func(ctx context.Context) error { return nil })),
+		ng.NewRoute(http.MethodGet, "/internal", ng.WithHandler(func(ctx context.Context) error { return nil }), ngopenapi.WithHidden()),
+	)
+	app.Build()
+
+	doc := ngopenapi.Generate(app,
+		ngopenapi.WithInfo(ngopenapi.Info{Title: "Users", Version: "2.0.0"}),
+		ngopenapi.WithSecurityScheme("bearer", ngopenapi.SecurityScheme{Type: "http", Scheme: "bearer"}),
+		ngopenapi.WithDefaultErrors(nghttp.CodeInternal),
+	)
+
+	t.Run("paths", func(t *testing.T) {
+		var paths []string
+		for path, item := range doc.Paths {
+			for method := range item {
+				paths = append(paths, method+" "+path)
+			}
+		}
+		slices.Sort(paths)
+
+		expected := []string{"delete /api/sessions/{token}", "get /api/users", "get /api/users/{id}", "post /api/users"}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("expected %v, got %v", expected, paths)
+		}
+		if doc.OpenAPI != ngopenapi.Version || doc.Info.Title != "Users" || len(doc.Tags) != 1 || doc.Tags[0].Name != "users" {
+			t.Fatalf("unexpected document header %+v", doc)
+		}
+	})
+
+	t.Run("operation", func(t *testing.T) {
+		op := doc.Paths["/api/users/{id}"]["get"]
+		if op.OperationID != "test.DocController.Get" || !op.Deprecated || op.Security[0]["bearer"][0] != "users:read" {
+			t.Fatalf("unexpected operation %+v", op)
+		}
+		if len(op.Parameters) != 1 || op.Parameters[0].Schema.Type != "integer" || !op.Parameters[0].Required {
+			t.Fatalf("unexpected parameters %+v", op.Parameters)
+		}
+
+		expected := []string{"INTERNAL", "INVALID_ARGUMENT", "NOT_FOUND", "UNAUTHENTICATED", "USER_SUSPENDED"}
+		if !reflect.DeepEqual(op.ErrorCodes, expected) {
+			t.Fatalf("expected codes %v, got %v", expected, op.ErrorCodes)
+		}
+		for _, status := range []string{"200", "400", "401", "403", "404", "500"} {
+			if op.Responses[status] == nil {
+				t.Fatalf("expected %s response, got %v", status, slices.Collect(func(yield func(string) bool) {
+					for k := range op.Responses {
+						yield(k)
+					}
+				}))
+			}
+		}
+
+		if ref := op.Responses["404"].Content[ngopenapi.ContentType].Schema.Ref; ref != "#/components/schemas/StandardBody" {
+			t.Fatalf("unexpected error schema %q", ref)
+		}
+		data := op.Responses["200"].Content[ngopenapi.ContentType].Schema.Properties["data"]
+		if data == nil || data.Ref != "#/components/schemas/DocUser" {
+			t.Fatalf("expected enveloped user data, got %+v", op.Responses["200"].Content[ngopenapi.ContentType].Schema)
+		}
+
+		// untyped routes only document path parameters
+		del := doc.Paths["/api/sessions/{token}"]["delete"]
+		if del.OperationID != "deleteApiSessionsToken" || del.Parameters[0].Name != "token" || del.Parameters[0].Schema.Type != "string" {
+			t.Fatalf("unexpected untyped operation %+v", del)
+		}
+	})
+
+	t.Run("parameters and body", func(t *testing.T) {
+		list := doc.Paths["/api/users"]["get"]
+		if list.Summary != "List users" || list.RequestBody != nil || len(list.Parameters) != 3 {
+			t.Fatalf("unexpected list operation %+v", list)
+		}
+		limit, roles, tenant := list.Parameters[0], list.Parameters[1], list.Parameters[2]
+		if limit.In != "query" || limit.Required || limit.Description != "Page size" || *limit.Schema.Minimum != 1 || *limit.Schema.Maximum != 100 {
+			t.Fatalf("unexpected limit parameter %+v", limit)
+		}
+		if roles.Schema.Type != "array" || roles.Schema.Items.Type != "string" {
+			t.Fatalf("unexpected roles parameter %+v", roles)
+		}
+		if tenant.In != "header" || tenant.Name != "X-Tenant" || !tenant.Required {
+			t.Fatalf("unexpected tenant parameter %+v", tenant)
+		}
+
+		page := list.Responses["200"].Content[ngopenapi.ContentType].Schema.Properties["data"]
+		if page.Ref != "#/components/schemas/DocPageDocUser" {
+			t.Fatalf("unexpected page schema %+v", page)
+		}
+
+		create := doc.Paths["/api/users"]["post"]
+		if create.OperationID != "createUser" {
+			t.Fatalf("unexpected operation id %q", create.OperationID)
+		}
+		body := doc.Components.Schemas["DocCreateUserRequest"]
+		if body == nil || create.RequestBody.Content[ngopenapi.ContentType].Schema.Ref != "#/components/schemas/DocCreateUserRequest" {
+			t.Fatalf("expected request body schema, got %+v", create.RequestBody)
+		}
+		if _, ok := body.Properties["Tenant"]; ok || !reflect.DeepEqual(body.Required, []string{"name"}) || *body.Properties["name"].MinLength != 2 {
+			t.Fatalf("unexpected request body schema %+v", body)
+		}
+
+		// data envelope documents bare data
+		if ref := create.Responses["200"].Content[ngopenapi.ContentType].Schema.Ref; ref != "#/components/schemas/DocUser" {
+			t.Fatalf("expected bare user, got %q", ref)
+		}
+	})
+
+	t.Run("schemas", func(t *testing.T) {
+		user := doc.Components.Schemas["DocUser"]
+		expected := []string{"id", "name", "role", "createdAt"}
+		if !reflect.DeepEqual(user.Required, expected) {
+			t.Fatalf("expected required %v, got %v", expected, user.Required)
+		}
+		if _, ok := user.Properties["Internal"]; ok || len(user.Properties) != 7 {
+			t.Fatalf("unexpected properties %v", user.Properties)
+		}
+
+		checks := map[string]func(s *ngopenapi.Schema) bool{
+			"id":        func(s *ngopenapi.Schema) bool { return s.Type == "integer" && s.Example == int64(42) },
+			"name":      func(s *ngopenapi.Schema) bool { return s.Description == "Display name" },
+			"role":      func(s *ngopenapi.Schema) bool { return reflect.DeepEqual(s.Enum, []any{"admin", "member"}) },
+			"email":     func(s *ngopenapi.Schema) bool { return s.Format == "email" },
+			"createdAt": func(s *ngopenapi.Schema) bool { return s.Type == "string" && s.Format == "date-time" },
+			"manager":   func(s *ngopenapi.Schema) bool { return s.Ref == "#/components/schemas/DocUser" },
+			"labels":    func(s *ngopenapi.Schema) bool { return s.Items.Ref == "#/components/schemas/DocLabel" },
+		}
+		for name, check := range checks {
+			if !check(user.Properties[name]) {
+				t.Fatalf("unexpected %s schema %+v", name, user.Properties[name])
+			}
+		}
+
+		codes := map[string]int{}
+		for _, c := range doc.ErrorCodes {
+			codes[c.Code] = c.Status
+		}
+		if codes["USER_SUSPENDED"] != http.StatusForbidden || codes["NOT_FOUND"] != http.StatusNotFound {
+			t.Fatalf("unexpected error codes %+v", doc.ErrorCodes)
+		}
+	})
+
+	t.Run("separate apps", func(t *testing.T) {
+		other := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+		other.AddRoute(ng.NewRoute(http.MethodGet, "/ping", ng.WithHandler(func(ctx context.Context) error { return nil }),
+			ngopenapi.WithErrors(nghttp.CodeUnavailable)))
+		other.Build()
+
+		op := ngopenapi.Generate(other).Paths["/ping"]["get"]
+		if !reflect.DeepEqual(op.ErrorCodes, []string{"UNAVAILABLE"}) {
+			t.Fatalf("unexpected codes %v", op.ErrorCodes)
+		}
+		if codes := ngopenapi.Generate(app).Paths["/api/sessions/{token}"]["delete"].ErrorCodes; slices.Contains(codes, "UNAVAILABLE") {
+			t.Fatalf("codes of other app leaked: %v", codes)
+		}
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		data, err := doc.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded ngopenapi.Document
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded.Paths["/api/users"]["post"].RequestBody, doc.Paths["/api/users"]["post"].RequestBody) {
+			t.Fatalf("expected document to round trip")
+		}
+
+		yaml, err := doc.YAML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range []string{
+			"openapi: 3.1.0\n",
+			"  /api/users/{id}:\n",
+			"          required: true\n",
+			"        - bearer:\n",
+			"            - users:read\n",
+			"        \"404\":\n",
+			"            $ref: \"#/components/schemas/DocUser\"\n",
+		} {
+			if !strings.Contains(string(yaml), line) {
+				t.Fatalf("expected yaml to contain %q, got\n%s", line, yaml)
+			}
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		expected, _ := doc.JSON()
+		yaml, _ := doc.YAML()
+		for _, data := range [][]byte{expected, yaml} {
+			parsed, err := ngopenapi.Parse(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual, _ := parsed.JSON(); string(actual) != string(expected) {
+				t.Fatalf("expected parsed document to match, got\n%s", actual)
+			}
+		}
+
+		user := doc.Resolve(doc.Paths["/api/users/{id}"]["get"].Responses["200"].Content[ngopenapi.ContentType].Schema.Properties["data"])
+		if user == nil || user.Properties["name"] == nil {
+			t.Fatal("expected reference to resolve")
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngproblem "github.com/foxie-io/ng/problem"
+)
+
+type ProblemController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *ProblemController) Get() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/orders/{id}",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewResponse(map[string]string{"id": "1"}))
+		}),
+	)
+}
+
+func (c *ProblemController) Missing() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/missing/{id}",
+		ng.WithHandler(func(ctx context.Context) error {
+			return nghttp.NewErrNotFound().Update(
+				nghttp.WithMessage("order 7 does not exist"),
+				nghttp.Meta("id", 7, "status", "ignored", "code", "ignored"),
+				nghttp.WithHeader("Cache-Control", "no-store"),
+			)
+		}),
+	)
+}
+
+func (c *ProblemController) Limited() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/limited",
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			return nghttp.NewErrTooManyRequests().Update(nghttp.WithHeader("Retry-After", "30"))
+		})),
+		ng.WithHandler(func(ctx context.Context) error { return nil }),
+	)
+}
+
+func (c *ProblemController) Panic() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/panic",
+		ng.WithHandler(func(ctx context.Context) error {
+			panic("boom")
+		}),
+	)
+}
+
+func TestProblemDetails(t *testing.T) {
+	ngproblem.RegisterType(nghttp.CodeNotFound, "https://example.com/problems/not-found")
+
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithErrorFormat(ngproblem.Format),
+	)
+	app.AddController(&ProblemController{})
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("success unchanged", func(t *testing.T) {
+		resp, body := get(t, "/orders/1")
+		if resp.Header.Get("Content-Type") != ng.MIMEApplicationJSON || body != `{"code":"OK","data":{"id":"1"}}` {
+			t.Fatalf("unexpected success response %s %s", resp.Header.Get("Content-Type"), body)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		header  [2]string
+		problem ngproblem.Problem
+	}{
+		{
+			name: "registered type with extensions", path: "/missing/7", status: 404, header: [2]string{"Cache-Control", "no-store"},
+			problem: ngproblem.Problem{
+				Type: "https://example.com/problems/not-found", Title: "Not Found", Status: 404,
+				Detail: "order 7 does not exist", Instance: "/missing/7",
+				Extensions: map[string]any{"code": "NOT_FOUND", "id": float64(7)},
+			},
+		},
+		{
+			name: "guard error", path: "/limited", status: 429, header: [2]string{"Retry-After", "30"},
+			problem: ngproblem.Problem{
+				Type: ngproblem.DefaultType, Title: "Too Many Requests", Status: 429,
+				Detail: "too many requests", Instance: "/limited",
+				Extensions: map[string]any{"code": "TOO_MANY_REQUESTS"},
+			},
+		},
+		{
+			name: "panic", path: "/panic", status: 500, header: [2]string{"Content-Type", ngproblem.ContentType},
+			problem: ngproblem.Problem{
+				Type: ngproblem.DefaultType, Title: "Internal Server Error", Status: 500,
+				Detail: "unknown", Instance: "/panic",
+				Extensions: map[string]any{"code": "UNKNOWN"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(t, tt.path)
+			if resp.StatusCode != tt.status || resp.Header.Get("Content-Type") != ngproblem.ContentType {
+				t.Fatalf("expected %d problem+json, got %d %s", tt.status, resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+			if resp.Header.Get(tt.header[0]) != tt.header[1] {
+				t.Fatalf("expected header %s=%s, got %q", tt.header[0], tt.header[1], resp.Header.Get(tt.header[0]))
+			}
+
+			var got ngproblem.Problem
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			want, _ := json.Marshal(tt.problem)
+			if have, _ := json.Marshal(got); string(have) != string(want) {
+				t.Fatalf("expected %s, got %s", want, body)
+			}
+		})
+	}
+}
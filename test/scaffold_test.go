@@ -0,0 +1,168 @@
+package test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ngopenapi "github.com/foxie-io/ng/openapi"
+	ngscaffold "github.com/foxie-io/ng/openapi/scaffold"
+)
+
+const scaffoldSpec = `# users service
+openapi: 3.1.0
+info:
+  title: Users
+  version: 1.0
+  description: |
+    Manages user accounts.
+tags:
+  - name: users
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      summary: Get a user
+      tags: [users]
+      security:
+        - bearer: [users:read]
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string, format: uuid}}
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items: {type: string}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  code: {type: string}
+                  data: {$ref: "#/components/schemas/User"}
+      x-error-codes: [NOT_FOUND]
+    put:
+      operationId: updateUser
+      tags: [users]
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/UserInput'
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, name, createdAt]
+      properties:
+        id: {type: string}
+        name:
+          type: string
+          description: Display name
+          maxLength: 64
+        role:
+          type: string
+          enum: [admin, member]
+        createdAt: {type: string, format: date-time}
+    UserInput:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1}
+`
+
+func TestScaffold(t *testing.T) {
+	doc, err := ngopenapi.Parse([]byte(scaffoldSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Version != "1.0" || doc.Info.Description != "Manages user accounts.\n" {
+		t.Fatalf("unexpected info %+v", doc.Info)
+	}
+
+	files, err := ngscaffold.Generate(doc, ngscaffold.Config{Package: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		ngscaffold.DTOsFile: {
+			"// Code generated by ngscaffold. DO NOT EDIT.",
+			"type User struct {",
+			"\tCreatedAt time.Time `json:\"createdAt\" validate:\"required\"`",
+			"\tName      string    `json:\"name\" validate:\"required,max=64\" doc:\"Display name\"`",
+			"\tRole      string    `json:\"role,omitempty\" validate:\"omitempty,oneof=admin member\"`",
+			"type GetUserRequest struct {\n\tID     string   `path:\"id\" validate:\"required,uuid\"`\n\tFields []string `query:\"fields\"`\n}",
+			"type UpdateUserRequest struct {\n\tUserInput\n\tID string `path:\"id\" validate:\"required\"`\n}",
+		},
+		ngscaffold.ControllersFile: {
+			"type UsersController struct {\n\tng.DefaultControllerInitializer\n\tService UsersService\n}",
+			"ng.WithPrefix(\"/users\"),\n\t\tngopenapi.WithTags(\"users\"),",
+			"// GetUser get a user\nfunc (c *UsersController) GetUser() ng.Route {\n\treturn ng.NewRoute(http.MethodGet, \"/{id}\",\n\t\tng.WithTypedHandler(c.Service.GetUser),",
+			"ngopenapi.WithSecurity(\"bearer\", \"users:read\"),\n\t\tngopenapi.WithErrors(\"NOT_FOUND\"),",
+		},
+		ngscaffold.ServicesFile: {
+			"type UsersService interface {",
+			"GetUser(ctx context.Context, req GetUserRequest) (*User, error)",
+			"UpdateUser(ctx context.Context, req UpdateUserRequest) (*User, error)",
+		},
+		"users_service.go": {
+			"var _ UsersService = (*usersService)(nil)",
+			"func (s *usersService) GetUser(ctx context.Context, req GetUserRequest) (resp *User, err error) {\n\treturn resp, nghttp.NewErrUnimplemented()\n}",
+		},
+	}
+	for name, snippets := range expected {
+		src := string(files[name])
+		if _, err := parser.ParseFile(token.NewFileSet(), name, src, parser.AllErrors); err != nil {
+			t.Fatalf("%s does not parse: %v\n%s", name, err, src)
+		}
+		for _, s := range snippets {
+			if !strings.Contains(src, s) {
+				t.Fatalf("expected %s to contain %q, got\n%s", name, s, src)
+			}
+		}
+	}
+	if strings.Contains(string(files["users_service.go"]), "DO NOT EDIT") {
+		t.Fatal("expected service implementation to be editable")
+	}
+
+	t.Run("regenerate", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := ngscaffold.WriteFiles(dir, doc, ngscaffold.Config{Package: "users"}); err != nil {
+			t.Fatal(err)
+		}
+
+		service := filepath.Join(dir, "users_service.go")
+		custom := []byte("package users\n\n// hand-written\n")
+		if err := os.WriteFile(service, custom, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		doc.Paths["/users/{id}"]["delete"] = &ngopenapi.Operation{OperationID: "deleteUser", Tags: []string{"users"}}
+		if err := ngscaffold.WriteFiles(dir, doc, ngscaffold.Config{Package: "users"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if data, _ := os.ReadFile(service); string(data) != string(custom) {
+			t.Fatalf("expected service implementation to survive, got\n%s", data)
+		}
+		services, _ := os.ReadFile(filepath.Join(dir, ngscaffold.ServicesFile))
+		if !strings.Contains(string(services), "DeleteUser(ctx context.Context, req struct{}) (*nghttp.Response, error)") {
+			t.Fatalf("expected generated files to be replaced, got\n%s", services)
+		}
+	})
+}
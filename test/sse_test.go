@@ -0,0 +1,149 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type SSEController struct {
+	ng.DefaultControllerInitializer
+	stopped chan struct{}
+}
+
+func (c *SSEController) Progress() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/progress",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewSSE(func(ctx context.Context, e *nghttp.SSEEmitter) error {
+				if err := e.Send(nghttp.SSEEvent{Event: "resume", Data: e.LastEventID(), Retry: time.Second}); err != nil {
+					return err
+				}
+
+				for i := 1; i <= 2; i++ {
+					if err := e.Send(nghttp.SSEEvent{ID: string(rune('0' + i)), Event: "progress", Data: map[string]int{"done": i}}); err != nil {
+						return err
+					}
+				}
+				return e.Data("line1\nline2")
+			}, nghttp.WithHeartbeat(0)))
+		}),
+	)
+}
+
+func (c *SSEController) Forever() ng.Route {
+	return ng.NewRoute(http.MethodGet, "/forever",
+		ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.NewSSE(func(ctx context.Context, e *nghttp.SSEEmitter) error {
+				defer close(c.stopped)
+				<-ctx.Done()
+				return ctx.Err()
+			}, nghttp.WithHeartbeat(10*time.Millisecond)))
+		}),
+	)
+}
+
+func TestSSE(t *testing.T) {
+	controller := &SSEController{stopped: make(chan struct{})}
+	guarded := 0
+
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			guarded++
+			return nil
+		})),
+	)
+	app.AddController(controller)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("events", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/progress", nil)
+		req.Header.Set("Last-Event-ID", "7")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("unexpected content type %s", ct)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		expected := "event: resume\nretry: 1000\ndata: 7\n\n" +
+			"id: 1\nevent: progress\ndata: {\"done\":1}\n\n" +
+			"id: 2\nevent: progress\ndata: {\"done\":2}\n\n" +
+			"data: line1\ndata: line2\n\n"
+		if string(body) != expected {
+			t.Fatalf("expected %q, got %q", expected, body)
+		}
+
+		if guarded != 1 {
+			t.Fatalf("expected guard to run once, got %d", guarded)
+		}
+	})
+
+	t.Run("heartbeat and disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/forever", nil)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, ": heartbeat") {
+			t.Fatalf("expected heartbeat, got %q %v", line, err)
+		}
+
+		cancel()
+		resp.Body.Close()
+
+		select {
+		case <-controller.stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected handler to stop after client disconnect")
+		}
+	})
+}
+
+func TestSSEPrepare(t *testing.T) {
+	sse := nghttp.NewSSE(func(ctx context.Context, e *nghttp.SSEEmitter) error {
+		return e.Data(e.LastEventID())
+	}, nghttp.WithHeartbeat(0))
+
+	// prepared per request, the shared value keeps no request state
+	resumed := http.Header{}
+	resumed.Set("Last-Event-ID", "7")
+	first, second := sse.Prepare(resumed), sse.Prepare(http.Header{})
+
+	for _, tt := range []struct {
+		stream   nghttp.Streamer
+		expected string
+	}{{first, "data: 7\n\n"}, {second, "data: \n\n"}} {
+		var buf strings.Builder
+		if err := tt.stream.Stream(context.Background(), &buf, func() error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.expected {
+			t.Fatalf("expected %q, got %q", tt.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+func items(n int, fail error) iter.Seq2[streamItem, error] {
+	return func(yield func(streamItem, error) bool) {
+		for i := 1; i <= n; i++ {
+			if !yield(streamItem{ID: i}, nil) {
+				return
+			}
+		}
+		if fail != nil {
+			yield(streamItem{}, fail)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	stopped := make(chan struct{})
+	blockedStopped, unblock, handled := make(chan struct{}), make(chan struct{}), make(chan struct{}, 1)
+	waitingStopped := make(chan struct{})
+
+	// one response value reused by every request
+	shared := nghttp.Stream(items(2, nil))
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddRoute(
+		ng.NewRoute(http.MethodGet, "/items", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(3, nil)))
+		})),
+		ng.NewRoute(http.MethodGet, "/failing", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(2, nghttp.NewErrUnavailable())))
+		})),
+		ng.NewRoute(http.MethodGet, "/hidden", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(0, errors.New("db password leaked"))))
+		})),
+		ng.NewRoute(http.MethodGet, "/bare", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(2, nil)))
+		}), ng.WithEnvelope(nghttp.DataEnvelope)),
+		ng.NewRoute(http.MethodGet, "/bare-failing", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(1, nghttp.NewErrUnavailable())))
+		}), ng.WithEnvelope(nghttp.DataEnvelope)),
+		ng.NewRoute(http.MethodGet, "/jsend-failing", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(items(1, nghttp.NewErrUnavailable())))
+		}), ng.WithEnvelope(nghttp.JSendEnvelope)),
+		ng.NewRoute(http.MethodGet, "/blocked", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(func(yield func(int, error) bool) {
+				defer close(blockedStopped)
+				if !yield(0, nil) {
+					return
+				}
+				<-unblock // producer ignores ctx
+				yield(1, nil)
+			}, nghttp.WithStreamFormat(nghttp.StreamNDJSON)))
+		})),
+		ng.NewRoute(http.MethodGet, "/waiting", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.StreamContext(func(ctx context.Context) iter.Seq2[int, error] {
+				return func(yield func(int, error) bool) {
+					defer close(waitingStopped)
+					if !yield(0, nil) {
+						return
+					}
+					<-ctx.Done() // released once the client is gone
+				}
+			}, nghttp.WithStreamFormat(nghttp.StreamNDJSON)))
+		})),
+		ng.NewRoute(http.MethodGet, "/shared", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, shared)
+		})),
+		ng.NewRoute(http.MethodGet, "/endless", ng.WithHandler(func(ctx context.Context) error {
+			return ng.Respond(ctx, nghttp.Stream(func(yield func(int, error) bool) {
+				defer close(stopped)
+				for i := 0; ; i++ {
+					if !yield(i, nil) {
+						return
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}, nghttp.WithStreamFormat(nghttp.StreamNDJSON)))
+		})),
+	)
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if r.URL.Path == "/blocked" {
+			handled <- struct{}{}
+		}
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, path, accept string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		body        string
+		errorCode   string
+	}{
+		{"json array", "/items", "", "application/json", `{"data":[{"id":1},{"id":2},{"id":3}],"code":"OK"}`, ""},
+		{"ndjson", "/items", "application/x-ndjson", "application/x-ndjson", "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n", ""},
+		{"json array error", "/failing", "", "application/json",
+			`{"data":[{"id":1},{"id":2}],"code":"UNAVAILABLE","message":"unavailable"}`, "UNAVAILABLE"},
+		{"ndjson error", "/failing", "application/x-ndjson", "application/x-ndjson",
+			"{\"id\":1}\n{\"id\":2}\n{\"error\":{\"code\":\"UNAVAILABLE\",\"message\":\"unavailable\"}}\n", "UNAVAILABLE"},
+		{"data envelope", "/bare", "", "application/json", `[{"id":1},{"id":2}]`, ""},
+		{"data envelope error", "/bare-failing", "", "application/json",
+			`[{"id":1},{"error":{"code":"UNAVAILABLE","message":"unavailable"}}]`, "UNAVAILABLE"},
+		{"jsend error", "/jsend-failing", "", "application/json",
+			`{"data":[{"id":1}],"status":"error","message":"unavailable","code":"UNAVAILABLE"}`, "UNAVAILABLE"},
+		{"unknown error", "/hidden", "", "application/json",
+			`{"data":[],"code":"INTERNAL","message":"internal error"}`, "INTERNAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(t, tt.path, tt.accept)
+			if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected content type %s, got %s", tt.contentType, ct)
+			}
+			if body != tt.body {
+				t.Fatalf("expected body %s, got %s", tt.body, body)
+			}
+			if code := resp.Trailer.Get(nghttp.TrailerErrorCode); code != tt.errorCode {
+				t.Fatalf("expected error trailer %q, got %q", tt.errorCode, code)
+			}
+		})
+	}
+
+	t.Run("client disconnect stops iteration", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/endless", nil)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil || line != "0\n" {
+			t.Fatalf("expected first item, got %q %v", line, err)
+		}
+
+		cancel()
+		resp.Body.Close()
+
+		select {
+		case <-stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected iteration to stop after client disconnect")
+		}
+	})
+
+	t.Run("shared response", func(t *testing.T) {
+		for _, accept := range []string{"application/x-ndjson", "", "application/x-ndjson"} {
+			resp, body := get(t, "/shared", accept)
+			expected := `{"data":[{"id":1},{"id":2}],"code":"OK"}`
+			if accept != "" {
+				expected = "{\"id\":1}\n{\"id\":2}\n"
+			}
+			if body != expected {
+				t.Fatalf("accept %q: expected %q, got %q", accept, expected, body)
+			}
+			if resp.Trailer.Get(nghttp.TrailerErrorCode) != "" {
+				t.Fatalf("unexpected error trailer %v", resp.Trailer)
+			}
+		}
+		if shared.Format() != nghttp.StreamAuto || shared.Headers().Get("Content-Type") != "" {
+			t.Fatalf("expected shared response untouched, got format %d %v", shared.Format(), shared.Headers())
+		}
+	})
+
+	t.Run("client disconnect releases context producer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/waiting", nil)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil || line != "0\n" {
+			t.Fatalf("expected first item, got %q %v", line, err)
+		}
+
+		cancel()
+		resp.Body.Close()
+
+		select {
+		case <-waitingStopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected producer to be released after client disconnect")
+		}
+	})
+
+	t.Run("client disconnect while producer blocks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/blocked", nil)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil || line != "0\n" {
+			t.Fatalf("expected first item, got %q %v", line, err)
+		}
+
+		cancel()
+		resp.Body.Close()
+
+		select {
+		case <-handled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected response to end while producer is blocked")
+		}
+
+		close(unblock)
+		select {
+		case <-blockedStopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected producer to stop once unblocked")
+		}
+	})
+}
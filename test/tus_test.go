@@ -0,0 +1,283 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	ngtus "github.com/foxie-io/ng/tus"
+)
+
+func TestTus(t *testing.T) {
+	store, err := ngtus.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	completed := make(chan string, 1)
+
+	app := ng.NewApp(
+		ng.WithPrefix("/api"),
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithGuards(ng.GuardFunc(func(ctx context.Context) error {
+			if ng.MustLoad[*http.Request](ctx).Header.Get("Authorization") != "token" {
+				return nghttp.NewErrUnauthenticated()
+			}
+			return nil
+		})),
+	)
+	app.AddController(ngtus.New(ngtus.Options{
+		Path:       "/videos",
+		Store:      store,
+		MaxSize:    1024,
+		Expiration: time.Hour,
+		OnComplete: func(ctx context.Context, file *ngtus.File) error {
+			r, err := file.Open(ctx)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			content, _ := io.ReadAll(r)
+			completed <- file.Filename() + ":" + string(content)
+			return nil
+		},
+	}))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, header map[string]string, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "token")
+		req.Header.Set("Tus-Resumable", ngtus.Version)
+		for k, v := range header {
+			if v == "" {
+				req.Header.Del(k)
+				continue
+			}
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	expect := func(t *testing.T, resp *http.Response, status int, headers map[string]string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("expected %d, got %d", status, resp.StatusCode)
+		}
+		if resp.Header.Get("Tus-Resumable") != ngtus.Version {
+			t.Fatalf("expected Tus-Resumable header on every response")
+		}
+		for k, v := range headers {
+			if resp.Header.Get(k) != v {
+				t.Fatalf("expected header %s=%q, got %q", k, v, resp.Header.Get(k))
+			}
+		}
+	}
+
+	patch := func(offset string) map[string]string {
+		return map[string]string{"Content-Type": ngtus.ContentTypeOffset, "Upload-Offset": offset}
+	}
+
+	t.Run("options", func(t *testing.T) {
+		resp := do(t, http.MethodOptions, "/api/videos", map[string]string{"Tus-Resumable": ""}, "")
+		expect(t, resp, http.StatusNoContent, map[string]string{
+			"Tus-Version":   ngtus.Version,
+			"Tus-Extension": ngtus.Extensions,
+			"Tus-Max-Size":  "1024",
+		})
+	})
+
+	t.Run("resumable upload", func(t *testing.T) {
+		metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("clip.mp4"))
+		resp := do(t, http.MethodPost, "/api/videos", map[string]string{"Upload-Length": "11", "Upload-Metadata": metadata}, "")
+		expect(t, resp, http.StatusCreated, nil)
+
+		location := resp.Header.Get("Location")
+		if !strings.HasPrefix(location, "/api/videos/") || resp.Header.Get("Upload-Expires") == "" {
+			t.Fatalf("unexpected location %q or missing expiration", location)
+		}
+
+		expect(t, do(t, http.MethodPatch, location, patch("0"), "hello"), http.StatusNoContent, map[string]string{"Upload-Offset": "5"})
+
+		// stale offset is rejected
+		expect(t, do(t, http.MethodPatch, location, patch("0"), "hello"), http.StatusConflict, nil)
+
+		expect(t, do(t, http.MethodHead, location, nil, ""), http.StatusOK, map[string]string{
+			"Upload-Offset":   "5",
+			"Upload-Length":   "11",
+			"Upload-Metadata": metadata,
+			"Cache-Control":   "no-store",
+		})
+
+		expect(t, do(t, http.MethodPatch, location, patch("5"), " world"), http.StatusNoContent, map[string]string{"Upload-Offset": "11"})
+
+		select {
+		case got := <-completed:
+			if got != "clip.mp4:hello world" {
+				t.Fatalf("unexpected completed file %q", got)
+			}
+		default:
+			t.Fatal("expected completion hook")
+		}
+
+		expect(t, do(t, http.MethodDelete, location, nil, ""), http.StatusNoContent, nil)
+		expect(t, do(t, http.MethodHead, location, nil, ""), http.StatusNotFound, nil)
+	})
+
+	t.Run("rejected requests", func(t *testing.T) {
+		expect(t, do(t, http.MethodPost, "/api/videos", map[string]string{"Upload-Length": "2048"}, ""), http.StatusRequestEntityTooLarge, nil)
+		expect(t, do(t, http.MethodPost, "/api/videos", map[string]string{"Upload-Length": "1", "Tus-Resumable": "0.2.2"}, ""), http.StatusPreconditionFailed, map[string]string{"Tus-Version": ngtus.Version})
+		expect(t, do(t, http.MethodPost, "/api/videos", map[string]string{"Upload-Length": "1", "Authorization": ""}, ""), http.StatusUnauthorized, nil)
+		expect(t, do(t, http.MethodPatch, "/api/videos/unknown", patch("0"), "x"), http.StatusNotFound, nil)
+		expect(t, do(t, http.MethodPatch, "/api/videos/unknown", map[string]string{"Upload-Offset": "0"}, "x"), http.StatusUnsupportedMediaType, nil)
+	})
+
+	t.Run("expired upload", func(t *testing.T) {
+		err := store.Create(context.Background(), ngtus.Upload{ID: "expired", Size: 1, ExpiresAt: time.Now().Add(-time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect(t, do(t, http.MethodHead, "/api/videos/expired", nil, ""), http.StatusGone, nil)
+		if _, err := store.Get(context.Background(), "expired"); err != ngtus.ErrNotFound {
+			t.Fatalf("expected expired upload to be deleted, got %v", err)
+		}
+	})
+}
+
+// blockingStore holds Write until released
+type blockingStore struct {
+	ngtus.Store
+	entered, release chan struct{}
+}
+
+func (s *blockingStore) Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	s.entered <- struct{}{}
+	<-s.release
+	return s.Store.Write(ctx, id, offset, r)
+}
+
+func TestTusLock(t *testing.T) {
+	files, err := ngtus.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &blockingStore{Store: files, entered: make(chan struct{}), release: make(chan struct{})}
+	if err := store.Create(context.Background(), ngtus.Upload{ID: "clip", Size: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(ngtus.New(ngtus.Options{Store: store}))
+	app.Build()
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	patch := func(offset, body string) int {
+		req, _ := http.NewRequest(http.MethodPatch, server.URL+"/files/clip", strings.NewReader(body))
+		req.Header.Set("Tus-Resumable", ngtus.Version)
+		req.Header.Set("Content-Type", ngtus.ContentTypeOffset)
+		req.Header.Set("Upload-Offset", offset)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	first := make(chan int)
+	go func() { first <- patch("0", "ab") }()
+	<-store.entered
+
+	if status := patch("0", "ab"); status != http.StatusLocked {
+		t.Fatalf("expected 423 while another PATCH runs, got %d", status)
+	}
+
+	close(store.release)
+	if status := <-first; status != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", status)
+	}
+
+	// lock is released with the request
+	go func() { <-store.entered }()
+	if status := patch("2", "cd"); status != http.StatusNoContent {
+		t.Fatalf("expected 204 after lock release, got %d", status)
+	}
+}
+
+func TestTusIDSegment(t *testing.T) {
+	store, err := ngtus.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(context.Background(), ngtus.Upload{ID: "clip", Size: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(ngtus.New(ngtus.Options{Store: store, IDSegment: ":id"}))
+	app.Build()
+
+	var head ng.Route
+	for _, r := range app.Routes() {
+		if r.Method() == http.MethodHead {
+			head = r
+		}
+	}
+	if head.Path() != "/files/:id" {
+		t.Fatalf("unexpected path %s", head.Path())
+	}
+
+	// head is dispatched like a router with ":id" params, e.g. gin
+	do := func(params ng.PathParams) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodHead, "/files/clip", nil)
+		r.Header.Set("Tus-Resumable", ngtus.Version)
+
+		ctx, rc := ng.NewContext(r.Context())
+		defer rc.Clear()
+		ng.Store(ctx, http.ResponseWriter(w))
+		ng.Store(ctx, r)
+		if params != nil {
+			ng.Store(ctx, params)
+		}
+		_ = head.Handler()(ctx)
+		return w
+	}
+
+	w := do(func(name string) string { return map[string]string{"id": "clip"}[name] })
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "0" {
+		t.Fatalf("expected offset 0, got %d %v", w.Code, w.Header())
+	}
+
+	if w := do(nil); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected unresolved id to fail, got %d", w.Code)
+	}
+}
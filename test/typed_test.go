@@ -0,0 +1,230 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foxie-io/ng"
+	ngadapter "github.com/foxie-io/ng/adapter"
+	nghttp "github.com/foxie-io/ng/http"
+	"github.com/foxie-io/ng/ngtest"
+)
+
+type UpdateItemRequest struct {
+	ID      int      `path:"id"`
+	Notify  bool     `query:"notify"`
+	Limit   *int     `query:"limit"`
+	Tags    []string `query:"tag"`
+	TraceID string   `header:"X-Trace-ID"`
+	Name    string   `json:"name"`
+}
+
+func (r UpdateItemRequest) Validate() error {
+	if r.Name == "" {
+		return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("field", "name"))
+	}
+	return nil
+}
+
+type ItemResponse struct {
+	ID      int      `json:"id"`
+	Limit   *int     `json:"limit,omitempty"`
+	Name    string   `json:"name"`
+	Notify  bool     `json:"notify"`
+	Tags    []string `json:"tags"`
+	TraceID string   `json:"traceId"`
+}
+
+type TypedController struct {
+	ng.DefaultControllerInitializer
+}
+
+func (c *TypedController) Update() ng.Route {
+	return ng.NewRoute(http.MethodPut, "/items/{id}",
+		ng.WithTypedHandler(func(ctx context.Context, req UpdateItemRequest) (ItemResponse, error) {
+			return ItemResponse{ID: req.ID, Limit: req.Limit, Name: req.Name, Notify: req.Notify, Tags: req.Tags, TraceID: req.TraceID}, nil
+		}),
+		ng.WithValidator(func(ctx context.Context, v any) error {
+			if req, ok := v.(*UpdateItemRequest); ok && req.ID > 100 {
+				return errors.New("id out of range")
+			}
+			return nil
+		}),
+	)
+}
+
+func (c *TypedController) Delete() ng.Route {
+	return ng.NewRoute(http.MethodDelete, "/items/{id}",
+		ng.WithTypedHandler(func(ctx context.Context, req *struct {
+			ID int `param:"id"`
+		}) (*nghttp.BodyResponse, error) {
+			if req.ID == 0 {
+				return nil, nghttp.NewErrNotFound()
+			}
+			return nghttp.NoContent(), nil
+		}),
+	)
+}
+
+func TestTypedHandler(t *testing.T) {
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddController(&TypedController{})
+	app.Build()
+
+	for _, r := range app.Routes() {
+		types, ok := ng.RouteHandlerTypes(r)
+		if !ok {
+			t.Fatalf("expected handler types on %s", r.Name())
+		}
+		if r.Method() == http.MethodPut && (types.Request != reflect.TypeFor[UpdateItemRequest]() || types.Response != reflect.TypeFor[ItemResponse]()) {
+			t.Fatalf("unexpected handler types %v", types)
+		}
+	}
+
+	mux := http.NewServeMux()
+	ngadapter.ServeMuxRegisterRoutes(app, mux)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		{"bind and wrap", http.MethodPut, "/items/7?notify=true&tag=a&tag=b", `{"name":"pen"}`, 200,
+			`{"code":"OK","data":{"id":7,"name":"pen","notify":true,"tags":["a","b"],"traceId":"t-1"}}`},
+		{"optional query", http.MethodPut, "/items/7?limit=5", `{"name":"pen"}`, 200,
+			`{"code":"OK","data":{"id":7,"limit":5,"name":"pen"`},
+		{"self validation", http.MethodPut, "/items/7", `{}`, 400,
+			`{"code":"INVALID_ARGUMENT","message":"invalid argument","meta":{"field":"name"}}`},
+		{"route validator", http.MethodPut, "/items/101", `{"name":"pen"}`, 400,
+			`{"code":"INVALID_ARGUMENT","message":"invalid argument","meta":{"error":"id out of range"}}`},
+		{"invalid path value", http.MethodPut, "/items/seven", `{"name":"pen"}`, 400,
+			`"field":"id"`},
+		{"invalid body", http.MethodPut, "/items/7", `{"name":`, 400,
+			`{"code":"INVALID_ARGUMENT"`},
+		{"http response result", http.MethodDelete, "/items/7", "", 204, ``},
+		{"error result", http.MethodDelete, "/items/0", "", 404, `{"code":"NOT_FOUND","message":"not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-Trace-ID", "t-1")
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.status || !strings.Contains(string(body), tt.expected) {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, tt.expected, res.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestTypedPathParams(t *testing.T) {
+	app := ng.NewApp(ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler))
+	app.AddRoute(ng.NewRoute(http.MethodGet, "/items/:id",
+		ng.WithTypedHandler(func(ctx context.Context, req struct {
+			ID int `path:"id"`
+		}) (int, error) {
+			return req.ID, nil
+		}),
+	))
+	app.Build()
+	route := app.Routes()[0]
+
+	// serve mimics an adapter of a router other than http.ServeMux
+	serve := func(params ng.PathParams) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/items/7", nil)
+
+		ctx, rc := ng.NewContext(r.Context())
+		defer rc.Clear()
+		ng.Store(ctx, http.ResponseWriter(w))
+		ng.Store(ctx, r)
+		if params != nil {
+			ng.Store(ctx, params)
+		}
+		_ = route.Handler()(ctx)
+		return w
+	}
+
+	t.Run("stored params", func(t *testing.T) {
+		w := serve(func(name string) string {
+			if name == "id" {
+				return "7"
+			}
+			return ""
+		})
+		if w.Code != http.StatusOK || w.Body.String() != `{"code":"OK","data":7}` {
+			t.Fatalf("unexpected response %d %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		w := serve(nil)
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d %s", w.Code, w.Body)
+		}
+	})
+}
+
+// fieldErr mimics validator.FieldError of go-playground/validator
+type fieldErr struct{ field, tag string }
+
+func (e fieldErr) Error() string { return e.field + " failed on " + e.tag }
+func (e fieldErr) Field() string { return e.field }
+func (e fieldErr) Tag() string   { return e.tag }
+
+// fieldErrs mimics validator.ValidationErrors
+type fieldErrs []fieldErr
+
+func (e fieldErrs) Error() string { return "validation failed" }
+
+func TestTypedFieldViolations(t *testing.T) {
+	app := ng.NewApp(
+		ng.WithResponseHandler(ngadapter.ServeMuxResponseHandler),
+		ng.WithI18n(newTestCatalog(t)),
+	)
+	app.AddRoute(ng.NewRoute(http.MethodPost, "/signup",
+		ng.WithTypedHandler(func(ctx context.Context, req struct {
+			Email string `json:"email"`
+		}) (string, error) {
+			return req.Email, nil
+		}),
+		ng.WithValidator(func(ctx context.Context, v any) error {
+			return fmt.Errorf("signup: %w", fieldErrs{{"email", "required"}, {"name", "min"}})
+		}),
+	))
+	app.Build()
+
+	resp := ngtest.NewClient(app).Request(http.MethodPost, "/signup",
+		ngtest.WithJSON(map[string]any{}), ngtest.WithRequestHeader("Accept-Language", "fr"))
+	resp.AssertCode(t, nghttp.CodeInvalidArgument)
+
+	expected := `{"code":"INVALID_ARGUMENT","message":"invalid argument","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[` +
+		`{"field":"email","description":"le champ email est obligatoire","reason":"required"},` +
+		`{"field":"name","description":"name failed on min","reason":"min"}]}]}`
+	if string(resp.Body) != expected {
+		t.Fatalf("expected %s, got %s", expected, resp.Body)
+	}
+}
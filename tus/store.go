@@ -0,0 +1,246 @@
+package ngtus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Store for unknown uploads
+var ErrNotFound = errors.New("ngtus: upload not found")
+
+type (
+	// Upload describes an upload resource
+	Upload struct {
+		ID        string            `json:"id"`
+		Size      int64             `json:"size"`
+		Offset    int64             `json:"offset"`
+		Metadata  map[string]string `json:"metadata,omitempty"`
+		CreatedAt time.Time         `json:"createdAt"`
+
+		// ExpiresAt is zero when expiration is disabled
+		ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	}
+
+	// Store persists uploads, implementations must be safe for concurrent use
+	Store interface {
+		// Create stores new upload with zero offset
+		Create(ctx context.Context, upload Upload) error
+
+		// Get return upload, ErrNotFound when unknown
+		Get(ctx context.Context, id string) (Upload, error)
+
+		// Write appends r at offset and return number of bytes written,
+		// bytes written before an error must be kept and counted
+		Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+
+		// Open return reader of upload content
+		Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+		// Delete removes upload and its content
+		Delete(ctx context.Context, id string) error
+
+		// List return all uploads, used to delete expired ones
+		List(ctx context.Context) ([]Upload, error)
+	}
+)
+
+// Done reports whether all bytes were received
+func (u Upload) Done() bool { return u.Offset >= u.Size }
+
+// Expired reports whether upload expired at now
+func (u Upload) Expired(now time.Time) bool {
+	return !u.ExpiresAt.IsZero() && now.After(u.ExpiresAt)
+}
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore stores uploads in a local directory, content in <id> and info in <id>.info
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore create store in dir, the directory is created if missing
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Path return path of upload content, e.g. to move a completed file
+func (s *FileStore) Path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+// Create stores upload info and an empty content file
+func (s *FileStore) Create(ctx context.Context, upload Upload) error {
+	if !validID(upload.ID) {
+		return fmt.Errorf("ngtus: invalid upload id %q", upload.ID)
+	}
+
+	f, err := os.OpenFile(s.Path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeInfo(upload)
+}
+
+// Get reads upload info
+func (s *FileStore) Get(ctx context.Context, id string) (Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readInfo(id)
+}
+
+// Write appends r to content file at offset
+func (s *FileStore) Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	if !validID(id) {
+		return 0, ErrNotFound
+	}
+
+	f, err := os.OpenFile(s.Path(id), os.O_WRONLY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := func() (int64, error) {
+		defer f.Close()
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+		return io.Copy(f, r)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ierr := s.readInfo(id)
+	if ierr != nil {
+		return n, ierr
+	}
+	upload.Offset = offset + n
+	if ierr := s.writeInfo(upload); ierr != nil {
+		return n, ierr
+	}
+	return n, err
+}
+
+// Open opens content file
+func (s *FileStore) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(s.Path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes content and info files
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if rerr := os.Remove(s.Path(id)); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+		return rerr
+	}
+	return err
+}
+
+// List reads info of all uploads in the directory
+func (s *FileStore) List(ctx context.Context) ([]Upload, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploads := []Upload{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".info")
+		if !ok {
+			continue
+		}
+		if upload, err := s.readInfo(id); err == nil {
+			uploads = append(uploads, upload)
+		}
+	}
+	return uploads, nil
+}
+
+func (s *FileStore) readInfo(id string) (Upload, error) {
+	if !validID(id) {
+		return Upload{}, ErrNotFound
+	}
+
+	b, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Upload{}, ErrNotFound
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+
+	var upload Upload
+	err = json.Unmarshal(b, &upload)
+	return upload, err
+}
+
+// writeInfo replaces info file atomically
+func (s *FileStore) writeInfo(upload Upload) error {
+	b, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(upload.ID))
+}
+
+// validID rejects ids that could escape the store directory
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,407 @@
+// Package ngtus implements resumable uploads with the tus 1.0 protocol as an ng controller.
+//
+// Supported extensions: creation, termination, expiration.
+// See https://tus.io/protocols/resumable-upload
+package ngtus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/foxie-io/ng"
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+// Protocol constants
+const (
+	Version    = "1.0.0"
+	Extensions = "creation,termination,expiration"
+
+	// ContentTypeOffset is required Content-Type of PATCH requests
+	ContentTypeOffset = "application/offset+octet-stream"
+)
+
+var _ ng.ControllerInitializer = (*Controller)(nil)
+
+type (
+	// CompleteHook receives an upload once all bytes were received
+	CompleteHook func(ctx context.Context, file *File) error
+
+	// Options configures tus controller
+	Options struct {
+		// Path is base path of upload resources, default "/files"
+		Path string
+
+		// IDSegment is route segment of the upload id named "id", default "{id}",
+		// e.g. ":id" for gin, echo and fiber. The id is read with ng.PathValue.
+		IDSegment string
+
+		// Store persists uploads, required
+		Store Store
+
+		// MaxSize is maximum upload size, zero means unlimited
+		MaxSize int64
+
+		// Expiration removes uploads not completed within the duration, zero disables expiration
+		Expiration time.Duration
+
+		// OnComplete is called after the last PATCH, its error is returned to the client
+		OnComplete CompleteHook
+	}
+
+	// Controller serves tus requests: OPTIONS and POST on Path, HEAD, PATCH and DELETE on Path/{id}
+	Controller struct {
+		opts   Options
+		ngOpts []ng.Option
+
+		// ids of uploads with a PATCH in progress, entries only live during the request
+		locks sync.Map
+	}
+
+	// File is a completed upload
+	File struct {
+		Upload
+		store Store
+	}
+)
+
+// Open return reader of uploaded content
+func (f *File) Open(ctx context.Context) (io.ReadCloser, error) {
+	return f.store.Open(ctx, f.ID)
+}
+
+// Filename return "filename" metadata sent by the client
+func (f *File) Filename() string {
+	return f.Metadata["filename"]
+}
+
+/*
+New create tus controller, ng options apply to the controller, e.g. guards.
+
+	store, _ := ngtus.NewFileStore("./uploads")
+
+	app.AddController(ngtus.New(ngtus.Options{
+		Path:       "/videos",
+		Store:      store,
+		MaxSize:    4 << 30,
+		Expiration: 24 * time.Hour,
+		OnComplete: func(ctx context.Context, file *ngtus.File) error {
+			return videos.Process(ctx, file)
+		},
+	}, ng.WithGuards(authGuard)))
+*/
+func New(opts Options, ngOpts ...ng.Option) *Controller {
+	if opts.Store == nil {
+		panic("ngtus: Options.Store is required")
+	}
+	if opts.Path == "" {
+		opts.Path = "/files"
+	}
+	if opts.IDSegment == "" {
+		opts.IDSegment = "{id}"
+	}
+	return &Controller{opts: opts, ngOpts: ngOpts}
+}
+
+// InitializeController mounts routes under Options.Path
+func (c *Controller) InitializeController() ng.Controller {
+	opts := []ng.Option{
+		ng.WithPrefix(c.opts.Path),
+		ng.WithMiddleware(ng.MiddlewareFunc(resumableHeader)),
+	}
+	return ng.NewController(append(opts, c.ngOpts...)...)
+}
+
+// DeleteExpired removes expired uploads, call it periodically when Expiration is set
+func (c *Controller) DeleteExpired(ctx context.Context) (int, error) {
+	uploads, err := c.opts.Store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, now := 0, time.Now()
+	for _, upload := range uploads {
+		if upload.Expired(now) && !upload.Done() {
+			if err := c.opts.Store.Delete(ctx, upload.ID); err == nil {
+				deleted++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// Options advertises protocol version and extensions
+func (c *Controller) Options() ng.Route {
+	return ng.NewRoute(http.MethodOptions, "/", ng.WithHandler(func(ctx context.Context) error {
+		resp := nghttp.NoContent().Update(
+			nghttp.WithHeader("Tus-Version", Version),
+			nghttp.WithHeader("Tus-Extension", Extensions),
+		)
+		if c.opts.MaxSize > 0 {
+			resp.Update(nghttp.WithHeader("Tus-Max-Size", strconv.FormatInt(c.opts.MaxSize, 10)))
+		}
+		return ng.Respond(ctx, resp)
+	}))
+}
+
+// Create implements creation extension
+func (c *Controller) Create() ng.Route {
+	return ng.NewRoute(http.MethodPost, "/", ng.WithHandler(func(ctx context.Context) error {
+		r := ng.MustLoad[*http.Request](ctx)
+
+		size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err != nil || size < 0 {
+			return nghttp.NewErrBadRequest().Update(nghttp.WithMessage("invalid Upload-Length"))
+		}
+
+		if c.opts.MaxSize > 0 && size > c.opts.MaxSize {
+			return nghttp.NewErrPayloadTooLarge().Update(nghttp.Meta("maxSize", c.opts.MaxSize))
+		}
+
+		metadata, err := parseMetadata(r.Header.Get("Upload-Metadata"))
+		if err != nil {
+			return nghttp.NewErrBadRequest().Update(nghttp.WithMessage("invalid Upload-Metadata"))
+		}
+
+		upload := Upload{ID: newID(), Size: size, Metadata: metadata, CreatedAt: time.Now().UTC()}
+		if c.opts.Expiration > 0 {
+			upload.ExpiresAt = upload.CreatedAt.Add(c.opts.Expiration)
+		}
+
+		if err := c.opts.Store.Create(ctx, upload); err != nil {
+			return err
+		}
+
+		resp := nghttp.NoContent().Update(
+			nghttp.WithStatusCode(http.StatusCreated),
+			nghttp.WithHeader("Location", path.Join(c.basePath(ctx), upload.ID)),
+		)
+		c.setExpires(resp, upload)
+
+		// empty upload is complete on creation
+		if upload.Done() {
+			if err := c.complete(ctx, upload); err != nil {
+				return err
+			}
+		}
+		return ng.Respond(ctx, resp)
+	}))
+}
+
+// Head return upload offset
+func (c *Controller) Head() ng.Route {
+	return ng.NewRoute(http.MethodHead, "/"+c.opts.IDSegment, ng.WithHandler(func(ctx context.Context) error {
+		upload, err := c.upload(ctx)
+		if err != nil {
+			return err
+		}
+
+		resp := nghttp.NoContent().Update(
+			nghttp.WithStatusCode(http.StatusOK),
+			nghttp.WithHeader("Upload-Offset", strconv.FormatInt(upload.Offset, 10)),
+			nghttp.WithHeader("Upload-Length", strconv.FormatInt(upload.Size, 10)),
+			nghttp.WithHeader("Cache-Control", "no-store"),
+		)
+		if len(upload.Metadata) > 0 {
+			resp.Update(nghttp.WithHeader("Upload-Metadata", formatMetadata(upload.Metadata)))
+		}
+		c.setExpires(resp, upload)
+		return ng.Respond(ctx, resp)
+	}))
+}
+
+// Patch appends request body at Upload-Offset
+func (c *Controller) Patch() ng.Route {
+	return ng.NewRoute(http.MethodPatch, "/"+c.opts.IDSegment, ng.WithHandler(func(ctx context.Context) error {
+		r := ng.MustLoad[*http.Request](ctx)
+
+		if r.Header.Get("Content-Type") != ContentTypeOffset {
+			return nghttp.NewErrUnsupportedMediaType().Update(nghttp.Meta("contentType", r.Header.Get("Content-Type")))
+		}
+
+		offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			return nghttp.NewErrBadRequest().Update(nghttp.WithMessage("invalid Upload-Offset"))
+		}
+
+		id, err := uploadID(ctx, r)
+		if err != nil {
+			return err
+		}
+		if _, locked := c.locks.LoadOrStore(id, struct{}{}); locked {
+			return nghttp.NewErrAborted().Update(
+				nghttp.WithStatusCode(http.StatusLocked),
+				nghttp.WithMessage("upload is locked by another request"),
+			)
+		}
+		defer c.locks.Delete(id)
+
+		upload, err := c.upload(ctx)
+		if err != nil {
+			return err
+		}
+
+		if offset != upload.Offset {
+			return nghttp.NewErrAborted().Update(
+				nghttp.WithMessage("Upload-Offset does not match"),
+				nghttp.Meta("offset", upload.Offset),
+			)
+		}
+
+		completed := upload.Done()
+		n, err := c.opts.Store.Write(ctx, upload.ID, offset, io.LimitReader(r.Body, upload.Size-offset))
+		upload.Offset += n
+		if err != nil && upload.Offset < upload.Size {
+			// received bytes are kept, the client resumes from the new offset
+			return nghttp.NewErrInternal().Update(nghttp.WithCause(err))
+		}
+
+		resp := nghttp.NoContent().Update(
+			nghttp.WithHeader("Upload-Offset", strconv.FormatInt(upload.Offset, 10)),
+		)
+		c.setExpires(resp, upload)
+
+		if upload.Done() && !completed {
+			if err := c.complete(ctx, upload); err != nil {
+				return err
+			}
+		}
+		return ng.Respond(ctx, resp)
+	}))
+}
+
+// Delete implements termination extension
+func (c *Controller) Delete() ng.Route {
+	return ng.NewRoute(http.MethodDelete, "/"+c.opts.IDSegment, ng.WithHandler(func(ctx context.Context) error {
+		upload, err := c.upload(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.opts.Store.Delete(ctx, upload.ID); err != nil {
+			return err
+		}
+		return ng.Respond(ctx, nghttp.NoContent())
+	}))
+}
+
+// upload loads upload of request path, 404 when unknown and 410 when expired
+func (c *Controller) upload(ctx context.Context) (Upload, error) {
+	r := ng.MustLoad[*http.Request](ctx)
+
+	id, err := uploadID(ctx, r)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	upload, err := c.opts.Store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return upload, nghttp.NewErrNotFound()
+	}
+	if err != nil {
+		return upload, err
+	}
+
+	if upload.Expired(time.Now()) && !upload.Done() {
+		_ = c.opts.Store.Delete(ctx, upload.ID)
+		return upload, nghttp.NewErrNotFound().Update(
+			nghttp.WithStatusCode(http.StatusGone),
+			nghttp.WithMessage("upload expired"),
+		)
+	}
+	return upload, nil
+}
+
+func (c *Controller) complete(ctx context.Context, upload Upload) error {
+	if c.opts.OnComplete == nil {
+		return nil
+	}
+	return c.opts.OnComplete(ctx, &File{Upload: upload, store: c.opts.Store})
+}
+
+func (c *Controller) setExpires(resp *nghttp.BodyResponse, upload Upload) {
+	if !upload.ExpiresAt.IsZero() && !upload.Done() {
+		resp.Update(nghttp.WithHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat)))
+	}
+}
+
+// basePath return full path of the creation route, including app prefixes
+func (c *Controller) basePath(ctx context.Context) string {
+	return ng.GetContext(ctx).Route().Path()
+}
+
+// resumableHeader adds Tus-Resumable to every response and rejects unsupported versions
+func resumableHeader(ctx context.Context, next ng.Handler) {
+	r := ng.MustLoad[*http.Request](ctx)
+
+	if r.Method != http.MethodOptions && r.Header.Get("Tus-Resumable") != Version {
+		_ = ng.Respond(ctx, nghttp.NewErrFailedPrecondition().Update(
+			nghttp.WithMessage("unsupported tus version"),
+			nghttp.WithHeader("Tus-Version", Version),
+		))
+	} else {
+		_ = next(ctx)
+	}
+
+	if carrier, ok := ng.GetContext(ctx).GetResponse().(nghttp.HeaderCarrier); ok {
+		carrier.Headers().Set("Tus-Resumable", Version)
+	}
+}
+
+// uploadID return "id" route param, see Options.IDSegment
+func uploadID(ctx context.Context, r *http.Request) (string, error) {
+	return ng.PathValue(ctx, r, "id")
+}
+
+// parseMetadata parses "key base64value,key2" Upload-Metadata header
+func parseMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+func formatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + " " + base64.StdEncoding.EncodeToString([]byte(metadata[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
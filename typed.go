@@ -0,0 +1,341 @@
+package ng
+
+// Typed handlers: bind and validate request DTOs, wrap results into responses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	nghttp "github.com/foxie-io/ng/http"
+)
+
+type (
+	handlerTypesKey struct{}
+
+	// HandlerTypes describes request and response types of a typed handler,
+	// e.g. for documentation and client generators
+	HandlerTypes struct {
+		Request  reflect.Type
+		Response reflect.Type
+	}
+
+	// Binder fills dst from the request stored in context
+	Binder func(ctx context.Context, dst any) error
+
+	// PathParams return path param of matched route by name, adapters of routers
+	// other than http.ServeMux store it so DefaultBinder can resolve `path` fields
+	/*
+		ng.Store(ctx, ng.PathParams(ginCtx.Param))
+	*/
+	PathParams func(name string) string
+
+	// Validator validates bound request, errors other than *nghttp.Response become INVALID_ARGUMENT
+	/*
+		validate := validator.New()
+
+		ng.WithValidator(func(ctx context.Context, v any) error {
+			return validate.StructCtx(ctx, v)
+		})
+	*/
+	Validator func(ctx context.Context, v any) error
+
+	// Validatable is implemented by request types validating themselves, it runs before the route Validator
+	Validatable interface {
+		Validate() error
+	}
+
+	// FieldError is a validation error of one field, e.g. validator.FieldError of go-playground/validator.
+	// Validation errors holding field errors become BadRequest field violations with Tag as reason,
+	// so they can be translated, see WithI18n.
+	FieldError interface {
+		error
+		Field() string
+		Tag() string
+	}
+)
+
+// WithBinder replaces DefaultBinder for typed handlers of app, controller or route
+func WithBinder(binder Binder) Option {
+	return func(c *config) {
+		c.core.binder = binder
+	}
+}
+
+// WithValidator sets validator of typed handlers and Validate for app, controller or route
+func WithValidator(validator Validator) Option {
+	return func(c *config) {
+		c.core.validator = validator
+	}
+}
+
+/*
+WithTypedHandler adds handler receiving a bound and validated Req, Resp is wrapped with nghttp.NewResponse
+unless it is already a nghttp.HTTPResponse. Req and Resp are recorded on the route, see RouteHandlerTypes.
+
+	type GetUserRequest struct {
+		ID     int    `path:"id"`
+		Fields string `query:"fields"`
+	}
+
+	ng.NewRoute(http.MethodGet, "/users/{id}",
+		ng.WithTypedHandler(func(ctx context.Context, req GetUserRequest) (*dtos.User, error) {
+			return users.Get(ctx, req.ID)
+		}),
+	)
+*/
+func WithTypedHandler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) HandlerOption {
+	types := HandlerTypes{
+		Request:  reflect.TypeFor[Req](),
+		Response: reflect.TypeFor[Resp](),
+	}
+
+	return func(c *config) {
+		c.core.metadata.Store(handlerTypesKey{}, types)
+		c.core.handlers = append(c.core.handlers, Typed(fn))
+	}
+}
+
+// Typed converts typed function to Handler, see WithTypedHandler
+func Typed[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) Handler {
+	return func(ctx context.Context) error {
+		var req Req
+		dst := any(&req)
+
+		// pointer requests are allocated and bound directly
+		if t := reflect.TypeFor[Req](); t.Kind() == reflect.Pointer {
+			v := reflect.New(t.Elem())
+			reflect.ValueOf(&req).Elem().Set(v)
+			dst = v.Interface()
+		}
+
+		if err := Bind(ctx, dst); err != nil {
+			return err
+		}
+		if err := Validate(ctx, dst); err != nil {
+			return err
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if httpResp, ok := any(resp).(nghttp.HTTPResponse); ok {
+			return Respond(ctx, httpResp)
+		}
+		return Respond(ctx, nghttp.NewResponse(resp))
+	}
+}
+
+// RouteHandlerTypes return request and response types of route built with WithTypedHandler
+func RouteHandlerTypes(r Route) (HandlerTypes, bool) {
+	val, ok := r.Core().Metadata(handlerTypesKey{})
+	if !ok {
+		return HandlerTypes{}, false
+	}
+	return val.(HandlerTypes), true
+}
+
+// Bind fills dst with binder of current route, DefaultBinder when none is set
+func Bind(ctx context.Context, dst any) error {
+	if c := routeCore(ctx); c != nil && c.binder != nil {
+		return c.binder(ctx, dst)
+	}
+	return DefaultBinder(ctx, dst)
+}
+
+// Validate runs Validate() of v when implemented, then validator of current route
+func Validate(ctx context.Context, v any) error {
+	if validatable, ok := v.(Validatable); ok {
+		if err := validatable.Validate(); err != nil {
+			return validationError(err)
+		}
+	}
+
+	if c := routeCore(ctx); c != nil && c.validator != nil {
+		if err := c.validator(ctx, v); err != nil {
+			return validationError(err)
+		}
+	}
+	return nil
+}
+
+func validationError(err error) error {
+	if _, ok := err.(nghttp.HTTPResponse); ok {
+		return err
+	}
+	if violations := fieldViolations(err); len(violations) > 0 {
+		return nghttp.NewErrInvalidArgument().Update(nghttp.WithDetails(&nghttp.BadRequest{FieldViolations: violations}), nghttp.WithCause(err))
+	}
+	return nghttp.NewErrInvalidArgument().Update(nghttp.Meta("error", err.Error()), nghttp.WithCause(err))
+}
+
+// fieldViolations collects field errors of err, joined errors and slices of field errors,
+// e.g. validator.ValidationErrors, are walked
+func fieldViolations(err error) []nghttp.FieldViolation {
+	if fe, ok := err.(FieldError); ok {
+		return []nghttp.FieldViolation{{Field: fe.Field(), Description: fe.Error(), Reason: fe.Tag()}}
+	}
+
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else if v := reflect.ValueOf(err); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if e, ok := v.Index(i).Interface().(error); ok {
+				errs = append(errs, e)
+			}
+		}
+	} else if inner := errors.Unwrap(err); inner != nil {
+		errs = []error{inner}
+	}
+
+	var violations []nghttp.FieldViolation
+	for _, e := range errs {
+		violations = append(violations, fieldViolations(e)...)
+	}
+	return violations
+}
+
+/*
+DefaultBinder decodes request body with codec selected by Content-Type, then sets struct fields
+tagged `path` (or `param`), `query` and `header`. Body is skipped for GET, HEAD and empty requests.
+
+Path params are read from PathParams stored in context, else from http.Request.PathValue.
+A `path` field the router did not resolve is an INTERNAL error rather than a silent zero value.
+
+	type UpdateUserRequest struct {
+		ID      int      `path:"id"`
+		Notify  bool     `query:"notify"`
+		TraceID string   `header:"X-Trace-ID"`
+		Name    string   `json:"name"`
+		Tags    []string `json:"tags"`
+	}
+*/
+var DefaultBinder Binder = func(ctx context.Context, dst any) error {
+	r, err := Load[*http.Request](ctx)
+	if err != nil {
+		return err
+	}
+
+	if hasBody(r) {
+		if err := DecodeBody(ctx, r.Header.Get("Content-Type"), r.Body, dst); err != nil {
+			return err
+		}
+	}
+
+	target := reflect.ValueOf(dst)
+	for target.Kind() == reflect.Pointer && !target.IsNil() {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return nil
+	}
+	params, _ := Load[PathParams](ctx)
+	return bindRequestFields(r, params, target)
+}
+
+func hasBody(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	return r.ContentLength != 0
+}
+
+// bindRequestFields sets path, query and header tagged fields, embedded structs are walked
+func bindRequestFields(r *http.Request, params PathParams, v reflect.Value) error {
+	t := v.Type()
+	query := r.URL.Query()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf, field := t.Field(i), v.Field(i)
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			if err := bindRequestFields(r, params, field); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		var values []string
+		name, source := requestFieldSource(sf)
+		switch source {
+		case "path":
+			value, err := pathValue(r, params, name)
+			if err != nil {
+				return err
+			}
+			if value != "" {
+				values = []string{value}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = r.Header.Values(name)
+		default:
+			continue
+		}
+
+		if field.Kind() != reflect.Slice && len(values) > 1 {
+			values = values[:1]
+		}
+		for _, value := range values {
+			if err := setFormValue(field, name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PathValue return path param of r from PathParams stored in ctx, else from r.PathValue, see DefaultBinder
+func PathValue(ctx context.Context, r *http.Request, name string) (string, error) {
+	var params PathParams
+	if GetContext(ctx) != nil {
+		params, _ = Load[PathParams](ctx)
+	}
+	return pathValue(r, params, name)
+}
+
+// pathValue return path param from params, else from r.PathValue when the http.ServeMux pattern declares it
+func pathValue(r *http.Request, params PathParams, name string) (string, error) {
+	if params != nil {
+		return params(name), nil
+	}
+	if value := r.PathValue(name); value != "" {
+		return value, nil
+	}
+	if strings.Contains(r.Pattern, "{"+name+"}") || strings.Contains(r.Pattern, "{"+name+"...}") {
+		return "", nil
+	}
+	err := fmt.Errorf("path param %q is not resolved, store ng.PathParams in adapter", name)
+	return "", nghttp.NewErrInternal().Update(nghttp.WithCause(err))
+}
+
+// requestFieldSource return name and source of field from `path`, `param`, `query` or `header` tag
+func requestFieldSource(sf reflect.StructField) (name, source string) {
+	for _, tag := range []string{"path", "param", "query", "header"} {
+		if name, _, _ = strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			if tag == "param" {
+				tag = "path"
+			}
+			return name, tag
+		}
+	}
+	return "", ""
+}
+
+func routeCore(ctx context.Context) *core {
+	rc := GetContext(ctx)
+	if rc == nil || rc.Route() == nil {
+		return nil
+	}
+	c, _ := rc.Route().Core().(*core)
+	return c
+}
@@ -1,7 +1,6 @@
 package ng
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -39,11 +38,6 @@ func ExtractControllerRoutes(app App, config ControllerInitializer) ([]Route, er
 			}
 
 			route.name = strings.Replace(fmt.Sprintf("%T.%s", config, funcName.Name), "*", "", 1)
-			route.core.preExecutes = append(route.core.preExecutes,
-				func(ctx context.Context) {
-					GetContext(ctx).setRoute(route)
-				},
-			)
 
 			routes = append(routes, route)
 		}
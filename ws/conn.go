@@ -0,0 +1,313 @@
+// Package ngws implements the WebSocket protocol (RFC 6455) on hijacked net/http connections
+package ngws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message and control frame opcodes
+const (
+	continuationFrame = 0x0
+	TextMessage       = 0x1
+	BinaryMessage     = 0x2
+	CloseMessage      = 0x8
+	PingMessage       = 0x9
+	PongMessage       = 0xA
+)
+
+// Close status codes
+const (
+	CloseNormal           = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatus         = 1005
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+	maxControlPayloadSize = 125
+)
+
+// DefaultReadLimit is maximum size of a message read from the peer
+var DefaultReadLimit int64 = 1 << 20
+
+// WriteTimeout is maximum time a frame write may take
+var WriteTimeout = 10 * time.Second
+
+// ErrClosed is returned when writing to a closed connection
+var ErrClosed = errors.New("ngws: connection closed")
+
+// CloseError is returned by ReadMessage when the peer closed the connection
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("ngws: close %d %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket connection, one reader and any number of concurrent writers are allowed
+type Conn struct {
+	conn      net.Conn
+	br        *bufio.Reader
+	client    bool
+	readLimit int64
+
+	wmu    sync.Mutex
+	closed bool
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, client bool) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	return &Conn{conn: conn, br: br, client: client, readLimit: DefaultReadLimit}
+}
+
+// SetReadLimit sets maximum message size, larger messages close the connection with CloseMessageTooBig
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// NetConn return underlying connection
+func (c *Conn) NetConn() net.Conn { return c.conn }
+
+// ReadMessage reads next text or binary message.
+//
+// Pings are answered and pongs skipped. When the peer closes the connection
+// the close frame is echoed and *CloseError returned.
+func (c *Conn) ReadMessage() (opcode int, data []byte, err error) {
+	opcode = -1
+
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+
+		case PongMessage:
+			continue
+
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Text = string(payload[2:])
+			}
+			// 1005 is reserved for "no status" and must not be sent
+			echo := closeErr.Code
+			if echo == CloseNoStatus {
+				echo = CloseNormal
+			}
+			_ = c.WriteClose(echo, "")
+			return 0, nil, closeErr
+
+		case continuationFrame:
+			if opcode == -1 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+
+		case TextMessage, BinaryMessage:
+			if opcode != -1 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			opcode = op
+
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(data)+len(payload)) > c.readLimit {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		data = append(data, payload...)
+
+		if fin {
+			if opcode == TextMessage && !utf8.Valid(data) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid utf-8")
+			}
+			return opcode, data, nil
+		}
+	}
+}
+
+// WriteMessage writes a single frame message
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	if opcode != TextMessage && opcode != BinaryMessage {
+		return fmt.Errorf("ngws: invalid message opcode %d", opcode)
+	}
+	return c.writeFrame(opcode, data)
+}
+
+// Ping sends ping frame, the peer answers with pong
+func (c *Conn) Ping(data []byte) error {
+	return c.writeFrame(PingMessage, data)
+}
+
+// WriteClose sends close frame, no data frame can be written afterwards
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayloadSize {
+		// cut reason at a rune boundary, it must stay valid UTF-8
+		end := maxControlPayloadSize
+		for end > 2 && !utf8.RuneStart(payload[end]) {
+			end--
+		}
+		payload = payload[:end]
+	}
+
+	err := c.writeFrame(CloseMessage, payload)
+
+	c.wmu.Lock()
+	c.closed = true
+	c.wmu.Unlock()
+	return err
+}
+
+// Close closes underlying connection without close handshake
+func (c *Conn) Close() error {
+	c.wmu.Lock()
+	c.closed = true
+	c.wmu.Unlock()
+	return c.conn.Close()
+}
+
+// fail sends close frame and closes connection
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.WriteClose(code, reason)
+	_ = c.conn.Close()
+	return &CloseError{Code: code, Text: reason}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = int(header[0] & 0x0F)
+	masked := header[1]&0x80 != 0
+	size := int64(header[1] & 0x7F)
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+
+	// clients must mask frames, servers must not
+	if masked == c.client {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid frame masking")
+	}
+
+	isControl := opcode&0x8 != 0
+	if isControl && (!fin || size > maxControlPayloadSize) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		size = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		size = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if size < 0 || size > c.readLimit {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+
+	switch size := len(payload); {
+	case size <= 125:
+		frame = append(frame, maskBit|byte(size))
+	case size <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(size))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(size))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskBytes(mask, frame[start:])
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
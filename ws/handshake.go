@@ -0,0 +1,218 @@
+package ngws
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// HandshakeError is returned by Upgrade before the connection is hijacked,
+// the caller is responsible for the HTTP error response
+type HandshakeError struct {
+	Status int
+	Reason string
+}
+
+func (e *HandshakeError) Error() string {
+	return "ngws: handshake failed: " + e.Reason
+}
+
+// UpgradeOption is used to customize Upgrade
+type UpgradeOption func(o *upgradeOptions)
+
+type upgradeOptions struct {
+	checkOrigin func(r *http.Request) bool
+}
+
+// WithCheckOrigin sets the handshake origin check, SameOrigin is used by default.
+//
+// Browsers send cookies with cross-site WebSocket handshakes, allowing any origin
+// lets other sites act as the user.
+func WithCheckOrigin(check func(r *http.Request) bool) UpgradeOption {
+	return func(o *upgradeOptions) {
+		o.checkOrigin = check
+	}
+}
+
+// SameOrigin allows handshakes whose Origin host equals the request Host.
+//
+// Requests without Origin header are allowed, they do not come from browsers.
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// IsUpgrade reports whether r asks for a WebSocket upgrade
+func IsUpgrade(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the opening handshake and hijacks the connection.
+//
+// header is added to the 101 response, e.g. Sec-WebSocket-Protocol.
+// Cross-origin handshakes are rejected with 403 unless WithCheckOrigin allows them.
+func Upgrade(w http.ResponseWriter, r *http.Request, header http.Header, opts ...UpgradeOption) (*Conn, error) {
+	o := upgradeOptions{checkOrigin: SameOrigin}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if r.Method != http.MethodGet {
+		return nil, &HandshakeError{Status: http.StatusMethodNotAllowed, Reason: "method must be GET"}
+	}
+
+	if !IsUpgrade(r) {
+		return nil, &HandshakeError{Status: http.StatusBadRequest, Reason: "not a websocket upgrade request"}
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, &HandshakeError{Status: http.StatusUpgradeRequired, Reason: "unsupported websocket version"}
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, &HandshakeError{Status: http.StatusBadRequest, Reason: "invalid Sec-WebSocket-Key"}
+	}
+
+	if !o.checkOrigin(r) {
+		return nil, &HandshakeError{Status: http.StatusForbidden, Reason: "origin not allowed"}
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, &HandshakeError{Status: http.StatusInternalServerError, Reason: "response writer does not support hijacking"}
+	}
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n")
+	for k, values := range header {
+		for _, v := range values {
+			b.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+
+	if _, err := netConn.Write([]byte(b.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	// hijacked net/http connections may carry deadlines of the server
+	_ = netConn.SetDeadline(time.Time{})
+	return newConn(netConn, rw.Reader, false), nil
+}
+
+// Dial opens a client connection to ws:// or wss:// url, mostly useful in tests
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, nil, fmt.Errorf("ngws: unsupported scheme %q", u.Scheme)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443"}[u.Scheme])
+	}
+
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(netConn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			netConn.Close()
+			return nil, nil, err
+		}
+		netConn = tlsConn
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		netConn.Close()
+		return nil, resp, errors.New("ngws: bad handshake, status " + resp.Status)
+	}
+
+	_ = netConn.SetDeadline(time.Time{})
+	return newConn(netConn, br, true), resp, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, v := range header.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}